@@ -16,7 +16,6 @@ import (
 
 	httpAdapter "github.com/mehmetymw/event-driven-ns/internal/adapter/http"
 	"github.com/mehmetymw/event-driven-ns/internal/adapter/postgres"
-	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
 	"github.com/mehmetymw/event-driven-ns/internal/adapter/ws"
 	"github.com/mehmetymw/event-driven-ns/internal/app"
 	"github.com/mehmetymw/event-driven-ns/pkg/config"
@@ -25,7 +24,11 @@ import (
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	log, err := logger.New(cfg.LogLevel)
 	if err != nil {
@@ -55,32 +58,61 @@ func main() {
 	notificationRepo := postgres.NewNotificationRepo(db)
 	templateRepo := postgres.NewTemplateRepo(db)
 	idempotencyStore := postgres.NewIdempotencyRepo(db)
-	producer := queue.NewProducer(cfg.KafkaBrokers)
-	defer func() { _ = producer.Close() }()
+	leaseRepo := postgres.NewLeaseRepo(db)
+	deadLetterRepo := postgres.NewDeadLetterRepo(db)
+	pushTokenRepo := postgres.NewPushTokenRepo(db)
+	providerRateRepo := postgres.NewProviderRateRepo(db)
+	breakerRepo := postgres.NewCircuitBreakerRepo(db)
+	contactRepo := postgres.NewContactRepo(db)
+	suppressionRepo := postgres.NewSuppressionRepo(db)
+	outboxRepo := postgres.NewOutboxRepo(db)
 	wsHub := ws.NewHub()
 
 	notificationService := app.NewNotificationService(
 		notificationRepo,
-		producer,
 		templateRepo,
+		contactRepo,
+		suppressionRepo,
 		idempotencyStore,
+		wsHub,
 		log,
 	)
 
 	templateService := app.NewTemplateService(templateRepo, log)
-	metricsCollector := app.NewMetricsCollector(notificationRepo)
+	deadLetterService := app.NewDeadLetterService(deadLetterRepo, log)
+	metricsCollector := app.NewMetricsCollector(notificationRepo, providerRateRepo, breakerRepo, outboxRepo)
 
 	notificationHandler := httpAdapter.NewNotificationHandler(notificationService)
 	templateHandler := httpAdapter.NewTemplateHandler(templateService)
-	healthHandler := httpAdapter.NewHealthHandler(db, cfg.KafkaBrokers)
+	contactHandler := httpAdapter.NewContactHandler(app.NewContactService(contactRepo, log))
+	suppressionHandler := httpAdapter.NewSuppressionHandler(app.NewSuppressionService(suppressionRepo, log))
+	// The API never talks to the queue directly: notifications reach it through
+	// the outbox relay in the worker. Kafka only matters here for readiness.
+	var kafkaBrokers []string
+	if cfg.QueueBackend == config.QueueBackendKafka {
+		kafkaBrokers = cfg.KafkaBrokers
+	}
+	healthHandler := httpAdapter.NewHealthHandler(db, kafkaBrokers)
 	metricsHandler := httpAdapter.NewMetricsHandler(metricsCollector)
+	schedulerHandler := httpAdapter.NewSchedulerHandler(app.NewLeaseService(leaseRepo))
+	deadLetterHandler := httpAdapter.NewDeadLetterHandler(deadLetterService)
+	pushTokenHandler := httpAdapter.NewPushTokenHandler(app.NewPushTokenService(pushTokenRepo))
+	receiptHandler := httpAdapter.NewReceiptHandler(app.NewReceiptService(notificationRepo, wsHub, log), receiptVerifiers(cfg), signedReceipts(cfg))
+	breakerHandler := httpAdapter.NewCircuitBreakerHandler(app.NewCircuitBreakerService(breakerRepo, log))
 	wsHandler := httpAdapter.NewWebSocketHandler(wsHub)
 
 	router := httpAdapter.NewRouter(httpAdapter.RouterDeps{
 		NotificationHandler: notificationHandler,
 		TemplateHandler:     templateHandler,
+		ContactHandler:      contactHandler,
+		SuppressionHandler:  suppressionHandler,
 		HealthHandler:       healthHandler,
 		MetricsHandler:      metricsHandler,
+		SchedulerHandler:    schedulerHandler,
+		DeadLetterHandler:   deadLetterHandler,
+		PushTokenHandler:    pushTokenHandler,
+		ReceiptHandler:      receiptHandler,
+		BreakerHandler:      breakerHandler,
 		WebSocketHandler:    wsHandler,
 		Logger:              log,
 	})
@@ -131,3 +163,22 @@ func runMigrations(databaseURL string, log *zap.Logger) {
 
 	log.Info("database migrations applied")
 }
+
+// receiptVerifiers checks Twilio's own signature on its status callbacks.
+// Twilio is only given a callback URL when PUBLIC_API_URL is set.
+func receiptVerifiers(cfg *config.Config) map[string]httpAdapter.ReceiptVerifier {
+	verifiers := make(map[string]httpAdapter.ReceiptVerifier)
+	if cfg.TwilioAuthToken != "" && cfg.PublicAPIURL != "" {
+		verifiers["twilio"] = httpAdapter.TwilioReceiptVerifier(cfg.TwilioAuthToken, cfg.PublicAPIURL)
+	}
+	return verifiers
+}
+
+// signedReceipts accepts receipts from any other provider that signs them
+// with one of RECEIPT_SECRETS. Without secrets those receipts are refused.
+func signedReceipts(cfg *config.Config) httpAdapter.ReceiptVerifier {
+	if len(cfg.ReceiptSecrets) == 0 {
+		return nil
+	}
+	return httpAdapter.SignedReceiptVerifier(cfg.ReceiptSecrets)
+}
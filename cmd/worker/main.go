@@ -11,8 +11,6 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/mehmetymw/event-driven-ns/internal/adapter/postgres"
-	"github.com/mehmetymw/event-driven-ns/internal/adapter/provider"
-	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
 	"github.com/mehmetymw/event-driven-ns/internal/adapter/ws"
 	"github.com/mehmetymw/event-driven-ns/internal/app"
 	"github.com/mehmetymw/event-driven-ns/pkg/config"
@@ -21,7 +19,11 @@ import (
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	log, err := logger.New(cfg.LogLevel)
 	if err != nil {
@@ -30,6 +32,10 @@ func main() {
 	}
 	defer func() { _ = log.Sync() }()
 
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("invalid configuration", zap.Error(err))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -47,37 +53,80 @@ func main() {
 	defer func() { _ = db.Close() }()
 
 	notificationRepo := postgres.NewNotificationRepo(db)
-	webhookProvider := provider.NewWebhookProvider(cfg.WebhookURL)
+	outboxRepo := postgres.NewOutboxRepo(db)
+	leaseRepo := postgres.NewLeaseRepo(db)
+	deadLetterRepo := postgres.NewDeadLetterRepo(db)
+	pushTokenRepo := postgres.NewPushTokenRepo(db)
+	suppressionRepo := postgres.NewSuppressionRepo(db)
+	providerRateRepo := postgres.NewProviderRateRepo(db)
+	breakerRepo := postgres.NewCircuitBreakerRepo(db)
+	rateStore, err := newRateStore(cfg, db)
+	if err != nil {
+		log.Fatal("failed to configure rate limiting", zap.Error(err))
+	}
+	providerRouter, err := newProviderRouter(cfg, rateStore)
+	if err != nil {
+		log.Fatal("failed to configure delivery providers", zap.Error(err))
+	}
 	wsHub := ws.NewHub()
-	metricsCollector := app.NewMetricsCollector(notificationRepo)
+	metricsCollector := app.NewMetricsCollector(notificationRepo, providerRateRepo, breakerRepo, outboxRepo)
 
 	deliveryService := app.NewDeliveryService(
 		notificationRepo,
-		webhookProvider,
+		providerRouter,
+		pushTokenRepo,
+		suppressionRepo,
 		wsHub,
 		metricsCollector,
 		log,
 	)
 
-	schedulerProducer := queue.NewProducer(cfg.KafkaBrokers)
-	defer func() { _ = schedulerProducer.Close() }()
+	deadLetterService := app.NewDeadLetterService(deadLetterRepo, log)
+
+	backend, err := newQueueBackend(cfg, db, rateStore, deadLetterService.Record, log)
+	if err != nil {
+		log.Fatal("failed to configure queue backend", zap.Error(err))
+	}
+	defer func() { _ = backend.publisher.Close() }()
+
+	elector := app.NewLeaderElector(leaseRepo, app.SchedulerLeaseName, cfg.InstanceID, log)
+	go elector.Run(ctx)
 
-	scheduler := app.NewScheduler(notificationRepo, schedulerProducer, log)
+	scheduler := app.NewScheduler(notificationRepo, elector, log)
 	go scheduler.Run(ctx)
 
-	consumer := queue.NewConsumer(queue.ConsumerConfig{
-		Brokers:        cfg.KafkaBrokers,
-		Group:          cfg.KafkaConsumerGroup,
-		RatePerChannel: cfg.RateLimitPerChannel,
-		Logger:         log,
-	})
+	outboxRelay := app.NewOutboxRelay(outboxRepo, notificationRepo, backend.publisher, log)
+	go outboxRelay.Run(ctx)
+
+	rateReporter := app.NewProviderRateReporter(providerRouter, providerRateRepo, cfg.InstanceID, log)
+	go rateReporter.Run(ctx)
+
+	breakerSync := app.NewBreakerSync(providerRouter, breakerRepo, cfg.InstanceID, cfg.Breakers.Shared, log)
+	go breakerSync.Run(ctx)
+
+	if backend.scheduled != nil {
+		go func() {
+			if err := backend.scheduled.Start(ctx, scheduler.HandleScheduled); err != nil {
+				if ctx.Err() == nil {
+					log.Error("scheduled consumer stopped unexpectedly", zap.Error(err))
+				}
+			}
+		}()
+	}
+
+	for _, svc := range backend.services {
+		go func() {
+			if err := svc.start(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.Error(svc.name+" stopped unexpectedly", zap.Error(err))
+				}
+			}
+		}()
+	}
 
 	go func() {
-		log.Info("starting kafka consumer",
-			zap.Strings("brokers", cfg.KafkaBrokers),
-			zap.String("group", cfg.KafkaConsumerGroup),
-		)
-		if err := consumer.Start(ctx, deliveryService.ProcessDelivery); err != nil {
+		log.Info("starting queue consumer", zap.String("backend", cfg.QueueBackend))
+		if err := backend.consumer.Start(ctx, deliveryService.ProcessDelivery); err != nil {
 			if ctx.Err() == nil {
 				log.Error("consumer stopped unexpectedly", zap.Error(err))
 			}
@@ -95,9 +144,19 @@ func main() {
 
 	cancel()
 
-	if err := consumer.Stop(shutdownCtx); err != nil {
+	if err := backend.consumer.Stop(shutdownCtx); err != nil {
 		log.Error("consumer shutdown error", zap.Error(err))
 	}
+	if backend.scheduled != nil {
+		if err := backend.scheduled.Stop(shutdownCtx); err != nil {
+			log.Error("scheduled consumer shutdown error", zap.Error(err))
+		}
+	}
+	for _, svc := range backend.services {
+		if err := svc.stop(shutdownCtx); err != nil {
+			log.Error(svc.name+" shutdown error", zap.Error(err))
+		}
+	}
 
 	log.Info("worker stopped")
 }
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/provider"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/circuitbreaker"
+	"github.com/mehmetymw/event-driven-ns/pkg/config"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+	"github.com/mehmetymw/event-driven-ns/pkg/webhooksig"
+)
+
+var channels = []domain.Channel{domain.ChannelSMS, domain.ChannelEmail, domain.ChannelPush}
+
+// newProviderRouter builds the delivery router from the configured providers
+// and PROVIDER_ROUTES. Without explicit routes every channel goes to the
+// webhook, with the fallback webhook as secondary when one is configured,
+// except SMS, which goes to Twilio when TWILIO_ACCOUNT_SID is set, email,
+// which goes to SMTP when SMTP_HOST is set, and push, which goes to FCM
+// and/or APNs when their credentials are set. Each route is limited to its
+// PROVIDER_RATE_LIMITS entry or PROVIDER_RATE_LIMIT, drawn from rateStore
+// when there is one, and gets breaker settings from BREAKER_SETTINGS over the
+// BREAKER_* defaults. Routes are uncapped by default: the queue already holds
+// each channel to RATE_LIMIT_PER_CHANNEL, and a second limiter at the same
+// rate would only add waiting.
+func newProviderRouter(cfg *config.Config, rateStore ratelimit.Store) (*provider.Router, error) {
+	endpoints, err := webhookEndpoints(cfg.Webhooks)
+	if err != nil {
+		return nil, err
+	}
+	providers := map[string]port.DeliveryProvider{
+		"webhook": provider.NewWebhookProvider(endpoints),
+	}
+	if cfg.FallbackWebhook.URL != "" {
+		// One fallback endpoint serves every channel.
+		webhooks := make(map[string]config.WebhookConfig, len(config.WebhookChannels))
+		for _, ch := range config.WebhookChannels {
+			webhooks[ch] = cfg.FallbackWebhook
+		}
+		fallback, err := webhookEndpoints(webhooks)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %w", err)
+		}
+		providers["webhook-fallback"] = provider.NewWebhookProvider(fallback)
+	}
+	if cfg.SMTPHost != "" {
+		providers["smtp"] = provider.NewSMTPProvider(provider.SMTPConfig{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.SMTPUsername,
+			Password:   cfg.SMTPPassword,
+			From:       cfg.SMTPFrom,
+			Subject:    cfg.SMTPSubject,
+			RequireTLS: cfg.SMTPRequireTLS,
+		})
+	}
+
+	if cfg.TwilioAccountSID != "" {
+		providers["twilio"] = provider.NewTwilioProvider(provider.TwilioConfig{
+			AccountSID:          cfg.TwilioAccountSID,
+			AuthToken:           cfg.TwilioAuthToken,
+			From:                cfg.TwilioFrom,
+			MessagingServiceSID: cfg.TwilioServiceSID,
+			StatusCallbackURL:   receiptURL(cfg.PublicAPIURL, "twilio"),
+			BaseURL:             cfg.TwilioBaseURL,
+		})
+	}
+
+	if err := addPushProviders(cfg, providers); err != nil {
+		return nil, err
+	}
+
+	spec := cfg.ProviderRoutes
+	if spec == "" {
+		spec = defaultRouteSpec(providers)
+	}
+
+	routes, err := parseRoutes(spec, providers)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := parseRateLimits(cfg.ProviderRateLimits)
+	if err != nil {
+		return nil, err
+	}
+	breakers, err := parseBreakerSettings(cfg.Breakers.Settings)
+	if err != nil {
+		return nil, err
+	}
+	defaults := circuitbreaker.Settings{
+		Failures:         cfg.Breakers.Failures,
+		OpenTimeout:      cfg.Breakers.OpenTimeout,
+		HalfOpenRequests: cfg.Breakers.HalfOpenRequests,
+	}
+	for i, rt := range routes {
+		routes[i].RateLimit = rateLimitFor(limits, rt, float64(cfg.ProviderRateLimit))
+		routes[i].RateStore = rateStore
+		routes[i].Breaker = breakerSettingsFor(breakers, rt, defaults)
+	}
+	return provider.NewRouter(routes...), nil
+}
+
+func defaultRouteSpec(providers map[string]port.DeliveryProvider) string {
+	targets := "webhook"
+	if _, ok := providers["webhook-fallback"]; ok {
+		targets += ",webhook-fallback:1:secondary"
+	}
+
+	specs := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		if _, ok := providers["twilio"]; ok && ch == domain.ChannelSMS {
+			specs = append(specs, string(ch)+"=twilio")
+			continue
+		}
+		if _, ok := providers["smtp"]; ok && ch == domain.ChannelEmail {
+			specs = append(specs, string(ch)+"=smtp")
+			continue
+		}
+		if _, ok := providers["push"]; ok && ch == domain.ChannelPush {
+			specs = append(specs, string(ch)+"=push")
+			continue
+		}
+		specs = append(specs, string(ch)+"="+targets)
+	}
+	return strings.Join(specs, ";")
+}
+
+// webhookEndpoints turns each channel's webhook settings into an endpoint.
+func webhookEndpoints(webhooks map[string]config.WebhookConfig) (map[domain.Channel]provider.WebhookEndpoint, error) {
+	endpoints := make(map[domain.Channel]provider.WebhookEndpoint, len(webhooks))
+	for name, wh := range webhooks {
+		ch := domain.Channel(name)
+		if len(wh.Secrets) > webhooksig.MaxSecrets {
+			return nil, fmt.Errorf("webhook secrets for %s: %w", ch, webhooksig.ErrTooManySecrets)
+		}
+
+		ep := provider.WebhookEndpoint{
+			URL:               wh.URL,
+			Headers:           wh.Headers,
+			Secrets:           wh.Secrets,
+			Timeout:           wh.Timeout,
+			TransientStatuses: wh.TransientStatuses,
+		}
+
+		switch wh.Auth {
+		case config.WebhookAuthNone:
+		case config.WebhookAuthBearer:
+			ep.Auth = provider.NewBearerAuth(wh.BearerToken)
+		case config.WebhookAuthBasic:
+			ep.Auth = provider.NewBasicAuth(wh.BasicUsername, wh.BasicPassword)
+		case config.WebhookAuthOAuth2:
+			if wh.OAuth2TokenURL == "" {
+				return nil, fmt.Errorf("webhook auth for %s: oauth2 needs OAUTH2_TOKEN_URL", ch)
+			}
+			ep.Auth = provider.NewOAuth2Auth(provider.OAuth2Config{
+				TokenURL:     wh.OAuth2TokenURL,
+				ClientID:     wh.OAuth2ClientID,
+				ClientSecret: wh.OAuth2ClientSecret,
+				Scopes:       wh.OAuth2Scopes,
+			})
+		default:
+			return nil, fmt.Errorf("webhook auth for %s: unknown type %q", ch, wh.Auth)
+		}
+		endpoints[ch] = ep
+	}
+	return endpoints, nil
+}
+
+// receiptURL is where a provider posts delivery status callbacks. Without a
+// public API URL no callback is requested.
+func receiptURL(publicAPIURL, name string) string {
+	if publicAPIURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(publicAPIURL, "/") + "/api/v1/providers/" + name + "/receipts"
+}
+
+// addPushProviders registers "fcm" and "apns" for whichever platforms have
+// credentials, and "push", which picks between them by token shape.
+func addPushProviders(cfg *config.Config, providers map[string]port.DeliveryProvider) error {
+	var fcm, apns port.DeliveryProvider
+
+	if cfg.FCMCredentialsFile != "" {
+		fcmConfig, err := provider.LoadFCMConfig(cfg.FCMCredentialsFile)
+		if err != nil {
+			return fmt.Errorf("fcm credentials: %w", err)
+		}
+		fcm = provider.NewFCMProvider(fcmConfig)
+		providers["fcm"] = fcm
+	}
+
+	if cfg.APNsKeyFile != "" {
+		key, err := provider.LoadAPNsKey(cfg.APNsKeyFile)
+		if err != nil {
+			return fmt.Errorf("apns key: %w", err)
+		}
+		apns = provider.NewAPNsProvider(provider.APNsConfig{
+			TeamID:     cfg.APNsTeamID,
+			KeyID:      cfg.APNsKeyID,
+			Topic:      cfg.APNsTopic,
+			PrivateKey: key,
+			Sandbox:    cfg.APNsSandbox,
+		})
+		providers["apns"] = apns
+	}
+
+	if fcm != nil || apns != nil {
+		providers["push"] = provider.NewPushDispatcher(fcm, apns)
+	}
+	return nil
+}
+
+// parseRoutes reads "channel=name[:weight[:role]],...;channel=...".
+func parseRoutes(spec string, providers map[string]port.DeliveryProvider) ([]provider.Route, error) {
+	var routes []provider.Route
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		channel, targets, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("provider route %q: want channel=provider,...", entry)
+		}
+		ch := domain.Channel(strings.TrimSpace(channel))
+		if !slices.Contains(channels, ch) {
+			return nil, fmt.Errorf("provider route %q: %w", entry, domain.ErrInvalidChannel)
+		}
+
+		for _, target := range strings.Split(targets, ",") {
+			parts := strings.Split(strings.TrimSpace(target), ":")
+			rt := provider.Route{Name: parts[0], Channel: ch, Weight: 1, Role: provider.RolePrimary}
+
+			p, ok := providers[rt.Name]
+			if !ok {
+				return nil, fmt.Errorf("provider route %q: unknown provider %q", entry, rt.Name)
+			}
+			rt.Provider = p
+
+			if len(parts) > 1 {
+				weight, err := strconv.Atoi(parts[1])
+				if err != nil || weight < 1 {
+					return nil, fmt.Errorf("provider route %q: invalid weight %q", entry, parts[1])
+				}
+				rt.Weight = weight
+			}
+			if len(parts) > 2 {
+				rt.Role = provider.Role(parts[2])
+				if rt.Role != provider.RolePrimary && rt.Role != provider.RoleSecondary {
+					return nil, fmt.Errorf("provider route %q: invalid role %q", entry, parts[2])
+				}
+			}
+			routes = append(routes, rt)
+		}
+	}
+	return routes, nil
+}
+
+// parseRateLimits reads "name=perSecond,channel/name=perSecond,...". A
+// channel-qualified limit applies to that route only.
+func parseRateLimits(spec string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("provider rate limit %q: want provider=perSecond", entry)
+		}
+		limit, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("provider rate limit %q: invalid rate %q", entry, value)
+		}
+		limits[strings.TrimSpace(name)] = limit
+	}
+	return limits, nil
+}
+
+// rateLimitFor picks a route's limit: channel/name, then name, then the
+// default for every route.
+func rateLimitFor(limits map[string]float64, rt provider.Route, fallback float64) float64 {
+	if limit, ok := limits[string(rt.Channel)+"/"+rt.Name]; ok {
+		return limit
+	}
+	if limit, ok := limits[rt.Name]; ok {
+		return limit
+	}
+	return fallback
+}
+
+// parseBreakerSettings reads
+// "target=failures:N,open_timeout:D,half_open:N;target=...", where a target
+// is a channel, a provider name or channel/name. Settings left out are not
+// overridden.
+func parseBreakerSettings(spec string) (map[string]circuitbreaker.Settings, error) {
+	overrides := make(map[string]circuitbreaker.Settings)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, settings, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("breaker settings %q: want target=setting:value,...", entry)
+		}
+
+		var s circuitbreaker.Settings
+		for _, setting := range strings.Split(settings, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(setting), ":")
+			var err error
+			switch name {
+			case "failures":
+				s.Failures, err = strconv.Atoi(value)
+			case "open_timeout":
+				s.OpenTimeout, err = time.ParseDuration(value)
+			case "half_open":
+				s.HalfOpenRequests, err = strconv.Atoi(value)
+			default:
+				return nil, fmt.Errorf("breaker settings %q: unknown setting %q", entry, name)
+			}
+			if err != nil || s.Failures < 0 || s.OpenTimeout < 0 || s.HalfOpenRequests < 0 {
+				return nil, fmt.Errorf("breaker settings %q: invalid %s %q", entry, name, value)
+			}
+		}
+		overrides[strings.TrimSpace(target)] = s
+	}
+	return overrides, nil
+}
+
+// breakerSettingsFor layers a route's overrides on the defaults, each more
+// specific target winning: channel, then name, then channel/name.
+func breakerSettingsFor(overrides map[string]circuitbreaker.Settings, rt provider.Route, defaults circuitbreaker.Settings) circuitbreaker.Settings {
+	s := defaults
+	for _, target := range []string{string(rt.Channel), rt.Name, string(rt.Channel) + "/" + rt.Name} {
+		o, ok := overrides[target]
+		if !ok {
+			continue
+		}
+		if o.Failures > 0 {
+			s.Failures = o.Failures
+		}
+		if o.OpenTimeout > 0 {
+			s.OpenTimeout = o.OpenTimeout
+		}
+		if o.HalfOpenRequests > 0 {
+			s.HalfOpenRequests = o.HalfOpenRequests
+		}
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/postgres"
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue/memory"
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue/pgqueue"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/config"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+// queueBackend is the publisher and consumers for the configured
+// QUEUE_BACKEND. scheduled is nil for backends that leave scheduling to the
+// Postgres poll; services are backend-specific background consumers.
+type queueBackend struct {
+	publisher port.QueuePublisher
+	consumer  port.QueueConsumer
+	scheduled port.ScheduledQueueConsumer
+	services  []service
+}
+
+type service struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+func newQueueBackend(cfg *config.Config, db *sqlx.DB, rateStore ratelimit.Store, deadLetters port.DeadLetterHandler, log *zap.Logger) (*queueBackend, error) {
+	switch cfg.QueueBackend {
+	case config.QueueBackendKafka:
+		consumerConfig := queue.ConsumerConfig{
+			Brokers:        cfg.KafkaBrokers,
+			Group:          cfg.KafkaConsumerGroup,
+			RatePerChannel: cfg.RateLimitPerChannel,
+			RateStore:      rateStore,
+			Concurrency:    cfg.WorkerConcurrency,
+			Logger:         log,
+			Ordered:        cfg.OrderedDelivery,
+		}
+		retryConsumer := queue.NewRetryConsumer(consumerConfig)
+		deadLetterConsumer := queue.NewDeadLetterConsumer(consumerConfig)
+
+		return &queueBackend{
+			publisher: queue.NewProducer(cfg.KafkaBrokers, cfg.OrderedDelivery),
+			consumer:  queue.NewConsumer(consumerConfig),
+			scheduled: queue.NewScheduledConsumer(consumerConfig),
+			services: []service{
+				{name: "retry consumer", start: retryConsumer.Start, stop: retryConsumer.Stop},
+				{
+					name:  "dead letter consumer",
+					start: func(ctx context.Context) error { return deadLetterConsumer.Start(ctx, deadLetters) },
+					stop:  deadLetterConsumer.Stop,
+				},
+			},
+		}, nil
+
+	case config.QueueBackendMemory:
+		q := memory.New(memory.Config{
+			Concurrency:    cfg.WorkerConcurrency,
+			DeadLetters:    deadLetters,
+			Logger:         log,
+			RatePerChannel: cfg.RateLimitPerChannel,
+			RateStore:      rateStore,
+		})
+		return &queueBackend{publisher: q, consumer: q, scheduled: q.Scheduled()}, nil
+
+	case config.QueueBackendPostgres:
+		q := pgqueue.New(db, pgqueue.Config{
+			Concurrency:    cfg.WorkerConcurrency,
+			DeadLetters:    deadLetters,
+			Logger:         log,
+			RatePerChannel: cfg.RateLimitPerChannel,
+			RateStore:      rateStore,
+		})
+		return &queueBackend{publisher: q, consumer: q}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.QueueBackend)
+	}
+}
+
+// newRateStore returns where rate limits are kept: Postgres, so the limits
+// hold across every worker, or nil for limits each worker keeps itself.
+func newRateStore(cfg *config.Config, db *sqlx.DB) (ratelimit.Store, error) {
+	switch cfg.RateLimitStore {
+	case config.RateLimitStorePostgres:
+		return postgres.NewRateBucketRepo(db), nil
+	case config.RateLimitStoreLocal:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimitStore)
+	}
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type CircuitBreakerHandler struct {
+	service *app.CircuitBreakerService
+}
+
+func NewCircuitBreakerHandler(service *app.CircuitBreakerService) *CircuitBreakerHandler {
+	return &CircuitBreakerHandler{service: service}
+}
+
+type BreakerControlResponse struct {
+	ForcedOpen bool       `json:"forced_open"`
+	OpenUntil  *time.Time `json:"open_until,omitempty"`
+	ResetAt    *time.Time `json:"reset_at,omitempty"`
+	UpdatedBy  string     `json:"updated_by"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type BreakerWorkerResponse struct {
+	InstanceID  string                        `json:"instance_id"`
+	State       domain.BreakerState           `json:"state"`
+	ForcedOpen  bool                          `json:"forced_open"`
+	OpenUntil   *time.Time                    `json:"open_until,omitempty"`
+	Transitions map[domain.BreakerState]int64 `json:"transitions"`
+	ChangedAt   *time.Time                    `json:"changed_at,omitempty"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+}
+
+type BreakerStatusResponse struct {
+	Channel  domain.Channel          `json:"channel"`
+	Provider string                  `json:"provider"`
+	Control  *BreakerControlResponse `json:"control,omitempty"`
+	Workers  []BreakerWorkerResponse `json:"workers"`
+}
+
+func NewBreakerStatusResponse(s app.BreakerStatus) BreakerStatusResponse {
+	resp := BreakerStatusResponse{
+		Channel:  s.Channel,
+		Provider: s.Provider,
+		Workers:  make([]BreakerWorkerResponse, len(s.Workers)),
+	}
+	if c := s.Control; c != nil {
+		resp.Control = &BreakerControlResponse{
+			ForcedOpen: c.ForcedOpen,
+			OpenUntil:  c.OpenUntil,
+			ResetAt:    c.ResetAt,
+			UpdatedBy:  c.UpdatedBy,
+			UpdatedAt:  c.UpdatedAt,
+		}
+	}
+	for i, w := range s.Workers {
+		resp.Workers[i] = BreakerWorkerResponse{
+			InstanceID:  w.InstanceID,
+			State:       w.State,
+			ForcedOpen:  w.ForcedOpen,
+			OpenUntil:   w.OpenUntil,
+			Transitions: w.Transitions,
+			ChangedAt:   w.ChangedAt,
+			UpdatedAt:   w.UpdatedAt,
+		}
+	}
+	return resp
+}
+
+func (h *CircuitBreakerHandler) List(c *gin.Context) {
+	statuses, err := h.service.List(c.Request.Context())
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	data := make([]BreakerStatusResponse, len(statuses))
+	for i, s := range statuses {
+		data[i] = NewBreakerStatusResponse(s)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+func (h *CircuitBreakerHandler) ForceOpen(c *gin.Context) {
+	channel, provider := domain.Channel(c.Param("channel")), c.Param("provider")
+	if err := h.service.ForceOpen(c.Request.Context(), channel, provider); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "forced_open"})
+}
+
+func (h *CircuitBreakerHandler) Reset(c *gin.Context) {
+	channel, provider := domain.Channel(c.Param("channel")), c.Param("provider")
+	if err := h.service.Reset(c.Request.Context(), channel, provider); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "reset"})
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type CreateUserRequest struct {
+	ID        string              `json:"id" binding:"required,max=255"`
+	Locale    string              `json:"locale,omitempty"`
+	TimeZone  string              `json:"time_zone,omitempty"`
+	Addresses []AddAddressRequest `json:"addresses,omitempty" binding:"omitempty,max=50,dive"`
+}
+
+func (r *CreateUserRequest) ToInput() app.CreateUserInput {
+	input := app.CreateUserInput{
+		ID:        r.ID,
+		Locale:    r.Locale,
+		TimeZone:  r.TimeZone,
+		Addresses: make([]app.AddAddressInput, len(r.Addresses)),
+	}
+	for i, a := range r.Addresses {
+		input.Addresses[i] = a.ToInput()
+	}
+	return input
+}
+
+type UpdateUserRequest struct {
+	Locale   string `json:"locale"`
+	TimeZone string `json:"time_zone"`
+}
+
+type AddAddressRequest struct {
+	Channel  string `json:"channel" binding:"required,oneof=sms email push"`
+	Address  string `json:"address" binding:"required"`
+	Verified bool   `json:"verified"`
+}
+
+func (r *AddAddressRequest) ToInput() app.AddAddressInput {
+	return app.AddAddressInput{
+		Channel:  domain.Channel(r.Channel),
+		Address:  r.Address,
+		Verified: r.Verified,
+	}
+}
+
+type UpdateAddressRequest struct {
+	Verified *bool `json:"verified,omitempty"`
+	Active   *bool `json:"active,omitempty"`
+}
+
+type ListUsersRequest struct {
+	Cursor   *string `form:"cursor"`
+	PageSize int     `form:"page_size"`
+}
+
+type AddressResponse struct {
+	ID        string    `json:"id"`
+	Channel   string    `json:"channel"`
+	Address   string    `json:"address"`
+	Verified  bool      `json:"verified"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewAddressResponse(a *domain.ContactAddress) AddressResponse {
+	return AddressResponse{
+		ID:        a.ID.String(),
+		Channel:   string(a.Channel),
+		Address:   a.Address,
+		Verified:  a.Verified,
+		Active:    a.Active,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+type UserResponse struct {
+	ID        string            `json:"id"`
+	Locale    string            `json:"locale,omitempty"`
+	TimeZone  string            `json:"time_zone,omitempty"`
+	Addresses []AddressResponse `json:"addresses,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func NewUserResponse(u *domain.User) UserResponse {
+	resp := UserResponse{
+		ID:        u.ID,
+		Locale:    u.Locale,
+		TimeZone:  u.TimeZone,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+	for _, a := range u.Addresses {
+		resp.Addresses = append(resp.Addresses, NewAddressResponse(a))
+	}
+	return resp
+}
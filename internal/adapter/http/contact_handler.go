@@ -0,0 +1,152 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+)
+
+type ContactHandler struct {
+	service *app.ContactService
+}
+
+func NewContactHandler(service *app.ContactService) *ContactHandler {
+	return &ContactHandler{service: service}
+}
+
+func (h *ContactHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), req.ToInput())
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewUserResponse(user))
+}
+
+func (h *ContactHandler) GetUser(c *gin.Context) {
+	user, err := h.service.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewUserResponse(user))
+}
+
+func (h *ContactHandler) ListUsers(c *gin.Context) {
+	var req ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	users, err := h.service.ListUsers(c.Request.Context(), req.Cursor, req.PageSize)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	resp := ListResponse[UserResponse]{Data: make([]UserResponse, len(users))}
+	for i, u := range users {
+		resp.Data[i] = NewUserResponse(u)
+	}
+	if len(users) == req.PageSize {
+		last := users[len(users)-1].ID
+		resp.NextCursor = &last
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ContactHandler) UpdateUser(c *gin.Context) {
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), c.Param("id"), req.Locale, req.TimeZone)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewUserResponse(user))
+}
+
+func (h *ContactHandler) DeleteUser(c *gin.Context) {
+	if err := h.service.DeleteUser(c.Request.Context(), c.Param("id")); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ContactHandler) AddAddress(c *gin.Context) {
+	var req AddAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := h.service.AddAddress(c.Request.Context(), c.Param("id"), req.ToInput())
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewAddressResponse(address))
+}
+
+func (h *ContactHandler) UpdateAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("address_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid address id"})
+		return
+	}
+
+	var req UpdateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := h.service.UpdateAddress(c.Request.Context(), c.Param("id"), id, app.UpdateAddressInput{
+		Verified: req.Verified,
+		Active:   req.Active,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewAddressResponse(address))
+}
+
+func (h *ContactHandler) DeleteAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("address_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid address id"})
+		return
+	}
+
+	if err := h.service.DeleteAddress(c.Request.Context(), c.Param("id"), id); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type ListDeadLettersRequest struct {
+	Topic          *string `form:"topic"`
+	NotificationID *string `form:"notification_id"`
+	Redriven       *bool   `form:"redriven"`
+	DateFrom       *string `form:"date_from"`
+	DateTo         *string `form:"date_to"`
+	Cursor         *string `form:"cursor"`
+	PageSize       int     `form:"page_size"`
+}
+
+func (r *ListDeadLettersRequest) ToFilter() domain.DeadLetterFilter {
+	filter := domain.DeadLetterFilter{
+		Topic:    r.Topic,
+		Redriven: r.Redriven,
+		PageSize: r.PageSize,
+	}
+
+	if r.NotificationID != nil {
+		if id, err := uuid.Parse(*r.NotificationID); err == nil {
+			filter.NotificationID = &id
+		}
+	}
+	if r.DateFrom != nil {
+		if t, err := time.Parse(time.RFC3339, *r.DateFrom); err == nil {
+			filter.DateFrom = &t
+		}
+	}
+	if r.DateTo != nil {
+		if t, err := time.Parse(time.RFC3339, *r.DateTo); err == nil {
+			filter.DateTo = &t
+		}
+	}
+	if r.Cursor != nil {
+		filter.Cursor = parseDeadLetterCursor(*r.Cursor)
+	}
+
+	return filter
+}
+
+// A dead letter cursor is the last entry's creation time and ID,
+// "<RFC 3339 time>_<id>". Cursors that don't parse start from the top.
+func formatDeadLetterCursor(c *domain.DeadLetterCursor) string {
+	return c.CreatedAt.UTC().Format(time.RFC3339Nano) + "_" + c.ID.String()
+}
+
+func parseDeadLetterCursor(s string) *domain.DeadLetterCursor {
+	at, id, ok := strings.Cut(s, "_")
+	if !ok {
+		return nil
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	return &domain.DeadLetterCursor{CreatedAt: createdAt, ID: parsed}
+}
+
+type RedriveDeadLettersRequest struct {
+	Topic          *string    `json:"topic,omitempty"`
+	NotificationID *string    `json:"notification_id,omitempty"`
+	DateFrom       *time.Time `json:"date_from,omitempty"`
+	DateTo         *time.Time `json:"date_to,omitempty"`
+	Limit          int        `json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+func (r *RedriveDeadLettersRequest) ToFilter() domain.DeadLetterFilter {
+	filter := domain.DeadLetterFilter{
+		Topic:    r.Topic,
+		DateFrom: r.DateFrom,
+		DateTo:   r.DateTo,
+	}
+
+	if r.NotificationID != nil {
+		if id, err := uuid.Parse(*r.NotificationID); err == nil {
+			filter.NotificationID = &id
+		}
+	}
+
+	return filter
+}
+
+type RedriveDeadLettersResponse struct {
+	Redriven int `json:"redriven"`
+	Skipped  int `json:"skipped"`
+}
+
+type DeadLetterAttemptResponse struct {
+	Attempt  int       `json:"attempt"`
+	Topic    string    `json:"topic"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+type DeadLetterResponse struct {
+	ID             string                      `json:"id"`
+	NotificationID *string                     `json:"notification_id,omitempty"`
+	Topic          string                      `json:"topic"`
+	Partition      int                         `json:"partition"`
+	Offset         int64                       `json:"offset"`
+	Payload        string                      `json:"payload"`
+	Error          string                      `json:"error"`
+	Attempts       []DeadLetterAttemptResponse `json:"attempts"`
+	Redrivable     bool                        `json:"redrivable"`
+	CreatedAt      time.Time                   `json:"created_at"`
+	RedrivenAt     *time.Time                  `json:"redriven_at,omitempty"`
+}
+
+func NewDeadLetterResponse(d *domain.DeadLetter) DeadLetterResponse {
+	resp := DeadLetterResponse{
+		ID:         d.ID.String(),
+		Topic:      d.Topic,
+		Partition:  d.Partition,
+		Offset:     d.Offset,
+		Payload:    string(d.Payload),
+		Error:      d.Error,
+		Attempts:   make([]DeadLetterAttemptResponse, len(d.Attempts)),
+		Redrivable: d.CanRedrive(),
+		CreatedAt:  d.CreatedAt,
+		RedrivenAt: d.RedrivenAt,
+	}
+
+	if d.NotificationID != nil {
+		s := d.NotificationID.String()
+		resp.NotificationID = &s
+	}
+	for i, a := range d.Attempts {
+		resp.Attempts[i] = DeadLetterAttemptResponse(a)
+	}
+
+	return resp
+}
+
+func NewDeadLetterListResponse(entries []*domain.DeadLetter, pageSize int) ListResponse[DeadLetterResponse] {
+	data := make([]DeadLetterResponse, len(entries))
+	for i, d := range entries {
+		data[i] = NewDeadLetterResponse(d)
+	}
+
+	var nextCursor *string
+	if len(entries) == pageSize {
+		last := formatDeadLetterCursor(entries[len(entries)-1].Cursor())
+		nextCursor = &last
+	}
+
+	return ListResponse[DeadLetterResponse]{
+		Data:       data,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func TestDeadLetterCursor_RoundTrip(t *testing.T) {
+	want := &domain.DeadLetterCursor{
+		CreatedAt: time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC),
+		ID:        uuid.Must(uuid.NewV7()),
+	}
+
+	got := parseDeadLetterCursor(formatDeadLetterCursor(want))
+
+	require.NotNil(t, got)
+	assert.True(t, want.CreatedAt.Equal(got.CreatedAt))
+	assert.Equal(t, want.ID, got.ID)
+
+	assert.Nil(t, parseDeadLetterCursor(want.ID.String()), "bare IDs from before tuple cursors")
+	assert.Nil(t, parseDeadLetterCursor("garbage_value"))
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+)
+
+type DeadLetterHandler struct {
+	service *app.DeadLetterService
+}
+
+func NewDeadLetterHandler(service *app.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	var req ListDeadLettersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := req.ToFilter()
+	entries, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDeadLetterListResponse(entries, filter.PageSize))
+}
+
+func (h *DeadLetterHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid dead letter id"})
+		return
+	}
+
+	entry, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDeadLetterResponse(entry))
+}
+
+func (h *DeadLetterHandler) Redrive(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid dead letter id"})
+		return
+	}
+
+	if err := h.service.Redrive(c.Request.Context(), id); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "redriven"})
+}
+
+func (h *DeadLetterHandler) RedriveMatching(c *gin.Context) {
+	var req RedriveDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.service.RedriveMatching(c.Request.Context(), req.ToFilter(), req.Limit)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, RedriveDeadLettersResponse{
+		Redriven: result.Redriven,
+		Skipped:  result.Skipped,
+	})
+}
@@ -14,6 +14,8 @@ type HealthHandler struct {
 	kafkaBrokers []string
 }
 
+// NewHealthHandler checks Kafka reachability only when kafkaBrokers is
+// non-empty, i.e. when Kafka is the configured queue backend.
 func NewHealthHandler(db *sqlx.DB, kafkaBrokers []string) *HealthHandler {
 	return &HealthHandler{db: db, kafkaBrokers: kafkaBrokers}
 }
@@ -32,6 +34,11 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 	}
 	checks["database"] = "healthy"
 
+	if len(h.kafkaBrokers) == 0 {
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+		return
+	}
+
 	broker := h.kafkaBrokers[0]
 	if !strings.Contains(broker, ":") {
 		broker = broker + ":9092"
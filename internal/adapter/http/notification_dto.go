@@ -8,26 +8,65 @@ import (
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 )
 
+// CreateNotificationRequest sends to Recipient, or without one to UserID's
+// addresses on the channel: every active device for push.
 type CreateNotificationRequest struct {
 	Channel           string            `json:"channel" binding:"required,oneof=sms email push"`
-	Recipient         string            `json:"recipient" binding:"required"`
+	Recipient         string            `json:"recipient" binding:"required_without=UserID"`
+	UserID            *string           `json:"user_id,omitempty"`
 	Content           string            `json:"content" binding:"required"`
 	Priority          string            `json:"priority" binding:"required,oneof=high normal low"`
 	ScheduledAt       *time.Time        `json:"scheduled_at,omitempty"`
 	IdempotencyKey    *string           `json:"idempotency_key,omitempty"`
+	OrderingKey       *string           `json:"ordering_key,omitempty" binding:"omitempty,max=255"`
 	TemplateID        *string           `json:"template_id,omitempty"`
 	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+	Fallbacks         []FallbackRequest `json:"fallbacks,omitempty" binding:"omitempty,max=5,dive"`
+	// FallbackTimeoutSeconds starts the first fallback when the notification
+	// hasn't been delivered that long after it was due.
+	FallbackTimeoutSeconds int `json:"fallback_timeout_seconds,omitempty" binding:"min=0"`
+}
+
+type FallbackRequest struct {
+	Channel           string            `json:"channel" binding:"required,oneof=sms email push"`
+	Recipient         string            `json:"recipient" binding:"required"`
+	Content           string            `json:"content,omitempty"`
+	TemplateID        *string           `json:"template_id,omitempty"`
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+	TimeoutSeconds    int               `json:"timeout_seconds,omitempty" binding:"min=0"`
+}
+
+func (r *FallbackRequest) ToInput() app.FallbackInput {
+	input := app.FallbackInput{
+		Channel:           domain.Channel(r.Channel),
+		Recipient:         r.Recipient,
+		Content:           r.Content,
+		TemplateVariables: r.TemplateVariables,
+		Timeout:           time.Duration(r.TimeoutSeconds) * time.Second,
+	}
+
+	if r.TemplateID != nil {
+		id, err := uuid.Parse(*r.TemplateID)
+		if err == nil {
+			input.TemplateID = &id
+		}
+	}
+
+	return input
 }
 
 func (r *CreateNotificationRequest) ToInput() app.CreateNotificationInput {
 	input := app.CreateNotificationInput{
 		Channel:           domain.Channel(r.Channel),
 		Recipient:         r.Recipient,
+		UserID:            r.UserID,
 		Content:           r.Content,
 		Priority:          domain.Priority(r.Priority),
 		ScheduledAt:       r.ScheduledAt,
 		IdempotencyKey:    r.IdempotencyKey,
+		OrderingKey:       r.OrderingKey,
 		TemplateVariables: r.TemplateVariables,
+		FallbackTimeout:   time.Duration(r.FallbackTimeoutSeconds) * time.Second,
 	}
 
 	if r.TemplateID != nil {
@@ -36,6 +75,9 @@ func (r *CreateNotificationRequest) ToInput() app.CreateNotificationInput {
 			input.TemplateID = &id
 		}
 	}
+	for _, f := range r.Fallbacks {
+		input.Fallbacks = append(input.Fallbacks, f.ToInput())
+	}
 
 	return input
 }
@@ -44,6 +86,53 @@ type CreateBatchRequest struct {
 	Notifications []CreateNotificationRequest `json:"notifications" binding:"required,min=1,max=1000,dive"`
 }
 
+// CreateGroupRequest sends to explicit recipients, or with a UserID to the
+// user's addresses on each channel that has no recipient.
+type CreateGroupRequest struct {
+	UserID            *string               `json:"user_id,omitempty"`
+	Priority          string                `json:"priority" binding:"required,oneof=high normal low"`
+	ScheduledAt       *time.Time            `json:"scheduled_at,omitempty"`
+	IdempotencyKey    *string               `json:"idempotency_key,omitempty"`
+	TemplateVariables map[string]string     `json:"template_variables,omitempty"`
+	Channels          []GroupChannelRequest `json:"channels" binding:"required,min=1,max=3,dive"`
+}
+
+type GroupChannelRequest struct {
+	Channel           string            `json:"channel" binding:"required,oneof=sms email push"`
+	Recipient         string            `json:"recipient,omitempty"`
+	Content           string            `json:"content,omitempty"`
+	TemplateID        *string           `json:"template_id,omitempty"`
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+}
+
+func (r *CreateGroupRequest) ToInput() app.CreateGroupInput {
+	input := app.CreateGroupInput{
+		UserID:            r.UserID,
+		Priority:          domain.Priority(r.Priority),
+		ScheduledAt:       r.ScheduledAt,
+		IdempotencyKey:    r.IdempotencyKey,
+		TemplateVariables: r.TemplateVariables,
+		Channels:          make([]app.GroupChannelInput, len(r.Channels)),
+	}
+
+	for i, c := range r.Channels {
+		input.Channels[i] = app.GroupChannelInput{
+			Channel:           domain.Channel(c.Channel),
+			Recipient:         c.Recipient,
+			Content:           c.Content,
+			TemplateVariables: c.TemplateVariables,
+		}
+		if c.TemplateID != nil {
+			id, err := uuid.Parse(*c.TemplateID)
+			if err == nil {
+				input.Channels[i].TemplateID = &id
+			}
+		}
+	}
+
+	return input
+}
+
 type ListNotificationsRequest struct {
 	Status   *string `form:"status"`
 	Channel  *string `form:"channel"`
@@ -88,6 +177,9 @@ func (r *ListNotificationsRequest) ToFilter() domain.NotificationFilter {
 type NotificationResponse struct {
 	ID                string            `json:"id"`
 	BatchID           *string           `json:"batch_id,omitempty"`
+	GroupID           *string           `json:"group_id,omitempty"`
+	UserID            *string           `json:"user_id,omitempty"`
+	OrderingKey       *string           `json:"ordering_key,omitempty"`
 	Channel           string            `json:"channel"`
 	Recipient         string            `json:"recipient"`
 	Content           string            `json:"content"`
@@ -100,8 +192,13 @@ type NotificationResponse struct {
 	RetryCount        int               `json:"retry_count"`
 	MaxRetries        int               `json:"max_retries"`
 	ProviderMessageID *string           `json:"provider_message_id,omitempty"`
+	Provider          *string           `json:"provider,omitempty"`
 	TemplateID        *string           `json:"template_id,omitempty"`
 	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+	ParentID          *string           `json:"parent_id,omitempty"`
+	FallbacksLeft     int               `json:"remaining_fallbacks,omitempty"`
+	FallbackAt        *time.Time        `json:"fallback_at,omitempty"`
+	NextAttemptID     *string           `json:"next_attempt_id,omitempty"`
 	CreatedAt         time.Time         `json:"created_at"`
 	UpdatedAt         time.Time         `json:"updated_at"`
 }
@@ -109,6 +206,8 @@ type NotificationResponse struct {
 func NewNotificationResponse(n *domain.Notification) NotificationResponse {
 	resp := NotificationResponse{
 		ID:                n.ID.String(),
+		UserID:            n.UserID,
+		OrderingKey:       n.OrderingKey,
 		Channel:           string(n.Channel),
 		Recipient:         n.Recipient,
 		Content:           n.Content,
@@ -121,7 +220,10 @@ func NewNotificationResponse(n *domain.Notification) NotificationResponse {
 		RetryCount:        n.RetryCount,
 		MaxRetries:        n.MaxRetries,
 		ProviderMessageID: n.ProviderMessageID,
+		Provider:          n.Provider,
 		TemplateVariables: n.TemplateVariables,
+		FallbacksLeft:     len(n.Fallbacks),
+		FallbackAt:        n.FallbackAt,
 		CreatedAt:         n.CreatedAt,
 		UpdatedAt:         n.UpdatedAt,
 	}
@@ -134,10 +236,42 @@ func NewNotificationResponse(n *domain.Notification) NotificationResponse {
 		s := n.TemplateID.String()
 		resp.TemplateID = &s
 	}
+	if n.GroupID != nil {
+		s := n.GroupID.String()
+		resp.GroupID = &s
+	}
+	if n.ParentID != nil {
+		s := n.ParentID.String()
+		resp.ParentID = &s
+	}
+	if n.NextAttemptID != nil {
+		s := n.NextAttemptID.String()
+		resp.NextAttemptID = &s
+	}
 
 	return resp
 }
 
+type FallbackChainResponse struct {
+	ParentID           string                 `json:"parent_id"`
+	Status             string                 `json:"status"`
+	RemainingFallbacks int                    `json:"remaining_fallbacks"`
+	Attempts           []NotificationResponse `json:"attempts"`
+}
+
+func NewFallbackChainResponse(c *domain.FallbackChain) FallbackChainResponse {
+	attempts := make([]NotificationResponse, len(c.Attempts))
+	for i, n := range c.Attempts {
+		attempts[i] = NewNotificationResponse(n)
+	}
+	return FallbackChainResponse{
+		ParentID:           c.ParentID.String(),
+		Status:             string(c.Status()),
+		RemainingFallbacks: c.RemainingFallbacks(),
+		Attempts:           attempts,
+	}
+}
+
 func NewNotificationListResponse(notifications []*domain.Notification, pageSize int) ListResponse[NotificationResponse] {
 	data := make([]NotificationResponse, len(notifications))
 	for i, n := range notifications {
@@ -157,27 +291,62 @@ func NewNotificationListResponse(notifications []*domain.Notification, pageSize
 }
 
 type BatchResponse struct {
-	ID             string    `json:"id"`
-	TotalCount     int       `json:"total_count"`
-	PendingCount   int       `json:"pending_count"`
-	DeliveredCount int       `json:"delivered_count"`
-	FailedCount    int       `json:"failed_count"`
-	CancelledCount int       `json:"cancelled_count"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID               string    `json:"id"`
+	TotalCount       int       `json:"total_count"`
+	PendingCount     int       `json:"pending_count"`
+	SentCount        int       `json:"sent_count"`
+	DeliveredCount   int       `json:"delivered_count"`
+	UndeliveredCount int       `json:"undelivered_count"`
+	ReadCount        int       `json:"read_count"`
+	FailedCount      int       `json:"failed_count"`
+	CancelledCount   int       `json:"cancelled_count"`
+	SuppressedCount  int       `json:"suppressed_count"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 func NewBatchResponse(b *domain.NotificationBatch) BatchResponse {
 	return BatchResponse{
-		ID:             b.ID.String(),
-		TotalCount:     b.TotalCount,
-		PendingCount:   b.PendingCount,
-		DeliveredCount: b.DeliveredCount,
-		FailedCount:    b.FailedCount,
-		CancelledCount: b.CancelledCount,
-		CreatedAt:      b.CreatedAt,
+		ID:               b.ID.String(),
+		TotalCount:       b.TotalCount,
+		PendingCount:     b.PendingCount,
+		SentCount:        b.SentCount,
+		DeliveredCount:   b.DeliveredCount,
+		UndeliveredCount: b.UndeliveredCount,
+		ReadCount:        b.ReadCount,
+		FailedCount:      b.FailedCount,
+		CancelledCount:   b.CancelledCount,
+		SuppressedCount:  b.SuppressedCount,
+		CreatedAt:        b.CreatedAt,
 	}
 }
 
+type GroupResponse struct {
+	ID             string                 `json:"id"`
+	IdempotencyKey *string                `json:"idempotency_key,omitempty"`
+	Status         string                 `json:"status"`
+	StatusCounts   map[string]int         `json:"status_counts"`
+	Notifications  []NotificationResponse `json:"notifications"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+func NewGroupResponse(g *domain.NotificationGroup) GroupResponse {
+	resp := GroupResponse{
+		ID:             g.ID.String(),
+		IdempotencyKey: g.IdempotencyKey,
+		Status:         string(g.Status()),
+		StatusCounts:   make(map[string]int),
+		Notifications:  make([]NotificationResponse, len(g.Notifications)),
+		CreatedAt:      g.CreatedAt,
+	}
+	for status, count := range g.Counts() {
+		resp.StatusCounts[string(status)] = count
+	}
+	for i, n := range g.Notifications {
+		resp.Notifications[i] = NewNotificationResponse(n)
+	}
+	return resp
+}
+
 type CreateBatchResponse struct {
 	Batch         BatchResponse          `json:"batch"`
 	Notifications []NotificationResponse `json:"notifications"`
@@ -193,3 +362,17 @@ func NewCreateBatchResponse(b *domain.NotificationBatch, notifications []*domain
 		Notifications: notifs,
 	}
 }
+
+// CreateToUserResponse lists what a create sent to a user produced: one
+// notification per address.
+type CreateToUserResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+}
+
+func NewCreateToUserResponse(notifications []*domain.Notification) CreateToUserResponse {
+	resp := CreateToUserResponse{Notifications: make([]NotificationResponse, len(notifications))}
+	for i, n := range notifications {
+		resp.Notifications[i] = NewNotificationResponse(n)
+	}
+	return resp
+}
@@ -26,6 +26,16 @@ func (h *NotificationHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if req.Recipient == "" {
+		notifications, err := h.service.CreateToUser(c.Request.Context(), req.ToInput())
+		if err != nil {
+			handleDomainError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, NewCreateToUserResponse(notifications))
+		return
+	}
+
 	notification, err := h.service.Create(c.Request.Context(), req.ToInput())
 	if err != nil {
 		handleDomainError(c, err)
@@ -58,6 +68,22 @@ func (h *NotificationHandler) CreateBatch(c *gin.Context) {
 	c.JSON(http.StatusCreated, NewCreateBatchResponse(batch, notifications))
 }
 
+func (h *NotificationHandler) CreateGroup(c *gin.Context) {
+	var req CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	group, err := h.service.CreateGroup(c.Request.Context(), req.ToInput())
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewGroupResponse(group))
+}
+
 func (h *NotificationHandler) GetByID(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -74,6 +100,22 @@ func (h *NotificationHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, NewNotificationResponse(notification))
 }
 
+func (h *NotificationHandler) GetChain(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid notification id"})
+		return
+	}
+
+	chain, err := h.service.GetChain(c.Request.Context(), id)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewFallbackChainResponse(chain))
+}
+
 func (h *NotificationHandler) List(c *gin.Context) {
 	var req ListNotificationsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -122,11 +164,50 @@ func (h *NotificationHandler) GetBatch(c *gin.Context) {
 	c.JSON(http.StatusOK, NewBatchResponse(batch))
 }
 
+func (h *NotificationHandler) GetGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid group id"})
+		return
+	}
+
+	group, err := h.service.GetGroup(c.Request.Context(), id)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewGroupResponse(group))
+}
+
+func (h *NotificationHandler) CancelGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid group id"})
+		return
+	}
+
+	cancelled, err := h.service.CancelGroup(c.Request.Context(), id)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "cancelled", "cancelled_count": cancelled})
+}
+
 func handleDomainError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, domain.ErrNotificationNotFound),
 		errors.Is(err, domain.ErrBatchNotFound),
-		errors.Is(err, domain.ErrTemplateNotFound):
+		errors.Is(err, domain.ErrTemplateNotFound),
+		errors.Is(err, domain.ErrLeaseNotFound),
+		errors.Is(err, domain.ErrDeadLetterNotFound),
+		errors.Is(err, domain.ErrBreakerNotFound),
+		errors.Is(err, domain.ErrGroupNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrAddressNotFound),
+		errors.Is(err, domain.ErrSuppressionNotFound):
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
 	case errors.Is(err, domain.ErrInvalidChannel),
 		errors.Is(err, domain.ErrInvalidRecipient),
@@ -138,12 +219,24 @@ func handleDomainError(c *gin.Context, err error) {
 		errors.Is(err, domain.ErrBatchEmpty),
 		errors.Is(err, domain.ErrEmptyTemplateName),
 		errors.Is(err, domain.ErrEmptyTemplateBody),
-		errors.Is(err, domain.ErrInvalidTemplateBody):
+		errors.Is(err, domain.ErrInvalidTemplateBody),
+		errors.Is(err, domain.ErrInvalidReceipt),
+		errors.Is(err, domain.ErrInvalidFallback),
+		errors.Is(err, domain.ErrInvalidGroup),
+		errors.Is(err, domain.ErrInvalidUser),
+		errors.Is(err, domain.ErrNoActiveAddress),
+		errors.Is(err, domain.ErrInvalidSuppression):
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-	case errors.Is(err, domain.ErrInvalidStatusTransition):
+	case errors.Is(err, domain.ErrRecipientSuppressed):
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrInvalidStatusTransition),
+		errors.Is(err, domain.ErrDeadLetterNotRedrivable),
+		errors.Is(err, domain.ErrClaimConflict):
 		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 	case errors.Is(err, domain.ErrDuplicateIdempotencyKey),
-		errors.Is(err, domain.ErrDuplicateTemplateName):
+		errors.Is(err, domain.ErrDuplicateTemplateName),
+		errors.Is(err, domain.ErrDuplicateUser),
+		errors.Is(err, domain.ErrDuplicateAddress):
 		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 	default:
 		_ = c.Error(err)
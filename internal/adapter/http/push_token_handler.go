@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type PushTokenHandler struct {
+	service *app.PushTokenService
+}
+
+func NewPushTokenHandler(service *app.PushTokenService) *PushTokenHandler {
+	return &PushTokenHandler{service: service}
+}
+
+type ListInvalidPushTokensRequest struct {
+	Since *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit int        `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+type InvalidPushTokenResponse struct {
+	Token         string    `json:"token"`
+	Provider      string    `json:"provider"`
+	Reason        string    `json:"reason"`
+	InvalidatedAt time.Time `json:"invalidated_at"`
+}
+
+func NewInvalidPushTokenResponse(t *domain.InvalidPushToken) InvalidPushTokenResponse {
+	return InvalidPushTokenResponse{
+		Token:         t.Token,
+		Provider:      t.Provider,
+		Reason:        t.Reason,
+		InvalidatedAt: t.InvalidatedAt,
+	}
+}
+
+func (h *PushTokenHandler) ListInvalid(c *gin.Context) {
+	var req ListInvalidPushTokensRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tokens, err := h.service.ListInvalid(c.Request.Context(), req.Since, req.Limit)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	data := make([]InvalidPushTokenResponse, len(tokens))
+	for i, t := range tokens {
+		data[i] = NewInvalidPushTokenResponse(t)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// receiptRetryAfter is how long a provider is asked to wait before
+// resending a receipt that arrived ahead of the send it reports on.
+const receiptRetryAfter = "5"
+
+// ReceiptHandler takes delivery receipts posted back by providers, either
+// as JSON or as the form-encoded status callback SMS aggregators send. A
+// receipt is only applied once its provider's verifier accepts it; verifiers
+// holds the provider-specific ones and signed checks every other provider.
+// Receipts that no verifier covers are refused.
+type ReceiptHandler struct {
+	service   *app.ReceiptService
+	verifiers map[string]ReceiptVerifier
+	signed    ReceiptVerifier
+}
+
+func NewReceiptHandler(service *app.ReceiptService, verifiers map[string]ReceiptVerifier, signed ReceiptVerifier) *ReceiptHandler {
+	return &ReceiptHandler{service: service, verifiers: verifiers, signed: signed}
+}
+
+type ReceiptRequest struct {
+	MessageID string `json:"message_id" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Error     string `json:"error"`
+}
+
+// statusCallbackForm is the Twilio status callback, which other aggregators
+// copy closely.
+type statusCallbackForm struct {
+	MessageSid    string `form:"MessageSid" binding:"required"`
+	MessageStatus string `form:"MessageStatus" binding:"required"`
+	ErrorCode     string `form:"ErrorCode"`
+	ErrorMessage  string `form:"ErrorMessage"`
+}
+
+type ReceiptResponse struct {
+	NotificationID string `json:"notification_id,omitempty"`
+	Status         string `json:"status"`
+	Applied        bool   `json:"applied"`
+}
+
+// receiptStatuses maps provider status words onto ours. A provider "failed"
+// arrives after it accepted the message, so it is undelivered rather than
+// failed.
+var receiptStatuses = map[string]domain.Status{
+	"sent":        domain.StatusSent,
+	"delivered":   domain.StatusDelivered,
+	"undelivered": domain.StatusUndelivered,
+	"failed":      domain.StatusUndelivered,
+	"read":        domain.StatusRead,
+}
+
+// intermediateReceiptStatuses carry no news past our sent status and are
+// acknowledged without a lookup.
+var intermediateReceiptStatuses = map[string]bool{
+	"accepted":  true,
+	"queued":    true,
+	"scheduled": true,
+	"sending":   true,
+}
+
+func (h *ReceiptHandler) Receive(c *gin.Context) {
+	provider := c.Param("name")
+
+	if err := h.verify(c.Request, provider); err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	messageID, status, errMsg, err := bindReceipt(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if intermediateReceiptStatuses[status] {
+		c.JSON(http.StatusOK, ReceiptResponse{Status: status, Applied: false})
+		return
+	}
+
+	mapped, ok := receiptStatuses[status]
+	if !ok {
+		mapped = domain.Status(status)
+	}
+	receipt, err := domain.NewDeliveryReceipt(provider, messageID, mapped, errMsg)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	n, applied, err := h.service.Apply(c.Request.Context(), receipt)
+	if errors.Is(err, domain.ErrNotificationNotFound) {
+		// The provider can report on a message before the worker has stored
+		// its provider_message_id. Ask for the receipt again rather than
+		// dropping it.
+		c.Header("Retry-After", receiptRetryAfter)
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ReceiptResponse{
+		NotificationID: n.ID.String(),
+		Status:         string(n.Status),
+		Applied:        applied,
+	})
+}
+
+func (h *ReceiptHandler) verify(r *http.Request, provider string) error {
+	verifier, ok := h.verifiers[provider]
+	if !ok {
+		verifier = h.signed
+	}
+	if verifier == nil {
+		return ErrInvalidReceiptSignature
+	}
+	return verifier(r)
+}
+
+// bindReceipt reads the receipt's message ID, lower-cased status and error
+// description from whichever body format the provider used.
+func bindReceipt(c *gin.Context) (messageID, status, errMsg string, err error) {
+	if c.ContentType() == "application/json" {
+		var req ReceiptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return "", "", "", err
+		}
+		return req.MessageID, strings.ToLower(req.Status), req.Error, nil
+	}
+
+	var form statusCallbackForm
+	if err := c.ShouldBind(&form); err != nil {
+		return "", "", "", err
+	}
+	errMsg = form.ErrorMessage
+	if errMsg == "" && form.ErrorCode != "" {
+		errMsg = fmt.Sprintf("provider error code %s", form.ErrorCode)
+	}
+	return form.MessageSid, strings.ToLower(form.MessageStatus), errMsg, nil
+}
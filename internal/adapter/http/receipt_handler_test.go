@@ -0,0 +1,118 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bindReceiptRouter() *gin.Engine {
+	r := setupTestRouter()
+	r.POST("/api/v1/providers/:name/receipts", func(c *gin.Context) {
+		messageID, status, errMsg, err := bindReceipt(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message_id": messageID, "status": status, "error": errMsg})
+	})
+	return r
+}
+
+func TestBindReceipt_JSON(t *testing.T) {
+	body := []byte(`{"message_id":"msg-1","status":"Delivered"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/webhook/receipts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	bindReceiptRouter().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "msg-1", resp["message_id"])
+	assert.Equal(t, "delivered", resp["status"])
+}
+
+func TestBindReceipt_StatusCallbackForm(t *testing.T) {
+	form := url.Values{
+		"MessageSid":    {"SM123"},
+		"MessageStatus": {"undelivered"},
+		"ErrorCode":     {"30003"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/twilio/receipts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	bindReceiptRouter().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "SM123", resp["message_id"])
+	assert.Equal(t, "undelivered", resp["status"])
+	assert.Equal(t, "provider error code 30003", resp["error"])
+}
+
+func TestBindReceipt_MissingMessageID(t *testing.T) {
+	body := []byte(`{"status":"delivered"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/webhook/receipts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	bindReceiptRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTwilioSignature_DocumentedExample(t *testing.T) {
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	assert.Equal(t, "0/KCTR6DLpKmkAf8muzZqo1nDgQ=",
+		twilioSignature("12345", "https://mycompany.com/myapp.php?foo=1&bar=2", params))
+}
+
+func TestTwilioReceiptVerifier(t *testing.T) {
+	verify := TwilioReceiptVerifier("token", "https://api.example.com/")
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}
+
+	newRequest := func(signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/twilio/receipts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(TwilioSignatureHeader, signature)
+		return req
+	}
+
+	valid := twilioSignature("token", "https://api.example.com/api/v1/providers/twilio/receipts", form)
+	assert.NoError(t, verify(newRequest(valid)))
+	assert.ErrorIs(t, verify(newRequest("forged")), ErrInvalidReceiptSignature)
+	assert.ErrorIs(t, verify(newRequest("")), ErrInvalidReceiptSignature)
+}
+
+func TestReceiptHandler_RefusesUnverifiedReceipts(t *testing.T) {
+	h := NewReceiptHandler(nil, nil, nil)
+	r := setupTestRouter()
+	r.POST("/api/v1/providers/:name/receipts", h.Receive)
+
+	body := []byte(`{"message_id":"msg-1","status":"delivered"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/webhook/receipts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
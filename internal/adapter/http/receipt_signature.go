@@ -0,0 +1,77 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mehmetymw/event-driven-ns/pkg/webhooksig"
+)
+
+// TwilioSignatureHeader carries Twilio's signature of a status callback.
+const TwilioSignatureHeader = "X-Twilio-Signature"
+
+var ErrInvalidReceiptSignature = errors.New("invalid receipt signature")
+
+// ReceiptVerifier checks that a receipt request was sent by the provider it
+// claims to come from. It may read the body; the handler binds it afterwards.
+type ReceiptVerifier func(r *http.Request) error
+
+// TwilioReceiptVerifier checks X-Twilio-Signature: the base64 HMAC-SHA1,
+// under the account's auth token, of the URL Twilio called followed by each
+// form parameter's name and value in name order. publicAPIURL is the base the
+// status callback URL was built from, since the request seen here may have
+// come through a proxy.
+func TwilioReceiptVerifier(authToken, publicAPIURL string) ReceiptVerifier {
+	base := strings.TrimSuffix(publicAPIURL, "/")
+	return func(r *http.Request) error {
+		got := r.Header.Get(TwilioSignatureHeader)
+		if got == "" {
+			return ErrInvalidReceiptSignature
+		}
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+
+		want := twilioSignature(authToken, base+r.URL.RequestURI(), r.PostForm)
+		if !hmac.Equal([]byte(got), []byte(want)) {
+			return ErrInvalidReceiptSignature
+		}
+		return nil
+	}
+}
+
+func twilioSignature(authToken, url string, params map[string][]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(url))
+	for _, name := range names {
+		values := append([]string(nil), params[name]...)
+		sort.Strings(values)
+		for _, v := range values {
+			mac.Write([]byte(name))
+			mac.Write([]byte(v))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignedReceiptVerifier checks receipts signed the way this service signs its
+// own webhooks; see pkg/webhooksig.
+func SignedReceiptVerifier(secrets []string) ReceiptVerifier {
+	return func(r *http.Request) error {
+		if _, err := webhooksig.VerifyRequest(r, secrets, 0); err != nil {
+			return errors.Join(ErrInvalidReceiptSignature, err)
+		}
+		return nil
+	}
+}
@@ -14,8 +14,15 @@ import (
 type RouterDeps struct {
 	NotificationHandler *NotificationHandler
 	TemplateHandler     *TemplateHandler
+	ContactHandler      *ContactHandler
+	SuppressionHandler  *SuppressionHandler
 	HealthHandler       *HealthHandler
 	MetricsHandler      *MetricsHandler
+	SchedulerHandler    *SchedulerHandler
+	DeadLetterHandler   *DeadLetterHandler
+	PushTokenHandler    *PushTokenHandler
+	ReceiptHandler      *ReceiptHandler
+	BreakerHandler      *CircuitBreakerHandler
 	WebSocketHandler    *WebSocketHandler
 	Logger              *zap.Logger
 }
@@ -48,8 +55,10 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 		{
 			notifications.POST("", deps.NotificationHandler.Create)
 			notifications.POST("/batch", deps.NotificationHandler.CreateBatch)
+			notifications.POST("/group", deps.NotificationHandler.CreateGroup)
 			notifications.GET("", deps.NotificationHandler.List)
 			notifications.GET("/:id", deps.NotificationHandler.GetByID)
+			notifications.GET("/:id/chain", deps.NotificationHandler.GetChain)
 			notifications.PATCH("/:id/cancel", deps.NotificationHandler.Cancel)
 		}
 
@@ -58,6 +67,31 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 			batches.GET("/:id", deps.NotificationHandler.GetBatch)
 		}
 
+		groups := v1.Group("/groups")
+		{
+			groups.GET("/:id", deps.NotificationHandler.GetGroup)
+			groups.PATCH("/:id/cancel", deps.NotificationHandler.CancelGroup)
+		}
+
+		users := v1.Group("/users")
+		{
+			users.POST("", deps.ContactHandler.CreateUser)
+			users.GET("", deps.ContactHandler.ListUsers)
+			users.GET("/:id", deps.ContactHandler.GetUser)
+			users.PUT("/:id", deps.ContactHandler.UpdateUser)
+			users.DELETE("/:id", deps.ContactHandler.DeleteUser)
+			users.POST("/:id/addresses", deps.ContactHandler.AddAddress)
+			users.PATCH("/:id/addresses/:address_id", deps.ContactHandler.UpdateAddress)
+			users.DELETE("/:id/addresses/:address_id", deps.ContactHandler.DeleteAddress)
+		}
+
+		suppressions := v1.Group("/suppressions")
+		{
+			suppressions.POST("", deps.SuppressionHandler.Add)
+			suppressions.GET("", deps.SuppressionHandler.List)
+			suppressions.DELETE("/:channel/:recipient", deps.SuppressionHandler.Remove)
+		}
+
 		templates := v1.Group("/templates")
 		{
 			templates.POST("", deps.TemplateHandler.Create)
@@ -65,7 +99,26 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 			templates.GET("/:id", deps.TemplateHandler.GetByID)
 		}
 
+		dlq := v1.Group("/dlq")
+		{
+			dlq.GET("", deps.DeadLetterHandler.List)
+			dlq.GET("/:id", deps.DeadLetterHandler.GetByID)
+			dlq.POST("/:id/redrive", deps.DeadLetterHandler.Redrive)
+			dlq.POST("/redrive", deps.DeadLetterHandler.RedriveMatching)
+		}
+
+		breakers := v1.Group("/breakers")
+		{
+			breakers.GET("", deps.BreakerHandler.List)
+			breakers.POST("/:channel/:provider/open", deps.BreakerHandler.ForceOpen)
+			breakers.POST("/:channel/:provider/reset", deps.BreakerHandler.Reset)
+		}
+
+		v1.GET("/push/invalid-tokens", deps.PushTokenHandler.ListInvalid)
+		v1.POST("/providers/:name/receipts", deps.ReceiptHandler.Receive)
+
 		v1.GET("/metrics", deps.MetricsHandler.GetMetrics)
+		v1.GET("/scheduler/lease", deps.SchedulerHandler.GetLease)
 	}
 
 	return r
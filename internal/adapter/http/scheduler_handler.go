@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type SchedulerHandler struct {
+	service *app.LeaseService
+}
+
+func NewSchedulerHandler(service *app.LeaseService) *SchedulerHandler {
+	return &SchedulerHandler{service: service}
+}
+
+type LeaseResponse struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	Active     bool      `json:"active"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func NewLeaseResponse(l *domain.Lease) LeaseResponse {
+	return LeaseResponse{
+		Name:       l.Name,
+		Holder:     l.Holder,
+		Active:     l.Active(),
+		AcquiredAt: l.AcquiredAt,
+		RenewedAt:  l.RenewedAt,
+		ExpiresAt:  l.ExpiresAt,
+	}
+}
+
+func (h *SchedulerHandler) GetLease(c *gin.Context) {
+	lease, err := h.service.Get(c.Request.Context(), app.SchedulerLeaseName)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewLeaseResponse(lease))
+}
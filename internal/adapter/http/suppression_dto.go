@@ -0,0 +1,92 @@
+package http
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type AddSuppressionRequest struct {
+	Channel   string     `json:"channel" binding:"required,oneof=sms email push"`
+	Recipient string     `json:"recipient" binding:"required"`
+	Reason    string     `json:"reason" binding:"required,max=500"`
+	Source    string     `json:"source,omitempty" binding:"max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (r *AddSuppressionRequest) ToInput() app.AddSuppressionInput {
+	return app.AddSuppressionInput{
+		Channel:   domain.Channel(r.Channel),
+		Recipient: r.Recipient,
+		Reason:    r.Reason,
+		Source:    r.Source,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+// ListSuppressionsRequest pages through the list. The cursor is the
+// next_cursor of the previous page, "channel:recipient".
+type ListSuppressionsRequest struct {
+	Channel        *string `form:"channel" binding:"omitempty,oneof=sms email push"`
+	IncludeExpired bool    `form:"include_expired"`
+	Cursor         *string `form:"cursor"`
+	PageSize       int     `form:"page_size"`
+}
+
+func (r *ListSuppressionsRequest) ToFilter() domain.SuppressionFilter {
+	filter := domain.SuppressionFilter{
+		IncludeExpired: r.IncludeExpired,
+		PageSize:       r.PageSize,
+	}
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	if r.Channel != nil {
+		c := domain.Channel(*r.Channel)
+		filter.Channel = &c
+	}
+	if r.Cursor != nil {
+		if channel, recipient, ok := strings.Cut(*r.Cursor, ":"); ok {
+			filter.Cursor = &domain.SuppressionCursor{Channel: domain.Channel(channel), Recipient: recipient}
+		}
+	}
+	return filter
+}
+
+type SuppressionResponse struct {
+	Channel   string     `json:"channel"`
+	Recipient string     `json:"recipient"`
+	Reason    string     `json:"reason"`
+	Source    string     `json:"source"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewSuppressionResponse(s *domain.Suppression) SuppressionResponse {
+	return SuppressionResponse{
+		Channel:   string(s.Channel),
+		Recipient: s.Recipient,
+		Reason:    s.Reason,
+		Source:    s.Source,
+		ExpiresAt: s.ExpiresAt,
+		Active:    s.Active(time.Now()),
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func NewSuppressionListResponse(entries []*domain.Suppression, pageSize int) ListResponse[SuppressionResponse] {
+	resp := ListResponse[SuppressionResponse]{Data: make([]SuppressionResponse, len(entries))}
+	for i, s := range entries {
+		resp.Data[i] = NewSuppressionResponse(s)
+	}
+	if len(entries) == pageSize {
+		last := entries[len(entries)-1]
+		cursor := string(last.Channel) + ":" + last.Recipient
+		resp.NextCursor = &cursor
+	}
+	return resp
+}
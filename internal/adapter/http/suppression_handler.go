@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mehmetymw/event-driven-ns/internal/app"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type SuppressionHandler struct {
+	service *app.SuppressionService
+}
+
+func NewSuppressionHandler(service *app.SuppressionService) *SuppressionHandler {
+	return &SuppressionHandler{service: service}
+}
+
+func (h *SuppressionHandler) Add(c *gin.Context) {
+	var req AddSuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entry, err := h.service.Add(c.Request.Context(), req.ToInput())
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuppressionResponse(entry))
+}
+
+func (h *SuppressionHandler) List(c *gin.Context) {
+	var req ListSuppressionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := req.ToFilter()
+	entries, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuppressionListResponse(entries, filter.PageSize))
+}
+
+func (h *SuppressionHandler) Remove(c *gin.Context) {
+	channel, recipient := domain.Channel(c.Param("channel")), c.Param("recipient")
+	if err := h.service.Remove(c.Request.Context(), channel, recipient); err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
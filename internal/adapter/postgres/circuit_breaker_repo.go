@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type CircuitBreakerRepo struct {
+	db *sqlx.DB
+}
+
+func NewCircuitBreakerRepo(db *sqlx.DB) *CircuitBreakerRepo {
+	return &CircuitBreakerRepo{db: db}
+}
+
+type circuitBreakerRow struct {
+	InstanceID  string          `db:"instance_id"`
+	Channel     string          `db:"channel"`
+	Provider    string          `db:"provider"`
+	State       string          `db:"state"`
+	ForcedOpen  bool            `db:"forced_open"`
+	OpenUntil   *time.Time      `db:"open_until"`
+	Transitions json.RawMessage `db:"transitions"`
+	ChangedAt   *time.Time      `db:"changed_at"`
+	UpdatedAt   time.Time       `db:"updated_at"`
+}
+
+type breakerControlRow struct {
+	Channel    string     `db:"channel"`
+	Provider   string     `db:"provider"`
+	ForcedOpen bool       `db:"forced_open"`
+	OpenUntil  *time.Time `db:"open_until"`
+	ResetAt    *time.Time `db:"reset_at"`
+	UpdatedBy  string     `db:"updated_by"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+// SaveStates upserts one worker's breakers and drops rows no worker has
+// refreshed in a day, so instances that went away don't pile up.
+func (r *CircuitBreakerRepo) SaveStates(ctx context.Context, breakers []domain.CircuitBreaker) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, b := range breakers {
+		transitions, _ := json.Marshal(b.Transitions)
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO circuit_breakers (instance_id, channel, provider, state, forced_open, open_until, transitions, changed_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			ON CONFLICT (instance_id, channel, provider) DO UPDATE
+			SET state = EXCLUDED.state, forced_open = EXCLUDED.forced_open, open_until = EXCLUDED.open_until,
+				transitions = EXCLUDED.transitions, changed_at = EXCLUDED.changed_at, updated_at = EXCLUDED.updated_at`,
+			b.InstanceID, b.Channel, b.Provider, b.State, b.ForcedOpen, b.OpenUntil, transitions, b.ChangedAt, b.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM circuit_breakers WHERE updated_at < NOW() - INTERVAL '1 day'`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CircuitBreakerRepo) ListStatesSince(ctx context.Context, since time.Time) ([]domain.CircuitBreaker, error) {
+	var rows []circuitBreakerRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM circuit_breakers WHERE updated_at >= $1 ORDER BY channel, provider, instance_id`, since)
+	if err != nil {
+		return nil, err
+	}
+
+	breakers := make([]domain.CircuitBreaker, len(rows))
+	for i, row := range rows {
+		breakers[i] = domain.CircuitBreaker{
+			InstanceID: row.InstanceID,
+			Channel:    domain.Channel(row.Channel),
+			Provider:   row.Provider,
+			State:      domain.BreakerState(row.State),
+			ForcedOpen: row.ForcedOpen,
+			OpenUntil:  row.OpenUntil,
+			ChangedAt:  row.ChangedAt,
+			UpdatedAt:  row.UpdatedAt,
+		}
+		_ = json.Unmarshal(row.Transitions, &breakers[i].Transitions)
+	}
+	return breakers, nil
+}
+
+func (r *CircuitBreakerRepo) ListControls(ctx context.Context) ([]domain.BreakerControl, error) {
+	var rows []breakerControlRow
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM circuit_breaker_controls ORDER BY channel, provider`); err != nil {
+		return nil, err
+	}
+
+	controls := make([]domain.BreakerControl, len(rows))
+	for i, row := range rows {
+		controls[i] = domain.BreakerControl{
+			Channel:    domain.Channel(row.Channel),
+			Provider:   row.Provider,
+			ForcedOpen: row.ForcedOpen,
+			OpenUntil:  row.OpenUntil,
+			ResetAt:    row.ResetAt,
+			UpdatedBy:  row.UpdatedBy,
+			UpdatedAt:  row.UpdatedAt,
+		}
+	}
+	return controls, nil
+}
+
+func (r *CircuitBreakerRepo) ForceOpen(ctx context.Context, channel domain.Channel, provider, by string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO circuit_breaker_controls (channel, provider, forced_open, updated_by, updated_at)
+		VALUES ($1,$2,TRUE,$3,NOW())
+		ON CONFLICT (channel, provider) DO UPDATE
+		SET forced_open = TRUE, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		channel, provider, by,
+	)
+	return err
+}
+
+func (r *CircuitBreakerRepo) HoldOpen(ctx context.Context, channel domain.Channel, provider string, until time.Time, by string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO circuit_breaker_controls (channel, provider, open_until, updated_by, updated_at)
+		VALUES ($1,$2,$3,$4,NOW())
+		ON CONFLICT (channel, provider) DO UPDATE
+		SET open_until = EXCLUDED.open_until, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		WHERE circuit_breaker_controls.open_until IS NULL OR circuit_breaker_controls.open_until < EXCLUDED.open_until`,
+		channel, provider, until, by,
+	)
+	return err
+}
+
+func (r *CircuitBreakerRepo) Reset(ctx context.Context, channel domain.Channel, provider, by string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO circuit_breaker_controls (channel, provider, reset_at, updated_by, updated_at)
+		VALUES ($1,$2,NOW(),$3,NOW())
+		ON CONFLICT (channel, provider) DO UPDATE
+		SET forced_open = FALSE, open_until = NULL, reset_at = NOW(), updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		channel, provider, by,
+	)
+	return err
+}
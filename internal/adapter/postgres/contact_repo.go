@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type ContactRepo struct {
+	db *sqlx.DB
+}
+
+func NewContactRepo(db *sqlx.DB) *ContactRepo {
+	return &ContactRepo{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (r *ContactRepo) CreateUser(ctx context.Context, u *domain.User) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO users (id, locale, time_zone, created_at, updated_at) VALUES ($1,$2,$3,$4,$5)`,
+		u.ID, u.Locale, u.TimeZone, u.CreatedAt, u.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateUser
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, a := range u.Addresses {
+		if err := insertAddress(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *ContactRepo) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	var u domain.User
+	err := r.db.GetContext(ctx, &u,
+		`SELECT id, locale, time_zone, created_at, updated_at FROM users WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.SelectContext(ctx, &u.Addresses,
+		`SELECT * FROM user_addresses WHERE user_id = $1 ORDER BY created_at, id`, id); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ListUsers pages through users by ID, without their addresses.
+func (r *ContactRepo) ListUsers(ctx context.Context, cursor *string, limit int) ([]*domain.User, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := `SELECT id, locale, time_zone, created_at, updated_at FROM users`
+	args := []any{}
+	if cursor != nil {
+		query += ` WHERE id > $1`
+		args = append(args, *cursor)
+	}
+	query += ` ORDER BY id LIMIT ` + itoa(limit)
+
+	var users []*domain.User
+	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *ContactRepo) UpdateUser(ctx context.Context, u *domain.User) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET locale = $1, time_zone = $2, updated_at = $3 WHERE id = $4`,
+		u.Locale, u.TimeZone, u.UpdatedAt, u.ID,
+	)
+	return affectedOne(result, err, domain.ErrUserNotFound)
+}
+
+func (r *ContactRepo) DeleteUser(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	return affectedOne(result, err, domain.ErrUserNotFound)
+}
+
+func (r *ContactRepo) AddAddress(ctx context.Context, a *domain.ContactAddress) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertAddress(ctx, tx, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertAddress(ctx context.Context, tx *sqlx.Tx, a *domain.ContactAddress) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO user_addresses (id, user_id, channel, address, verified, active, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		a.ID, a.UserID, a.Channel, a.Address, a.Verified, a.Active, a.CreatedAt, a.UpdatedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return domain.ErrUserNotFound
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAddress
+	}
+	return err
+}
+
+func (r *ContactRepo) UpdateAddress(ctx context.Context, a *domain.ContactAddress) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE user_addresses SET verified = $1, active = $2, updated_at = $3
+		WHERE id = $4 AND user_id = $5`,
+		a.Verified, a.Active, a.UpdatedAt, a.ID, a.UserID,
+	)
+	return affectedOne(result, err, domain.ErrAddressNotFound)
+}
+
+func (r *ContactRepo) DeleteAddress(ctx context.Context, userID string, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM user_addresses WHERE id = $1 AND user_id = $2`, id, userID)
+	return affectedOne(result, err, domain.ErrAddressNotFound)
+}
+
+func (r *ContactRepo) ActiveAddresses(ctx context.Context, userID string, channel domain.Channel) ([]*domain.ContactAddress, error) {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+
+	var addresses []*domain.ContactAddress
+	err := r.db.SelectContext(ctx, &addresses,
+		`SELECT a.* FROM user_addresses a
+		WHERE a.user_id = $1 AND a.channel = $2 AND a.active
+		  AND NOT EXISTS (SELECT 1 FROM invalid_push_tokens t WHERE a.channel = 'push' AND t.token = a.address)
+		ORDER BY a.created_at, a.id`, userID, channel)
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// affectedOne maps an update or delete that matched no row to notFound.
+func affectedOne(result sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
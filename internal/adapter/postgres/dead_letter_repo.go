@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type DeadLetterRepo struct {
+	db *sqlx.DB
+}
+
+func NewDeadLetterRepo(db *sqlx.DB) *DeadLetterRepo {
+	return &DeadLetterRepo{db: db}
+}
+
+type deadLetterRow struct {
+	ID             uuid.UUID       `db:"id"`
+	NotificationID *uuid.UUID      `db:"notification_id"`
+	Topic          string          `db:"topic"`
+	Partition      int             `db:"kafka_partition"`
+	Offset         int64           `db:"kafka_offset"`
+	Payload        []byte          `db:"payload"`
+	Error          string          `db:"error"`
+	Attempts       json.RawMessage `db:"attempts"`
+	CreatedAt      time.Time       `db:"created_at"`
+	RedrivenAt     *time.Time      `db:"redriven_at"`
+}
+
+// Save is idempotent on the entry ID, so a dead letter redelivered from Kafka
+// is stored once.
+func (r *DeadLetterRepo) Save(ctx context.Context, d *domain.DeadLetter) error {
+	attempts, err := json.Marshal(d.Attempts)
+	if err != nil {
+		return err
+	}
+	if d.Attempts == nil {
+		attempts = []byte("[]")
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO dead_letters
+		(id, notification_id, topic, kafka_partition, kafka_offset, payload, error, attempts, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (id) DO NOTHING`,
+		d.ID, d.NotificationID, d.Topic, d.Partition, d.Offset, d.Payload, d.Error, attempts, d.CreatedAt,
+	)
+	return err
+}
+
+func (r *DeadLetterRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.DeadLetter, error) {
+	var row deadLetterRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM dead_letters WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rowToDeadLetter(row), nil
+}
+
+func (r *DeadLetterRepo) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	query := `SELECT * FROM dead_letters WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if filter.Topic != nil {
+		query += ` AND topic = $` + itoa(argIdx)
+		args = append(args, *filter.Topic)
+		argIdx++
+	}
+	if filter.NotificationID != nil {
+		query += ` AND notification_id = $` + itoa(argIdx)
+		args = append(args, *filter.NotificationID)
+		argIdx++
+	}
+	if filter.Redriven != nil {
+		if *filter.Redriven {
+			query += ` AND redriven_at IS NOT NULL`
+		} else {
+			query += ` AND redriven_at IS NULL`
+		}
+	}
+	if filter.DateFrom != nil {
+		query += ` AND created_at >= $` + itoa(argIdx)
+		args = append(args, *filter.DateFrom)
+		argIdx++
+	}
+	if filter.DateTo != nil {
+		query += ` AND created_at <= $` + itoa(argIdx)
+		args = append(args, *filter.DateTo)
+		argIdx++
+	}
+	if filter.Cursor != nil {
+		query += ` AND (created_at, id) < ($` + itoa(argIdx) + `, $` + itoa(argIdx+1) + `)`
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		argIdx += 2
+	}
+
+	pageSize := filter.PageSize
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $` + itoa(argIdx)
+	args = append(args, pageSize)
+
+	var rows []deadLetterRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.DeadLetter, len(rows))
+	for i, row := range rows {
+		result[i] = rowToDeadLetter(row)
+	}
+	return result, nil
+}
+
+// Redrive puts the entry's notification back to pending and writes an outbox
+// row in the same transaction, so the outbox relay republishes it to its
+// priority topic. The entry is marked redriven so it is only redriven once.
+func (r *DeadLetterRepo) Redrive(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row deadLetterRow
+	err = tx.GetContext(ctx, &row, `SELECT * FROM dead_letters WHERE id = $1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !rowToDeadLetter(row).CanRedrive() {
+		return domain.ErrDeadLetterNotRedrivable
+	}
+
+	var requeued struct {
+		BatchID   *uuid.UUID `db:"batch_id"`
+		WasFailed bool       `db:"was_failed"`
+	}
+	err = tx.GetContext(ctx, &requeued,
+		`UPDATE notifications n SET status = 'pending', retry_count = 0, error_message = NULL,
+			failed_at = NULL, updated_at = NOW()
+		FROM (SELECT id, status FROM notifications WHERE id = $1 FOR UPDATE) old
+		WHERE n.id = old.id AND old.status IN ('failed', 'pending')
+		RETURNING n.batch_id, old.status = 'failed' AS was_failed`,
+		*row.NotificationID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrDeadLetterNotRedrivable
+	}
+	if err != nil {
+		return err
+	}
+
+	if requeued.BatchID != nil && requeued.WasFailed {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE notification_batches SET failed_count = failed_count - 1, pending_count = pending_count + 1
+			WHERE id = $1`, *requeued.BatchID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertOutbox(ctx, tx, *row.NotificationID, traceCarrier(ctx)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE dead_letters SET redriven_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func rowToDeadLetter(row deadLetterRow) *domain.DeadLetter {
+	d := &domain.DeadLetter{
+		ID:             row.ID,
+		NotificationID: row.NotificationID,
+		Topic:          row.Topic,
+		Partition:      row.Partition,
+		Offset:         row.Offset,
+		Payload:        row.Payload,
+		Error:          row.Error,
+		CreatedAt:      row.CreatedAt,
+		RedrivenAt:     row.RedrivenAt,
+	}
+	if row.Attempts != nil {
+		_ = json.Unmarshal(row.Attempts, &d.Attempts)
+	}
+	return d
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type LeaseRepo struct {
+	db *sqlx.DB
+}
+
+func NewLeaseRepo(db *sqlx.DB) *LeaseRepo {
+	return &LeaseRepo{db: db}
+}
+
+// TryAcquire takes the lease when it is free or expired and renews it when the
+// caller already holds it. It reports whether the caller is the holder.
+func (r *LeaseRepo) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW(), NOW() + $3::interval)
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN leases.holder = EXCLUDED.holder THEN leases.acquired_at ELSE NOW() END,
+			renewed_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE leases.holder = EXCLUDED.holder OR leases.expires_at < NOW()`,
+		name, holder, ttl.String(),
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *LeaseRepo) Release(ctx context.Context, name, holder string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE leases SET expires_at = NOW() WHERE name = $1 AND holder = $2`, name, holder)
+	return err
+}
+
+func (r *LeaseRepo) Get(ctx context.Context, name string) (*domain.Lease, error) {
+	var lease domain.Lease
+	err := r.db.GetContext(ctx, &lease,
+		`SELECT name, holder, acquired_at, renewed_at, expires_at FROM leases WHERE name = $1`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrLeaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
@@ -35,6 +35,7 @@ type notificationRow struct {
 	ID                uuid.UUID       `db:"id"`
 	BatchID           *uuid.UUID      `db:"batch_id"`
 	IdempotencyKey    *string         `db:"idempotency_key"`
+	OrderingKey       *string         `db:"ordering_key"`
 	Channel           string          `db:"channel"`
 	Recipient         string          `db:"recipient"`
 	Content           string          `db:"content"`
@@ -47,24 +48,34 @@ type notificationRow struct {
 	RetryCount        int             `db:"retry_count"`
 	MaxRetries        int             `db:"max_retries"`
 	ProviderMessageID *string         `db:"provider_message_id"`
+	Provider          *string         `db:"provider"`
 	TemplateID        *uuid.UUID      `db:"template_id"`
 	TemplateVariables json.RawMessage `db:"template_variables"`
+	GroupID           *uuid.UUID      `db:"group_id"`
+	UserID            *string         `db:"user_id"`
+	ParentID          *uuid.UUID      `db:"parent_id"`
+	Fallbacks         json.RawMessage `db:"fallbacks"`
+	FallbackAt        *time.Time      `db:"fallback_at"`
+	NextAttemptID     *uuid.UUID      `db:"next_attempt_id"`
 	CreatedAt         time.Time       `db:"created_at"`
 	UpdatedAt         time.Time       `db:"updated_at"`
 }
 
 func (r *NotificationRepo) Create(ctx context.Context, n *domain.Notification) error {
-	vars, _ := json.Marshal(n.TemplateVariables)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO notifications 
-		(id, batch_id, idempotency_key, channel, recipient, content, priority, status, 
-		 scheduled_at, max_retries, template_id, template_variables, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
-		n.ID, n.BatchID, n.IdempotencyKey, n.Channel, n.Recipient, n.Content, n.Priority,
-		n.Status, n.ScheduledAt, n.MaxRetries, n.TemplateID, vars, n.CreatedAt, n.UpdatedAt,
-	)
-	return wrapIDempotencyError(err)
+	if err := insertNotification(ctx, tx, n); err != nil {
+		return err
+	}
+	if err := queueNotification(ctx, tx, n, traceCarrier(ctx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *NotificationRepo) CreateBatch(ctx context.Context, batch *domain.NotificationBatch, notifications []*domain.Notification) error {
@@ -75,32 +86,251 @@ func (r *NotificationRepo) CreateBatch(ctx context.Context, batch *domain.Notifi
 	defer func() { _ = tx.Rollback() }()
 
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO notification_batches (id, total_count, pending_count, created_at)
-		VALUES ($1, $2, $3, $4)`,
-		batch.ID, batch.TotalCount, batch.PendingCount, batch.CreatedAt,
+		`INSERT INTO notification_batches (id, total_count, pending_count, suppressed_count, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		batch.ID, batch.TotalCount, batch.PendingCount, batch.SuppressedCount, batch.CreatedAt,
 	)
 	if err != nil {
 		return err
 	}
 
+	carrier := traceCarrier(ctx)
 	for _, n := range notifications {
-		vars, _ := json.Marshal(n.TemplateVariables)
-		_, err = tx.ExecContext(ctx,
-			`INSERT INTO notifications 
-			(id, batch_id, idempotency_key, channel, recipient, content, priority, status,
-			 scheduled_at, max_retries, template_id, template_variables, created_at, updated_at)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
-			n.ID, n.BatchID, n.IdempotencyKey, n.Channel, n.Recipient, n.Content, n.Priority,
-			n.Status, n.ScheduledAt, n.MaxRetries, n.TemplateID, vars, n.CreatedAt, n.UpdatedAt,
-		)
-		if err != nil {
-			return wrapIDempotencyError(err)
+		if err := insertNotification(ctx, tx, n); err != nil {
+			return err
+		}
+		if err := queueNotification(ctx, tx, n, carrier); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *NotificationRepo) CreateGroup(ctx context.Context, group *domain.NotificationGroup) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO notification_groups (id, idempotency_key, created_at) VALUES ($1, $2, $3)`,
+		group.ID, group.IdempotencyKey, group.CreatedAt,
+	)
+	if err != nil {
+		return wrapIDempotencyError(err)
+	}
+
+	carrier := traceCarrier(ctx)
+	for _, n := range group.Notifications {
+		if err := insertNotification(ctx, tx, n); err != nil {
+			return err
+		}
+		if err := queueNotification(ctx, tx, n, carrier); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+type groupRow struct {
+	ID             uuid.UUID `db:"id"`
+	IdempotencyKey *string   `db:"idempotency_key"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func (r *NotificationRepo) GetGroup(ctx context.Context, groupID uuid.UUID) (*domain.NotificationGroup, error) {
+	return r.getGroup(ctx, `SELECT id, idempotency_key, created_at FROM notification_groups WHERE id = $1`, groupID)
+}
+
+func (r *NotificationRepo) GetGroupByIdempotencyKey(ctx context.Context, key string) (*domain.NotificationGroup, error) {
+	return r.getGroup(ctx, `SELECT id, idempotency_key, created_at FROM notification_groups WHERE idempotency_key = $1`, key)
+}
+
+func (r *NotificationRepo) getGroup(ctx context.Context, query string, arg any) (*domain.NotificationGroup, error) {
+	var row groupRow
+	err := r.db.GetContext(ctx, &row, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []notificationRow
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM notifications WHERE group_id = $1 ORDER BY created_at, id`, row.ID); err != nil {
+		return nil, err
+	}
+
+	group := &domain.NotificationGroup{
+		ID:             row.ID,
+		IdempotencyKey: row.IdempotencyKey,
+		CreatedAt:      row.CreatedAt,
+		Notifications:  make([]*domain.Notification, len(rows)),
+	}
+	for i, n := range rows {
+		group.Notifications[i] = rowToNotification(n)
+	}
+	return group, nil
+}
+
+// CancelGroup cancels the group's pending and scheduled siblings and returns
+// their IDs, moving each one's batch counter from pending to cancelled in the
+// same transaction. Siblings already on their way are left alone.
+func (r *NotificationRepo) CancelGroup(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var rows []struct {
+		ID      uuid.UUID  `db:"id"`
+		BatchID *uuid.UUID `db:"batch_id"`
+	}
+	err = tx.SelectContext(ctx, &rows,
+		`UPDATE notifications SET status='cancelled', updated_at=NOW()
+		WHERE group_id=$1 AND status IN ('pending','scheduled')
+		RETURNING id, batch_id`, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		// Nothing was cancelled: tell an unknown group from one whose
+		// siblings are all on their way.
+		var exists bool
+		if err := tx.GetContext(ctx, &exists,
+			`SELECT EXISTS (SELECT 1 FROM notification_groups WHERE id = $1)`, groupID); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrGroupNotFound
+		}
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		if row.BatchID != nil {
+			if err := transferBatchCounter(ctx, tx, *row.BatchID, domain.StatusPending, domain.StatusCancelled); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	return ids, tx.Commit()
+}
+
+func insertNotification(ctx context.Context, tx *sqlx.Tx, n *domain.Notification) error {
+	vars, _ := json.Marshal(n.TemplateVariables)
+	// Fallbacks stay NULL when there are none, which keeps them out of the
+	// index the fallback sweep reads.
+	var fallbacks []byte
+	if len(n.Fallbacks) > 0 {
+		fallbacks, _ = json.Marshal(n.Fallbacks)
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO notifications 
+		(id, batch_id, idempotency_key, ordering_key, channel, recipient, content, priority, status,
+		 scheduled_at, max_retries, template_id, template_variables, parent_id, fallbacks, fallback_at,
+		 group_id, user_id, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)`,
+		n.ID, n.BatchID, n.IdempotencyKey, n.OrderingKey, n.Channel, n.Recipient, n.Content, n.Priority,
+		n.Status, n.ScheduledAt, n.MaxRetries, n.TemplateID, vars, n.ParentID, fallbacks, n.FallbackAt,
+		n.GroupID, n.UserID, n.CreatedAt, n.UpdatedAt,
+	)
+	return wrapIDempotencyError(err)
+}
+
+// queueNotification writes the outbox row for a notification being created.
+// One suppressed at creation is stored but never queued.
+func queueNotification(ctx context.Context, tx *sqlx.Tx, n *domain.Notification, carrier map[string]string) error {
+	if n.Status == domain.StatusSuppressed {
+		return nil
+	}
+	return insertOutbox(ctx, tx, n.ID, carrier)
+}
+
+// CreateFallback saves next as the attempt after from and queues it through
+// the outbox. It returns domain.ErrClaimConflict if from already has a next
+// attempt, so a chain never forks.
+func (r *NotificationRepo) CreateFallback(ctx context.Context, from, next *domain.Notification) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE notifications SET next_attempt_id = $1, updated_at = NOW()
+		WHERE id = $2 AND next_attempt_id IS NULL`, next.ID, from.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrClaimConflict
+	}
+
+	if err := insertNotification(ctx, tx, next); err != nil {
+		return err
+	}
+	if err := insertOutbox(ctx, tx, next.ID, traceCarrier(ctx)); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
+func (r *NotificationRepo) ListChain(ctx context.Context, parentID uuid.UUID) ([]*domain.Notification, error) {
+	var rows []notificationRow
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM notifications WHERE id = $1 OR parent_id = $1 ORDER BY created_at, id`, parentID); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, domain.ErrNotificationNotFound
+	}
+	result := make([]*domain.Notification, len(rows))
+	for i, row := range rows {
+		result[i] = rowToNotification(row)
+	}
+	return result, nil
+}
+
+// ListFallbacksDue returns attempts whose next step should start: failed,
+// undelivered or suppressed ones, and ones past their fallback deadline
+// without being delivered. Pages are in ID order after the given ID.
+func (r *NotificationRepo) ListFallbacksDue(ctx context.Context, now time.Time, after *uuid.UUID, limit int) ([]*domain.Notification, error) {
+	query := `SELECT * FROM notifications
+		WHERE fallbacks IS NOT NULL AND next_attempt_id IS NULL
+		  AND (status IN ('failed','undelivered','suppressed')
+		       OR (fallback_at <= $1 AND status NOT IN ('delivered','read','cancelled')))`
+	args := []any{now}
+
+	if after != nil {
+		query += ` AND id > $2`
+		args = append(args, *after)
+	}
+
+	query += ` ORDER BY id LIMIT $` + itoa(len(args)+1)
+	args = append(args, limit)
+
+	var rows []notificationRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	result := make([]*domain.Notification, len(rows))
+	for i, row := range rows {
+		result[i] = rowToNotification(row)
+	}
+	return result, nil
+}
+
 func (r *NotificationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
 	var row notificationRow
 	err := r.db.GetContext(ctx, &row,
@@ -117,7 +347,8 @@ func (r *NotificationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.N
 func (r *NotificationRepo) GetBatchByID(ctx context.Context, batchID uuid.UUID) (*domain.NotificationBatch, error) {
 	var batch domain.NotificationBatch
 	err := r.db.GetContext(ctx, &batch,
-		`SELECT id, total_count, pending_count, delivered_count, failed_count, cancelled_count, created_at
+		`SELECT id, total_count, pending_count, sent_count, delivered_count, undelivered_count, read_count,
+		        failed_count, cancelled_count, suppressed_count, created_at
 		FROM notification_batches WHERE id = $1`, batchID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, domain.ErrBatchNotFound
@@ -188,10 +419,10 @@ func (r *NotificationRepo) UpdateStatus(ctx context.Context, n *domain.Notificat
 	_, err := r.db.ExecContext(ctx,
 		`UPDATE notifications 
 		SET status=$1, sent_at=$2, failed_at=$3, error_message=$4, retry_count=$5, 
-		    provider_message_id=$6, updated_at=$7
-		WHERE id=$8`,
+		    provider_message_id=$6, provider=$7, updated_at=$8, fallback_at=$9
+		WHERE id=$10`,
 		n.Status, n.SentAt, n.FailedAt, n.ErrorMessage, n.RetryCount,
-		n.ProviderMessageID, n.UpdatedAt, n.ID,
+		n.ProviderMessageID, n.Provider, n.UpdatedAt, n.FallbackAt, n.ID,
 	)
 	return err
 }
@@ -211,30 +442,87 @@ func (r *NotificationRepo) Cancel(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *NotificationRepo) IncrementBatchCounter(ctx context.Context, batchID uuid.UUID, status domain.Status) error {
-	var column string
-	switch status {
-	case domain.StatusDelivered:
-		column = "delivered_count"
-	case domain.StatusFailed:
-		column = "failed_count"
-	case domain.StatusCancelled:
-		column = "cancelled_count"
-	default:
+	return transferBatchCounter(ctx, r.db, batchID, domain.StatusPending, status)
+}
+
+// batchCounterColumns maps a status to the batch counter it is tallied in.
+var batchCounterColumns = map[domain.Status]string{
+	domain.StatusPending:     "pending_count",
+	domain.StatusSent:        "sent_count",
+	domain.StatusDelivered:   "delivered_count",
+	domain.StatusUndelivered: "undelivered_count",
+	domain.StatusRead:        "read_count",
+	domain.StatusFailed:      "failed_count",
+	domain.StatusCancelled:   "cancelled_count",
+	domain.StatusSuppressed:  "suppressed_count",
+}
+
+func transferBatchCounter(ctx context.Context, db sqlx.ExecerContext, batchID uuid.UUID, from, to domain.Status) error {
+	fromColumn, ok := batchCounterColumns[from]
+	if !ok {
+		return nil
+	}
+	toColumn, ok := batchCounterColumns[to]
+	if !ok || fromColumn == toColumn {
 		return nil
 	}
 
-	_, err := r.db.ExecContext(ctx,
+	_, err := db.ExecContext(ctx,
 		`UPDATE notification_batches 
-		SET `+column+` = `+column+` + 1, pending_count = pending_count - 1
+		SET `+toColumn+` = `+toColumn+` + 1, `+fromColumn+` = `+fromColumn+` - 1
 		WHERE id = $1`, batchID)
 	return err
 }
 
+func (r *NotificationRepo) GetByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*domain.Notification, error) {
+	var row notificationRow
+	err := r.db.GetContext(ctx, &row,
+		`SELECT * FROM notifications WHERE provider_message_id = $1 AND provider = $2`,
+		providerMessageID, provider)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rowToNotification(row), nil
+}
+
+func (r *NotificationRepo) TransitionStatus(ctx context.Context, n *domain.Notification, from domain.Status) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE notifications 
+		SET status=$1, failed_at=$2, error_message=$3, updated_at=$4
+		WHERE id=$5 AND status=$6`,
+		n.Status, n.FailedAt, n.ErrorMessage, n.UpdatedAt, n.ID, from,
+	)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrClaimConflict
+	}
+
+	if n.BatchID != nil {
+		if err := transferBatchCounter(ctx, tx, *n.BatchID, from, n.Status); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func rowToNotification(row notificationRow) *domain.Notification {
 	n := &domain.Notification{
 		ID:                row.ID,
 		BatchID:           row.BatchID,
 		IdempotencyKey:    row.IdempotencyKey,
+		OrderingKey:       row.OrderingKey,
 		Channel:           domain.Channel(row.Channel),
 		Recipient:         row.Recipient,
 		Content:           row.Content,
@@ -247,7 +535,13 @@ func rowToNotification(row notificationRow) *domain.Notification {
 		RetryCount:        row.RetryCount,
 		MaxRetries:        row.MaxRetries,
 		ProviderMessageID: row.ProviderMessageID,
+		Provider:          row.Provider,
 		TemplateID:        row.TemplateID,
+		GroupID:           row.GroupID,
+		UserID:            row.UserID,
+		ParentID:          row.ParentID,
+		FallbackAt:        row.FallbackAt,
+		NextAttemptID:     row.NextAttemptID,
 		CreatedAt:         row.CreatedAt,
 		UpdatedAt:         row.UpdatedAt,
 	}
@@ -255,17 +549,27 @@ func rowToNotification(row notificationRow) *domain.Notification {
 	if row.TemplateVariables != nil {
 		_ = json.Unmarshal(row.TemplateVariables, &n.TemplateVariables)
 	}
+	if row.Fallbacks != nil {
+		_ = json.Unmarshal(row.Fallbacks, &n.Fallbacks)
+	}
 
 	return n
 }
 
-func (r *NotificationRepo) ListDueScheduled(ctx context.Context, limit int) ([]*domain.Notification, error) {
+func (r *NotificationRepo) ListDueScheduled(ctx context.Context, until time.Time, after *domain.ScheduleCursor, limit int) ([]*domain.Notification, error) {
+	query := `SELECT * FROM notifications WHERE status = 'scheduled' AND scheduled_at <= $1`
+	args := []any{until}
+
+	if after != nil {
+		query += ` AND (scheduled_at, id) > ($2, $3)`
+		args = append(args, after.ScheduledAt, after.ID)
+	}
+
+	query += ` ORDER BY scheduled_at, id LIMIT $` + itoa(len(args)+1)
+	args = append(args, limit)
+
 	var rows []notificationRow
-	err := r.db.SelectContext(ctx, &rows,
-		`SELECT * FROM notifications WHERE status = 'scheduled' AND scheduled_at <= NOW() ORDER BY scheduled_at LIMIT $1`,
-		limit,
-	)
-	if err != nil {
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
 		return nil, err
 	}
 	result := make([]*domain.Notification, len(rows))
@@ -275,19 +579,97 @@ func (r *NotificationRepo) ListDueScheduled(ctx context.Context, limit int) ([]*
 	return result, nil
 }
 
-func (r *NotificationRepo) ListStuckProcessing(ctx context.Context, olderThan time.Duration, limit int) ([]*domain.Notification, error) {
+// ClaimScheduled moves a due notification to pending and writes its outbox
+// row in the same transaction, so the relay queues it even if this worker
+// dies right after the claim.
+func (r *NotificationRepo) ClaimScheduled(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row notificationRow
+	err = tx.GetContext(ctx, &row,
+		`UPDATE notifications SET status = 'pending', updated_at = NOW()
+		WHERE id = $1 AND status = 'scheduled'
+		RETURNING *`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrClaimConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertOutbox(ctx, tx, row.ID, traceCarrier(ctx)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rowToNotification(row), nil
+}
+
+func (r *NotificationRepo) ClaimForDelivery(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	var row notificationRow
+	err := r.db.GetContext(ctx, &row,
+		`UPDATE notifications SET status = 'processing', updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING *`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM notifications WHERE id = $1)`, id); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrNotificationNotFound
+		}
+		return nil, domain.ErrClaimConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rowToNotification(row), nil
+}
+
+// ResetStuckProcessing puts notifications stuck in processing back to
+// pending and writes an outbox row for each in the same transaction, so the
+// relay requeues them even if this worker dies right after.
+func (r *NotificationRepo) ResetStuckProcessing(ctx context.Context, olderThan time.Duration, limit int) ([]*domain.Notification, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	var rows []notificationRow
-	err := r.db.SelectContext(ctx, &rows,
-		`SELECT * FROM notifications WHERE status = 'processing' AND updated_at < NOW() - $1::interval ORDER BY updated_at LIMIT $2`,
+	err = tx.SelectContext(ctx, &rows,
+		`UPDATE notifications SET status = 'pending', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'processing' AND updated_at < NOW() - $1::interval
+			ORDER BY updated_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
 		olderThan.String(), limit,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	carrier := traceCarrier(ctx)
 	result := make([]*domain.Notification, len(rows))
 	for i, row := range rows {
+		if err := insertOutbox(ctx, tx, row.ID, carrier); err != nil {
+			return nil, err
+		}
 		result[i] = rowToNotification(row)
 	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -295,9 +677,9 @@ func (r *NotificationRepo) GetChannelMetrics(ctx context.Context) ([]domain.Chan
 	var stats []domain.ChannelStats
 	err := r.db.SelectContext(ctx, &stats,
 		`SELECT channel,
-			COUNT(*) FILTER (WHERE status = 'delivered') AS sent,
-			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
-			COALESCE(AVG(EXTRACT(EPOCH FROM (sent_at - created_at)) * 1000) FILTER (WHERE status = 'delivered' AND sent_at IS NOT NULL), 0) AS avg_latency_ms
+			COUNT(*) FILTER (WHERE status IN ('sent', 'delivered', 'read')) AS sent,
+			COUNT(*) FILTER (WHERE status IN ('failed', 'undelivered')) AS failed,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (sent_at - created_at)) * 1000) FILTER (WHERE status IN ('sent', 'delivered', 'read') AND sent_at IS NOT NULL), 0) AS avg_latency_ms
 		FROM notifications
 		GROUP BY channel`)
 	if err != nil {
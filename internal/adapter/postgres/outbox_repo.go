@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type OutboxRepo struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepo(db *sqlx.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+type outboxRow struct {
+	ID             int64           `db:"id"`
+	NotificationID uuid.UUID       `db:"notification_id"`
+	TraceCarrier   json.RawMessage `db:"trace_carrier"`
+	Attempts       int             `db:"attempts"`
+	LastError      *string         `db:"last_error"`
+	LockedUntil    *time.Time      `db:"locked_until"`
+	PublishedAt    *time.Time      `db:"published_at"`
+	ParkedAt       *time.Time      `db:"parked_at"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// ClaimPending leases up to limit unpublished rows so that concurrent relays
+// never pick the same row while it is being published.
+func (r *OutboxRepo) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	var rows []outboxRow
+	err := r.db.SelectContext(ctx, &rows,
+		`UPDATE notification_outbox SET locked_until = NOW() + $1::interval
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE published_at IS NULL AND parked_at IS NULL
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		lease.String(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.OutboxMessage, len(rows))
+	for i, row := range rows {
+		result[i] = rowToOutboxMessage(row)
+	}
+	return result, nil
+}
+
+func (r *OutboxRepo) MarkPublished(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notification_outbox SET published_at = NOW(), locked_until = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id int64, errMsg string, retryAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notification_outbox SET attempts = attempts + 1, last_error = $1, locked_until = $2 WHERE id = $3`,
+		errMsg, retryAt, id)
+	return err
+}
+
+func (r *OutboxRepo) Park(ctx context.Context, id int64, errMsg string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notification_outbox
+		SET attempts = attempts + 1, last_error = $1, locked_until = NULL, parked_at = NOW()
+		WHERE id = $2`,
+		errMsg, id)
+	return err
+}
+
+func (r *OutboxRepo) DeletePublished(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM notification_outbox
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE published_at < $1
+			LIMIT $2
+		)`,
+		cutoff, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	return int(deleted), err
+}
+
+func (r *OutboxRepo) Stats(ctx context.Context) (domain.OutboxStats, error) {
+	var stats domain.OutboxStats
+	err := r.db.GetContext(ctx, &stats,
+		`SELECT
+			COUNT(*) FILTER (WHERE parked_at IS NULL) AS pending,
+			COUNT(*) FILTER (WHERE parked_at IS NOT NULL) AS parked
+		FROM notification_outbox
+		WHERE published_at IS NULL`,
+	)
+	return stats, err
+}
+
+func insertOutbox(ctx context.Context, tx *sqlx.Tx, notificationID uuid.UUID, carrier map[string]string) error {
+	var raw []byte
+	if len(carrier) > 0 {
+		raw, _ = json.Marshal(carrier)
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO notification_outbox (notification_id, trace_carrier) VALUES ($1, $2)`,
+		notificationID, raw,
+	)
+	return err
+}
+
+func traceCarrier(ctx context.Context) map[string]string {
+	carrier := make(map[string]string)
+	propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(carrier))
+	return carrier
+}
+
+func rowToOutboxMessage(row outboxRow) *domain.OutboxMessage {
+	m := &domain.OutboxMessage{
+		ID:             row.ID,
+		NotificationID: row.NotificationID,
+		Attempts:       row.Attempts,
+		LastError:      row.LastError,
+		CreatedAt:      row.CreatedAt,
+		PublishedAt:    row.PublishedAt,
+	}
+	if row.TraceCarrier != nil {
+		_ = json.Unmarshal(row.TraceCarrier, &m.Carrier)
+	}
+	return m
+}
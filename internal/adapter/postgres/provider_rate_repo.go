@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type ProviderRateRepo struct {
+	db *sqlx.DB
+}
+
+func NewProviderRateRepo(db *sqlx.DB) *ProviderRateRepo {
+	return &ProviderRateRepo{db: db}
+}
+
+type providerRateRow struct {
+	InstanceID  string     `db:"instance_id"`
+	Channel     string     `db:"channel"`
+	Provider    string     `db:"provider"`
+	Rate        float64    `db:"rate"`
+	Ceiling     float64    `db:"ceiling"`
+	Shared      bool       `db:"shared"`
+	PausedUntil *time.Time `db:"paused_until"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+// Save upserts one worker's rates and drops rows no worker has refreshed in
+// a day, so instances that went away don't pile up.
+func (r *ProviderRateRepo) Save(ctx context.Context, rates []domain.ProviderRate) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO provider_rates (instance_id, channel, provider, rate, ceiling, shared, paused_until, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+			ON CONFLICT (instance_id, channel, provider) DO UPDATE
+			SET rate = EXCLUDED.rate, ceiling = EXCLUDED.ceiling, shared = EXCLUDED.shared,
+				paused_until = EXCLUDED.paused_until, updated_at = EXCLUDED.updated_at`,
+			rate.InstanceID, rate.Channel, rate.Provider, rate.Rate, rate.Ceiling, rate.Shared, rate.PausedUntil, rate.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM provider_rates WHERE updated_at < NOW() - INTERVAL '1 day'`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ProviderRateRepo) ListSince(ctx context.Context, since time.Time) ([]domain.ProviderRate, error) {
+	var rows []providerRateRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM provider_rates WHERE updated_at >= $1 ORDER BY channel, provider, instance_id`, since)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]domain.ProviderRate, len(rows))
+	for i, row := range rows {
+		rates[i] = domain.ProviderRate{
+			InstanceID:  row.InstanceID,
+			Channel:     domain.Channel(row.Channel),
+			Provider:    row.Provider,
+			Rate:        row.Rate,
+			Ceiling:     row.Ceiling,
+			Shared:      row.Shared,
+			PausedUntil: row.PausedUntil,
+			UpdatedAt:   row.UpdatedAt,
+		}
+	}
+	return rates, nil
+}
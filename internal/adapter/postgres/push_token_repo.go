@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type PushTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewPushTokenRepo(db *sqlx.DB) *PushTokenRepo {
+	return &PushTokenRepo{db: db}
+}
+
+type invalidPushTokenRow struct {
+	Token         string    `db:"token"`
+	Provider      string    `db:"provider"`
+	Reason        string    `db:"reason"`
+	InvalidatedAt time.Time `db:"invalidated_at"`
+}
+
+// Invalidate records the token, refreshing the provider and reason when it
+// was already on the list.
+func (r *PushTokenRepo) Invalidate(ctx context.Context, t *domain.InvalidPushToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO invalid_push_tokens (token, provider, reason, invalidated_at)
+		VALUES ($1,$2,$3,$4)
+		ON CONFLICT (token) DO UPDATE
+		SET provider = EXCLUDED.provider, reason = EXCLUDED.reason, invalidated_at = EXCLUDED.invalidated_at`,
+		t.Token, t.Provider, t.Reason, t.InvalidatedAt,
+	)
+	return err
+}
+
+func (r *PushTokenRepo) IsInvalid(ctx context.Context, token string) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM invalid_push_tokens WHERE token = $1)`, token)
+	return exists, err
+}
+
+func (r *PushTokenRepo) ListInvalid(ctx context.Context, since *time.Time, limit int) ([]*domain.InvalidPushToken, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	query := `SELECT * FROM invalid_push_tokens`
+	args := []any{}
+	if since != nil {
+		query += ` WHERE invalidated_at > $1`
+		args = append(args, *since)
+	}
+	query += ` ORDER BY invalidated_at DESC LIMIT ` + itoa(limit)
+
+	var rows []invalidPushTokenRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.InvalidPushToken, len(rows))
+	for i, row := range rows {
+		tokens[i] = &domain.InvalidPushToken{
+			Token:         row.Token,
+			Provider:      row.Provider,
+			Reason:        row.Reason,
+			InvalidatedAt: row.InvalidatedAt,
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+// RateBucketRepo is a ratelimit.Store in Postgres, so every worker replica
+// draws from the same token buckets. Each take locks the bucket's row for one
+// short transaction, using the database clock so worker clock skew doesn't
+// mint tokens, and leases several tokens at once so busy workers don't make a
+// round trip per message. The row also holds the bucket's adapted rate.
+type RateBucketRepo struct {
+	db *sqlx.DB
+}
+
+func NewRateBucketRepo(db *sqlx.DB) *RateBucketRepo {
+	return &RateBucketRepo{db: db}
+}
+
+type rateBucketRow struct {
+	Tokens    float64   `db:"tokens"`
+	Rate      *float64  `db:"rate"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Now       time.Time `db:"now"`
+}
+
+func (r *RateBucketRepo) Take(ctx context.Context, key string, ceiling float64, n int) (ratelimit.Lease, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ratelimit.Lease{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO rate_buckets (key, tokens, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO NOTHING`,
+		key, max(ceiling, 1),
+	); err != nil {
+		return ratelimit.Lease{}, err
+	}
+
+	var row rateBucketRow
+	if err := tx.GetContext(ctx, &row,
+		`SELECT tokens, rate, updated_at, NOW() AS now FROM rate_buckets WHERE key = $1 FOR UPDATE`, key,
+	); err != nil {
+		return ratelimit.Lease{}, err
+	}
+
+	lease := ratelimit.Lease{Rate: ratelimit.SharedRate(row.Rate, ceiling)}
+	var tokens float64
+	tokens, lease.Tokens, lease.Wait = ratelimit.TakeTokens(row.Tokens, row.UpdatedAt, row.Now, lease.Rate, n)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE rate_buckets SET tokens = $1, updated_at = $2 WHERE key = $3`,
+		tokens, row.Now, key,
+	); err != nil {
+		return ratelimit.Lease{}, err
+	}
+	return lease, tx.Commit()
+}
+
+func (r *RateBucketRepo) SetRate(ctx context.Context, key string, rate float64) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO rate_buckets (key, tokens, rate, updated_at) VALUES ($1, $2, $2, NOW())
+		ON CONFLICT (key) DO UPDATE SET rate = EXCLUDED.rate`,
+		key, rate,
+	)
+	return err
+}
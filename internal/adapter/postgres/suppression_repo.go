@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type SuppressionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSuppressionRepo(db *sqlx.DB) *SuppressionRepo {
+	return &SuppressionRepo{db: db}
+}
+
+// activeSuppression limits a query to entries that haven't expired.
+const activeSuppression = `(expires_at IS NULL OR expires_at > NOW())`
+
+// Suppress records the recipient, replacing the reason, source and expiry
+// when it was already suppressed.
+func (r *SuppressionRepo) Suppress(ctx context.Context, s *domain.Suppression) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO suppressions (channel, recipient, reason, source, expires_at, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (channel, recipient) DO UPDATE
+		SET reason = EXCLUDED.reason, source = EXCLUDED.source,
+		    expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at`,
+		s.Channel, s.Recipient, s.Reason, s.Source, s.ExpiresAt, s.CreatedAt,
+	)
+	return err
+}
+
+func (r *SuppressionRepo) Remove(ctx context.Context, channel domain.Channel, recipient string) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM suppressions WHERE channel = $1 AND recipient = $2`, channel, recipient)
+	return affectedOne(result, err, domain.ErrSuppressionNotFound)
+}
+
+func (r *SuppressionRepo) List(ctx context.Context, filter domain.SuppressionFilter) ([]*domain.Suppression, error) {
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	query := `SELECT channel, recipient, reason, source, expires_at, created_at FROM suppressions WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if filter.Channel != nil {
+		query += ` AND channel = $` + itoa(argIdx)
+		args = append(args, *filter.Channel)
+		argIdx++
+	}
+	if !filter.IncludeExpired {
+		query += ` AND ` + activeSuppression
+	}
+	if filter.Cursor != nil {
+		query += ` AND (channel, recipient) > ($` + itoa(argIdx) + `, $` + itoa(argIdx+1) + `)`
+		args = append(args, filter.Cursor.Channel, filter.Cursor.Recipient)
+	}
+	query += ` ORDER BY channel, recipient LIMIT ` + itoa(filter.PageSize)
+
+	var entries []*domain.Suppression
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *SuppressionRepo) IsSuppressed(ctx context.Context, channel domain.Channel, recipient string) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM suppressions
+		WHERE channel = $1 AND recipient = $2 AND `+activeSuppression+`)`, channel, recipient)
+	return exists, err
+}
+
+func (r *SuppressionRepo) Suppressed(ctx context.Context, channel domain.Channel, recipients []string) (map[string]bool, error) {
+	var found []string
+	err := r.db.SelectContext(ctx, &found,
+		`SELECT recipient FROM suppressions
+		WHERE channel = $1 AND recipient = ANY($2) AND `+activeSuppression, channel, recipients)
+	if err != nil {
+		return nil, err
+	}
+	suppressed := make(map[string]bool, len(found))
+	for _, recipient := range found {
+		suppressed[recipient] = true
+	}
+	return suppressed, nil
+}
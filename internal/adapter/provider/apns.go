@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+const (
+	apnsProductionEndpoint = "https://api.push.apple.com"
+	apnsSandboxEndpoint    = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles ones
+	// refreshed more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig holds token-based (.p8) credentials. Topic is the app's bundle
+// ID.
+type APNsConfig struct {
+	TeamID     string
+	KeyID      string
+	Topic      string
+	PrivateKey *ecdsa.PrivateKey
+	Sandbox    bool
+	Endpoint   string
+	Timeout    time.Duration
+}
+
+// LoadAPNsKey reads an APNs auth key (.p8) file.
+func LoadAPNsKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodePEMKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns key is not ECDSA")
+	}
+	return ecKey, nil
+}
+
+// APNsProvider sends to Apple's HTTP/2 provider API with an ES256 provider
+// token, re-signed every 50 minutes.
+type APNsProvider struct {
+	cfg        APNsConfig
+	httpClient *http.Client
+	tokens     *tokenCache
+}
+
+func NewAPNsProvider(cfg APNsConfig) *APNsProvider {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = apnsProductionEndpoint
+		if cfg.Sandbox {
+			cfg.Endpoint = apnsSandboxEndpoint
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+
+	p := &APNsProvider{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(transport),
+		},
+	}
+	p.tokens = newTokenCache(p.signToken)
+	return p
+}
+
+type apnsAlert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type apnsAPS struct {
+	Alert apnsAlert `json:"alert"`
+	Badge *int      `json:"badge,omitempty"`
+	Sound string    `json:"sound,omitempty"`
+}
+
+func (p *APNsProvider) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "apns.send")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("apns.topic", p.cfg.Topic),
+		attribute.String("notification.channel", string(n.Channel)),
+	)
+
+	m := newPushMessage(n)
+	body, err := json.Marshal(buildAPNsPayload(m))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	token, err := p.tokens.get(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint+"/3/device/"+n.Recipient, bytes.NewReader(body))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "5")
+	if n.Priority == domain.PriorityHigh {
+		req.Header.Set("apns-priority", "10")
+	}
+	if m.TTL > 0 {
+		req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(m.TTL).Unix(), 10))
+	}
+	if m.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", m.CollapseKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		var reason struct {
+			Reason string `json:"reason"`
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = json.Unmarshal(respBody, &reason)
+
+		if reason.Reason == "ExpiredProviderToken" {
+			p.tokens.invalidate(token)
+		}
+		sendErr := apnsError(resp.StatusCode, resp.Header, reason.Reason)
+		tracing.RecordError(span, sendErr)
+		return nil, sendErr
+	}
+
+	apnsID := resp.Header.Get("apns-id")
+	span.SetAttributes(attribute.String("apns.id", apnsID))
+
+	return &port.ProviderResponse{
+		MessageID: apnsID,
+		Status:    "accepted",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// buildAPNsPayload puts custom data next to aps, where the app reads it.
+func buildAPNsPayload(m pushMessage) map[string]any {
+	payload := make(map[string]any, len(m.Data)+1)
+	for k, v := range m.Data {
+		payload[k] = v
+	}
+	payload["aps"] = apnsAPS{
+		Alert: apnsAlert{Title: m.Title, Body: m.Body},
+		Badge: m.Badge,
+		Sound: m.Sound,
+	}
+	return payload
+}
+
+// apnsError maps an APNs rejection. Unregistered (410) and BadDeviceToken
+// mean the token will never work again. An expired provider token is
+// re-signed and retried once as an auth failure; throttling and server
+// errors are retried as usual.
+func apnsError(status int, header http.Header, reason string) *port.ProviderError {
+	var category port.ErrorCategory
+	switch reason {
+	case "Unregistered", "BadDeviceToken", "DeviceTokenNotForTopic":
+		return unregisteredToken(reason, "apns "+reason)
+	case "ExpiredProviderToken", "InvalidProviderToken", "MissingProviderToken":
+		category = port.CategoryAuthFailure
+	case "PayloadTooLarge", "PayloadEmpty", "BadCollapseId", "BadExpirationDate", "BadPriority":
+		category = port.CategoryContentRejected
+	case "TooManyRequests", "TooManyProviderTokenUpdates":
+		category = port.CategoryRateLimited
+	default:
+		if status == http.StatusGone {
+			return unregisteredToken(reason, "apns "+reason)
+		}
+		category = statusCategory(status)
+	}
+	err := providerError(category, reason, "apns status %d %s", status, reason)
+	err.RetryAfter = retryAfter(header, time.Now())
+	return err
+}
+
+func (p *APNsProvider) signToken(_ context.Context) (string, time.Time, error) {
+	now := time.Now()
+	token, err := signJWT(
+		map[string]string{"alg": "ES256", "kid": p.cfg.KeyID},
+		map[string]any{"iss": p.cfg.TeamID, "iat": now.Unix()},
+		p.cfg.PrivateKey,
+	)
+	if err != nil {
+		return "", time.Time{}, providerError(port.CategoryPermanent, "", "sign apns token: %v", err)
+	}
+	return token, now.Add(apnsTokenLifetime), nil
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+const testAPNsToken = "6f1b3a0c9d2e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f90"
+
+type apnsStandIn struct {
+	*httptest.Server
+	status  int
+	reason  string
+	headers http.Header
+	path    string
+	payload map[string]any
+}
+
+func newAPNsStandIn(t *testing.T) *apnsStandIn {
+	s := &apnsStandIn{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.headers = r.Header.Clone()
+		s.path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&s.payload))
+
+		w.Header().Set("apns-id", "apns-123")
+		w.WriteHeader(s.status)
+		if s.reason != "" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"reason": s.reason})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestAPNsProvider(t *testing.T, server *apnsStandIn) (*APNsProvider, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return NewAPNsProvider(APNsConfig{
+		TeamID:     "TEAM123456",
+		KeyID:      "KEY1234567",
+		Topic:      "com.example.app",
+		PrivateKey: key,
+		Endpoint:   server.URL,
+	}), key
+}
+
+func TestAPNsProvider_BuildsRequest(t *testing.T) {
+	server := newAPNsStandIn(t)
+	p, key := newTestAPNsProvider(t, server)
+
+	resp, err := p.Send(context.Background(), newPush(testAPNsToken))
+	require.NoError(t, err)
+	assert.Equal(t, "apns-123", resp.MessageID)
+
+	assert.Equal(t, "/3/device/"+testAPNsToken, server.path)
+	assert.Equal(t, "com.example.app", server.headers.Get("apns-topic"))
+	assert.Equal(t, "alert", server.headers.Get("apns-push-type"))
+	assert.Equal(t, "10", server.headers.Get("apns-priority"))
+	assert.Equal(t, "order-42", server.headers.Get("apns-collapse-id"))
+	assert.NotEmpty(t, server.headers.Get("apns-expiration"))
+
+	assert.Equal(t, map[string]any{
+		"alert": map[string]any{"title": "Order update", "body": "Your order shipped"},
+		"badge": float64(3),
+		"sound": "default",
+	}, server.payload["aps"])
+	assert.Equal(t, "42", server.payload["order_id"])
+
+	token, ok := strings.CutPrefix(server.headers.Get("Authorization"), "bearer ")
+	require.True(t, ok)
+	verifyES256(t, token, &key.PublicKey)
+}
+
+func TestAPNsProvider_ReusesProviderToken(t *testing.T) {
+	server := newAPNsStandIn(t)
+	p, _ := newTestAPNsProvider(t, server)
+
+	_, err := p.Send(context.Background(), newPush(testAPNsToken))
+	require.NoError(t, err)
+	first := server.headers.Get("Authorization")
+
+	_, err = p.Send(context.Background(), newPush(testAPNsToken))
+	require.NoError(t, err)
+	assert.Equal(t, first, server.headers.Get("Authorization"))
+}
+
+func TestAPNsProvider_MapsErrors(t *testing.T) {
+	tests := []struct {
+		status       int
+		reason       string
+		unregistered bool
+		category     port.ErrorCategory
+	}{
+		{http.StatusGone, "Unregistered", true, port.CategoryInvalidRecipient},
+		{http.StatusBadRequest, "BadDeviceToken", true, port.CategoryInvalidRecipient},
+		{http.StatusTooManyRequests, "TooManyRequests", false, port.CategoryRateLimited},
+		{http.StatusServiceUnavailable, "ServiceUnavailable", false, port.CategoryTransient},
+		{http.StatusForbidden, "ExpiredProviderToken", false, port.CategoryAuthFailure},
+		{http.StatusRequestEntityTooLarge, "PayloadTooLarge", false, port.CategoryContentRejected},
+		{http.StatusBadRequest, "TopicDisallowed", false, port.CategoryContentRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			server := newAPNsStandIn(t)
+			server.status = tt.status
+			server.reason = tt.reason
+			p, _ := newTestAPNsProvider(t, server)
+
+			_, err := p.Send(context.Background(), newPush(testAPNsToken))
+
+			require.Error(t, err)
+			assert.Equal(t, tt.unregistered, errors.Is(err, domain.ErrUnregisteredToken))
+			assert.Equal(t, tt.category, port.Classify(err))
+			var providerErr *port.ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.reason, providerErr.Code)
+		})
+	}
+}
+
+func TestPushDispatcher_RoutesByTokenShape(t *testing.T) {
+	fcm := &fakeProvider{}
+	apns := &fakeProvider{}
+	d := NewPushDispatcher(fcm, apns)
+
+	_, err := d.Send(context.Background(), newPush(testAPNsToken))
+	require.NoError(t, err)
+	_, err = d.Send(context.Background(), newPush("dGVzdC1mY20tdG9rZW4:APA91bH"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, apns.calls)
+	assert.Equal(t, 1, fcm.calls)
+}
+
+func verifyES256(t *testing.T, token string, pub *ecdsa.PublicKey) {
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg":"ES256","kid":"KEY1234567"}`, string(header))
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(claims), `"iss":"TEAM123456"`)
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	assert.True(t, ecdsa.Verify(pub, digest[:], r, s))
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// providerError classifies a provider's rejection. Errors in retried
+// categories also wrap domain.ErrProviderUnavailable, so the message and any
+// caller checking for it agree that the provider may yet accept.
+func providerError(category port.ErrorCategory, code string, format string, args ...any) *port.ProviderError {
+	err := fmt.Errorf(format, args...)
+	if category.Policy().Retry {
+		err = fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+	return port.NewProviderError(category, code, err)
+}
+
+// statusCategory is the category an HTTP status implies when the provider
+// says nothing more specific.
+func statusCategory(status int) port.ErrorCategory {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return port.CategoryRateLimited
+	case status >= http.StatusInternalServerError:
+		return port.CategoryTransient
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return port.CategoryAuthFailure
+	case status == http.StatusBadRequest, status == http.StatusRequestEntityTooLarge, status == http.StatusUnprocessableEntity:
+		return port.CategoryContentRejected
+	default:
+		return port.CategoryPermanent
+	}
+}
+
+// tokenError classifies a refused access token request. The token endpoint
+// being down is transient; any other refusal means the credentials are bad.
+func tokenError(name string, status int, body []byte) *port.ProviderError {
+	category := port.CategoryAuthFailure
+	if retryAfterStatus(status) {
+		category = statusCategory(status)
+	}
+	return providerError(category, strconv.Itoa(status), "%s token status %d: %s", name, status, body)
+}
+
+// retryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date. It returns zero when there is none.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	val := h.Get("Retry-After")
+	if val == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(val); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
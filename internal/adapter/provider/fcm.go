@@ -0,0 +1,348 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+const (
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmDefaultTokenURL = "https://oauth2.googleapis.com/token"
+	fcmDefaultEndpoint = "https://fcm.googleapis.com"
+)
+
+// FCMConfig holds a service account's credentials. Endpoint and TokenURL
+// default to Google's and are overridable for tests.
+type FCMConfig struct {
+	ProjectID   string
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	TokenURL    string
+	Endpoint    string
+	Timeout     time.Duration
+}
+
+// LoadFCMConfig reads a Google service account JSON key file.
+func LoadFCMConfig(path string) (FCMConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FCMConfig{}, err
+	}
+
+	var account struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return FCMConfig{}, fmt.Errorf("parse service account: %w", err)
+	}
+
+	key, err := decodePEMKey([]byte(account.PrivateKey))
+	if err != nil {
+		return FCMConfig{}, fmt.Errorf("parse service account key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return FCMConfig{}, errors.New("service account key is not RSA")
+	}
+
+	return FCMConfig{
+		ProjectID:   account.ProjectID,
+		ClientEmail: account.ClientEmail,
+		PrivateKey:  rsaKey,
+		TokenURL:    account.TokenURI,
+	}, nil
+}
+
+// FCMProvider sends through the FCM HTTP v1 API. Access tokens come from the
+// service account JWT bearer grant and are cached until shortly before they
+// expire.
+type FCMProvider struct {
+	cfg        FCMConfig
+	httpClient *http.Client
+	tokens     *tokenCache
+}
+
+func NewFCMProvider(cfg FCMConfig) *FCMProvider {
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = fcmDefaultTokenURL
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fcmDefaultEndpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	p := &FCMProvider{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+	p.tokens = newTokenCache(p.fetchToken)
+	return p
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification *fcmNotification  `json:"notification,omitempty"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+	APNs         *fcmAPNs          `json:"apns,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type fcmAndroid struct {
+	CollapseKey  string                  `json:"collapse_key,omitempty"`
+	Priority     string                  `json:"priority,omitempty"`
+	TTL          string                  `json:"ttl,omitempty"`
+	Notification *fcmAndroidNotification `json:"notification,omitempty"`
+}
+
+type fcmAndroidNotification struct {
+	Sound string `json:"sound,omitempty"`
+}
+
+type fcmAPNs struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload map[string]any    `json:"payload,omitempty"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (p *FCMProvider) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "fcm.send")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("fcm.project_id", p.cfg.ProjectID),
+		attribute.String("notification.channel", string(n.Channel)),
+	)
+
+	body, err := json.Marshal(fcmRequest{Message: buildFCMMessage(n)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	token, err := p.tokens.get(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", p.cfg.Endpoint, url.PathEscape(p.cfg.ProjectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			p.tokens.invalidate(token)
+		}
+		sendErr := fcmError(resp.StatusCode, resp.Header, respBody)
+		tracing.RecordError(span, sendErr)
+		return nil, sendErr
+	}
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(respBody, &result)
+
+	span.SetAttributes(attribute.String("fcm.message_name", result.Name))
+
+	return &port.ProviderResponse{
+		MessageID: result.Name,
+		Status:    "accepted",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func buildFCMMessage(n *domain.Notification) fcmMessage {
+	m := newPushMessage(n)
+
+	msg := fcmMessage{
+		Token:        n.Recipient,
+		Notification: &fcmNotification{Title: m.Title, Body: m.Body},
+		Data:         m.Data,
+		Android:      &fcmAndroid{CollapseKey: m.CollapseKey, Priority: "NORMAL"},
+	}
+	if n.Priority == domain.PriorityHigh {
+		msg.Android.Priority = "HIGH"
+	}
+	if m.TTL > 0 {
+		msg.Android.TTL = strconv.Itoa(int(m.TTL.Seconds())) + "s"
+	}
+	if m.Sound != "" {
+		msg.Android.Notification = &fcmAndroidNotification{Sound: m.Sound}
+	}
+
+	aps := map[string]any{}
+	if m.Badge != nil {
+		aps["badge"] = *m.Badge
+	}
+	if m.Sound != "" {
+		aps["sound"] = m.Sound
+	}
+	headers := map[string]string{}
+	if m.CollapseKey != "" {
+		headers["apns-collapse-id"] = m.CollapseKey
+	}
+	if m.TTL > 0 {
+		headers["apns-expiration"] = strconv.FormatInt(time.Now().Add(m.TTL).Unix(), 10)
+	}
+	if len(aps) > 0 || len(headers) > 0 {
+		msg.APNs = &fcmAPNs{Headers: headers}
+		if len(aps) > 0 {
+			msg.APNs.Payload = map[string]any{"aps": aps}
+		}
+	}
+	return msg
+}
+
+// fcmErrorCategories maps FCM v1 error codes to the error taxonomy. Codes
+// not listed fall back to the HTTP status.
+var fcmErrorCategories = map[string]port.ErrorCategory{
+	"INVALID_ARGUMENT":       port.CategoryContentRejected,
+	"QUOTA_EXCEEDED":         port.CategoryQuotaExceeded,
+	"UNAVAILABLE":            port.CategoryTransient,
+	"INTERNAL":               port.CategoryTransient,
+	"SENDER_ID_MISMATCH":     port.CategoryAuthFailure,
+	"THIRD_PARTY_AUTH_ERROR": port.CategoryAuthFailure,
+	"UNAUTHENTICATED":        port.CategoryAuthFailure,
+	"PERMISSION_DENIED":      port.CategoryAuthFailure,
+}
+
+// fcmError maps an FCM v1 error. UNREGISTERED (404) means the app was
+// uninstalled or the token rotated. A per-device QUOTA_EXCEEDED comes back
+// as 429 and is only rate limiting; a project quota is not.
+func fcmError(status int, header http.Header, body []byte) *port.ProviderError {
+	var parsed fcmErrorResponse
+	_ = json.Unmarshal(body, &parsed)
+
+	code := parsed.Error.Status
+	for _, d := range parsed.Error.Details {
+		if d.ErrorCode != "" {
+			code = d.ErrorCode
+		}
+	}
+	if code == "UNREGISTERED" {
+		return unregisteredToken(code, "fcm "+code)
+	}
+
+	category, ok := fcmErrorCategories[code]
+	if !ok || status == http.StatusTooManyRequests {
+		category = statusCategory(status)
+	}
+	err := providerError(category, code, "fcm status %d %s: %s", status, code, strings.TrimSpace(parsed.Error.Message))
+	err.RetryAfter = retryAfter(header, time.Now())
+	return err
+}
+
+func (p *FCMProvider) fetchToken(ctx context.Context) (string, time.Time, error) {
+	now := time.Now()
+	assertion, err := signJWT(
+		map[string]string{"alg": "RS256", "typ": "JWT"},
+		map[string]any{
+			"iss":   p.cfg.ClientEmail,
+			"scope": fcmScope,
+			"aud":   p.cfg.TokenURL,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		},
+		p.cfg.PrivateKey,
+	)
+	if err != nil {
+		return "", time.Time{}, providerError(port.CategoryPermanent, "", "sign fcm assertion: %v", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: fcm token: %v", domain.ErrProviderUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", time.Time{}, tokenError("fcm", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: fcm token: %v", domain.ErrProviderUnavailable, err)
+	}
+
+	return token.AccessToken, now.Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+type fcmStandIn struct {
+	*httptest.Server
+	tokenRequests atomic.Int32
+	status        int
+	body          string
+	lastAuth      string
+	lastMessage   fcmMessage
+}
+
+func newFCMStandIn(t *testing.T) *fcmStandIn {
+	s := &fcmStandIn{status: http.StatusOK, body: `{"name":"projects/demo/messages/0:1"}`}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		assert.NotEmpty(t, r.Form.Get("assertion"))
+
+		n := s.tokenRequests.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("access-%d", n),
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("POST /v1/projects/demo/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		s.lastAuth = r.Header.Get("Authorization")
+		var req fcmRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		s.lastMessage = req.Message
+
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte(s.body))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestFCMProvider(t *testing.T, server *fcmStandIn) *FCMProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return NewFCMProvider(FCMConfig{
+		ProjectID:   "demo",
+		ClientEmail: "sender@demo.iam.gserviceaccount.com",
+		PrivateKey:  key,
+		TokenURL:    server.URL + "/token",
+		Endpoint:    server.URL,
+	})
+}
+
+func newPush(recipient string) *domain.Notification {
+	return &domain.Notification{
+		Channel:   domain.ChannelPush,
+		Recipient: recipient,
+		Content:   "Your order shipped",
+		Priority:  domain.PriorityHigh,
+		TemplateVariables: map[string]string{
+			"title":         "Order update",
+			"sound":         "default",
+			"badge":         "3",
+			"collapse_key":  "order-42",
+			"ttl":           "600",
+			"data.order_id": "42",
+		},
+	}
+}
+
+func TestFCMProvider_BuildsV1Message(t *testing.T) {
+	server := newFCMStandIn(t)
+	p := newTestFCMProvider(t, server)
+
+	resp, err := p.Send(context.Background(), newPush("fcm-token"))
+	require.NoError(t, err)
+	assert.Equal(t, "projects/demo/messages/0:1", resp.MessageID)
+
+	msg := server.lastMessage
+	assert.Equal(t, "Bearer access-1", server.lastAuth)
+	assert.Equal(t, "fcm-token", msg.Token)
+	assert.Equal(t, &fcmNotification{Title: "Order update", Body: "Your order shipped"}, msg.Notification)
+	assert.Equal(t, map[string]string{"order_id": "42"}, msg.Data)
+	assert.Equal(t, "order-42", msg.Android.CollapseKey)
+	assert.Equal(t, "600s", msg.Android.TTL)
+	assert.Equal(t, "HIGH", msg.Android.Priority)
+	assert.Equal(t, "default", msg.Android.Notification.Sound)
+	assert.Equal(t, "order-42", msg.APNs.Headers["apns-collapse-id"])
+	assert.Equal(t, map[string]any{"badge": float64(3), "sound": "default"}, msg.APNs.Payload["aps"])
+}
+
+func TestFCMProvider_CachesAccessToken(t *testing.T) {
+	server := newFCMStandIn(t)
+	p := newTestFCMProvider(t, server)
+
+	for range 3 {
+		_, err := p.Send(context.Background(), newPush("fcm-token"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), server.tokenRequests.Load())
+
+	server.status = http.StatusUnauthorized
+	server.body = `{"error":{"code":401,"status":"UNAUTHENTICATED"}}`
+	_, err := p.Send(context.Background(), newPush("fcm-token"))
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+
+	server.status = http.StatusOK
+	server.body = `{"name":"projects/demo/messages/0:2"}`
+	_, err = p.Send(context.Background(), newPush("fcm-token"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), server.tokenRequests.Load(), "rejected token is refreshed")
+	assert.Equal(t, "Bearer access-2", server.lastAuth)
+}
+
+func TestFCMProvider_MapsErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		unregistered bool
+		category     port.ErrorCategory
+	}{
+		{
+			name:         "unregistered",
+			status:       http.StatusNotFound,
+			body:         `{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`,
+			unregistered: true,
+			category:     port.CategoryInvalidRecipient,
+		},
+		{
+			name:     "device rate",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`,
+			category: port.CategoryRateLimited,
+		},
+		{
+			name:     "project quota",
+			status:   http.StatusForbidden,
+			body:     `{"error":{"code":403,"status":"RESOURCE_EXHAUSTED","details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`,
+			category: port.CategoryQuotaExceeded,
+		},
+		{
+			name:     "unavailable",
+			status:   http.StatusServiceUnavailable,
+			body:     `{"error":{"code":503,"status":"UNAVAILABLE"}}`,
+			category: port.CategoryTransient,
+		},
+		{
+			name:     "sender mismatch",
+			status:   http.StatusForbidden,
+			body:     `{"error":{"code":403,"status":"PERMISSION_DENIED","details":[{"errorCode":"SENDER_ID_MISMATCH"}]}}`,
+			category: port.CategoryAuthFailure,
+		},
+		{
+			name:     "invalid argument",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`,
+			category: port.CategoryContentRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFCMStandIn(t)
+			server.status = tt.status
+			server.body = tt.body
+			p := newTestFCMProvider(t, server)
+
+			_, err := p.Send(context.Background(), newPush("fcm-token"))
+
+			require.Error(t, err)
+			assert.Equal(t, tt.unregistered, errors.Is(err, domain.ErrUnregisteredToken))
+			assert.Equal(t, tt.category, port.Classify(err))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// pushMessage is what both push providers render. The notification content is
+// the body; the rest comes from reserved template variables: title, sound,
+// badge, collapse_key, ttl (seconds) and data.<key> for custom data.
+type pushMessage struct {
+	Title       string
+	Body        string
+	Sound       string
+	Badge       *int
+	CollapseKey string
+	TTL         time.Duration
+	Data        map[string]string
+}
+
+func newPushMessage(n *domain.Notification) pushMessage {
+	vars := n.TemplateVariables
+	m := pushMessage{
+		Title:       vars["title"],
+		Body:        n.Content,
+		Sound:       vars["sound"],
+		CollapseKey: vars["collapse_key"],
+	}
+	if badge, err := strconv.Atoi(vars["badge"]); err == nil {
+		m.Badge = &badge
+	}
+	if ttl, err := strconv.Atoi(vars["ttl"]); err == nil && ttl >= 0 {
+		m.TTL = time.Duration(ttl) * time.Second
+	}
+	for k, v := range vars {
+		if key, ok := strings.CutPrefix(k, "data."); ok && key != "" {
+			if m.Data == nil {
+				m.Data = make(map[string]string)
+			}
+			m.Data[key] = v
+		}
+	}
+	return m
+}
+
+// unregisteredToken is the invalid recipient error push providers return
+// when the service says the device token is gone.
+func unregisteredToken(code, reason string) *port.ProviderError {
+	return port.NewProviderError(port.CategoryInvalidRecipient, code, fmt.Errorf("%w: %s", domain.ErrUnregisteredToken, reason))
+}
+
+var apnsToken = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// PushDispatcher sends 64-character hex tokens to APNs and everything else to
+// FCM. Either side may be nil when only one platform is configured.
+type PushDispatcher struct {
+	fcm  port.DeliveryProvider
+	apns port.DeliveryProvider
+}
+
+func NewPushDispatcher(fcm, apns port.DeliveryProvider) *PushDispatcher {
+	return &PushDispatcher{fcm: fcm, apns: apns}
+}
+
+func (d *PushDispatcher) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	target := d.fcm
+	if d.apns != nil && (target == nil || apnsToken.MatchString(n.Recipient)) {
+		target = d.apns
+	}
+	if target == nil {
+		return nil, providerError(port.CategoryPermanent, "", "no push platform configured")
+	}
+	return target.Send(ctx, n)
+}
+
+// decodePEMKey parses a PKCS#8 or PKCS#1 private key, the forms FCM service
+// accounts and APNs .p8 files use.
+func decodePEMKey(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// retryAfterStatus reports whether a push service status is worth retrying.
+func retryAfterStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
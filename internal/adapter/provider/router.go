@@ -0,0 +1,309 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/circuitbreaker"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+type Role string
+
+const (
+	RolePrimary   Role = "primary"
+	RoleSecondary Role = "secondary"
+)
+
+// Route registers a provider for one channel. Weight splits traffic among the
+// routes of the same role; secondaries only see traffic once every primary
+// has been tried. RateLimit caps sends per second, zero meaning no cap; with
+// a RateStore the cap is shared by every worker using that store. Breaker
+// tunes the route's circuit breaker, zero fields taking the defaults.
+type Route struct {
+	Name      string
+	Channel   domain.Channel
+	Provider  port.DeliveryProvider
+	Weight    int
+	Role      Role
+	RateLimit float64
+	RateStore ratelimit.Store
+	Breaker   circuitbreaker.Settings
+}
+
+type route struct {
+	Route
+	breaker *circuitbreaker.Breaker
+	limiter *ratelimit.Limiter
+}
+
+// Router sends each notification through the providers registered for its
+// channel, failing over to the next one when a provider's breaker is open or
+// it returns an error whose category is retried. Other errors are returned
+// as is, since another provider would reject the same message.
+//
+// Each route's rate adapts to the provider: a rate limited error halves it
+// and a Retry-After pauses the route, which is then skipped like an open
+// breaker; accepted sends win the rate back step by step.
+type Router struct {
+	routes map[domain.Channel][]*route
+	intn   func(n int) int
+}
+
+func NewRouter(routes ...Route) *Router {
+	r := &Router{
+		routes: make(map[domain.Channel][]*route),
+		intn:   rand.IntN,
+	}
+	for _, rt := range routes {
+		if rt.Weight < 1 {
+			rt.Weight = 1
+		}
+		if rt.Role == "" {
+			rt.Role = RolePrimary
+		}
+		name := string(rt.Channel) + "/" + rt.Name
+		limiter := ratelimit.New(rt.RateLimit)
+		if rt.RateStore != nil {
+			limiter = ratelimit.NewShared(rt.RateLimit, rt.RateStore, "provider/"+name)
+		}
+		r.routes[rt.Channel] = append(r.routes[rt.Channel], &route{
+			Route:   rt,
+			breaker: circuitbreaker.New(name, rt.Breaker),
+			limiter: limiter,
+		})
+	}
+	return r
+}
+
+func (r *Router) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "provider.route")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("notification.channel", string(n.Channel)))
+
+	candidates := r.order(n.Channel)
+	if len(candidates) == 0 {
+		err := fmt.Errorf("no provider configured for channel %s", n.Channel)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	var lastErr error
+	for i, rt := range candidates {
+		span.AddEvent("provider.attempt", attemptEvent(rt, i))
+
+		if wait := rt.limiter.PausedFor(); wait > 0 {
+			throttled := port.NewProviderError(port.CategoryRateLimited, "",
+				fmt.Errorf("%w: throttled for %s", domain.ErrProviderUnavailable, wait.Round(time.Second)))
+			throttled.RetryAfter = wait
+			lastErr = withProvider(throttled, rt.Name)
+			continue
+		}
+		if err := rt.limiter.Wait(ctx); err != nil {
+			lastErr = withProvider(fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err), rt.Name)
+			break
+		}
+
+		// A rejection that isn't worth retrying means the provider is up and
+		// answering, so it is reported to the breaker as a success and
+		// returned afterwards. So is throttling, which the limiter handles.
+		var rejected error
+		result, err := rt.breaker.Execute(func() (any, error) {
+			resp, err := rt.Provider.Send(ctx, n)
+			if category := port.Classify(err); err != nil && (!category.Policy().Retry || category == port.CategoryRateLimited) {
+				rejected = err
+				return nil, nil
+			}
+			return resp, err
+		})
+		if rejected != nil {
+			err = rejected
+		}
+		if err == nil {
+			rt.limiter.Success(ctx)
+			resp := result.(*port.ProviderResponse)
+			resp.Provider = rt.Name
+			span.SetAttributes(
+				attribute.String("provider.name", rt.Name),
+				attribute.Int("provider.failovers", i),
+			)
+			return resp, nil
+		}
+
+		if circuitbreaker.IsOpen(err) {
+			err = fmt.Errorf("%w: %s", domain.ErrCircuitOpen, rt.Name)
+		}
+		named := withProvider(err, rt.Name)
+		if named.Category == port.CategoryRateLimited {
+			rt.limiter.Throttled(ctx, named.RetryAfter)
+		}
+		lastErr = named
+
+		if !port.Classify(err).Policy().Retry {
+			break
+		}
+	}
+
+	tracing.RecordError(span, lastErr)
+	return nil, lastErr
+}
+
+// order returns the channel's routes in the order they should be tried:
+// primaries, then secondaries, each drawn by weight. Routes whose breaker is
+// open or whose rate is paused go last so a recovering provider still gets
+// its half-open probe once the healthy ones have failed.
+func (r *Router) order(channel domain.Channel) []*route {
+	var primaries, secondaries []*route
+	for _, rt := range r.routes[channel] {
+		if rt.Role == RoleSecondary {
+			secondaries = append(secondaries, rt)
+		} else {
+			primaries = append(primaries, rt)
+		}
+	}
+
+	ordered := append(r.shuffle(primaries), r.shuffle(secondaries)...)
+	slices.SortStableFunc(ordered, func(a, b *route) int {
+		return boolRank(a.unavailable()) - boolRank(b.unavailable())
+	})
+	return ordered
+}
+
+// shuffle is a weighted draw without replacement.
+func (r *Router) shuffle(routes []*route) []*route {
+	remaining := slices.Clone(routes)
+	out := make([]*route, 0, len(routes))
+
+	for len(remaining) > 0 {
+		total := 0
+		for _, rt := range remaining {
+			total += rt.Weight
+		}
+		pick := r.intn(total)
+		for i, rt := range remaining {
+			if pick < rt.Weight {
+				out = append(out, rt)
+				remaining = slices.Delete(remaining, i, i+1)
+				break
+			}
+			pick -= rt.Weight
+		}
+	}
+	return out
+}
+
+func (rt *route) unavailable() bool {
+	return rt.breaker.Open() || rt.limiter.PausedFor() > 0
+}
+
+// ProviderRates reports each route's current rate limit.
+func (r *Router) ProviderRates() []domain.ProviderRate {
+	var rates []domain.ProviderRate
+	for _, routes := range r.routes {
+		for _, rt := range routes {
+			rate := domain.ProviderRate{
+				Channel:  rt.Channel,
+				Provider: rt.Name,
+				Rate:     rt.limiter.Rate(),
+				Ceiling:  rt.limiter.Ceiling(),
+				Shared:   rt.limiter.Shared(),
+			}
+			if wait := rt.limiter.PausedFor(); wait > 0 {
+				until := time.Now().Add(wait).UTC()
+				rate.PausedUntil = &until
+			}
+			rates = append(rates, rate)
+		}
+	}
+	return rates
+}
+
+// CircuitBreakers reports the state of each route's breaker.
+func (r *Router) CircuitBreakers() []domain.CircuitBreaker {
+	var breakers []domain.CircuitBreaker
+	for _, routes := range r.routes {
+		for _, rt := range routes {
+			snap := rt.breaker.Snapshot()
+			b := domain.CircuitBreaker{
+				Channel:     rt.Channel,
+				Provider:    rt.Name,
+				State:       domain.BreakerState(snap.State),
+				ForcedOpen:  snap.Forced,
+				Transitions: make(map[domain.BreakerState]int64, len(snap.Transitions)),
+			}
+			for state, n := range snap.Transitions {
+				b.Transitions[domain.BreakerState(state)] = n
+			}
+			if !snap.OpenUntil.IsZero() {
+				until := snap.OpenUntil.UTC()
+				b.OpenUntil = &until
+			}
+			if !snap.ChangedAt.IsZero() {
+				changed := snap.ChangedAt.UTC()
+				b.ChangedAt = &changed
+			}
+			breakers = append(breakers, b)
+		}
+	}
+	return breakers
+}
+
+func (r *Router) ForceOpen(channel domain.Channel, provider string) bool {
+	return r.withBreaker(channel, provider, (*circuitbreaker.Breaker).ForceOpen)
+}
+
+func (r *Router) HoldOpen(channel domain.Channel, provider string, until time.Time) bool {
+	return r.withBreaker(channel, provider, func(b *circuitbreaker.Breaker) { b.HoldOpen(until) })
+}
+
+func (r *Router) Reset(channel domain.Channel, provider string) bool {
+	return r.withBreaker(channel, provider, (*circuitbreaker.Breaker).Reset)
+}
+
+func (r *Router) withBreaker(channel domain.Channel, provider string, fn func(*circuitbreaker.Breaker)) bool {
+	for _, rt := range r.routes[channel] {
+		if rt.Name == provider {
+			fn(rt.breaker)
+			return true
+		}
+	}
+	return false
+}
+
+// withProvider names the provider on a send error, classifying it if the
+// provider didn't.
+func withProvider(err error, name string) *port.ProviderError {
+	var providerErr *port.ProviderError
+	if errors.As(err, &providerErr) {
+		named := *providerErr
+		named.Provider = name
+		return &named
+	}
+	return &port.ProviderError{Provider: name, Category: port.Classify(err), Err: err}
+}
+
+func attemptEvent(rt *route, attempt int) trace.EventOption {
+	return trace.WithAttributes(
+		attribute.String("provider.name", rt.Name),
+		attribute.String("provider.role", string(rt.Role)),
+		attribute.Int("provider.attempt", attempt),
+	)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
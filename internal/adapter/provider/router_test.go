@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/circuitbreaker"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+type fakeProvider struct {
+	calls int
+	err   error
+}
+
+func (f *fakeProvider) Send(_ context.Context, _ *domain.Notification) (*port.ProviderResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &port.ProviderResponse{MessageID: "msg-1"}, nil
+}
+
+func testNotification() *domain.Notification {
+	return &domain.Notification{Channel: domain.ChannelSMS, Recipient: "+905551234567", Content: "hi"}
+}
+
+func TestRouter_FailsOverOnTransientError(t *testing.T) {
+	primary := &fakeProvider{err: domain.ErrProviderUnavailable}
+	secondary := &fakeProvider{}
+	r := NewRouter(
+		Route{Name: "a", Channel: domain.ChannelSMS, Provider: primary, Role: RolePrimary},
+		Route{Name: "b", Channel: domain.ChannelSMS, Provider: secondary, Role: RoleSecondary},
+	)
+
+	resp, err := r.Send(context.Background(), testNotification())
+
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp.Provider)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+func TestRouter_PermanentErrorStopsFailover(t *testing.T) {
+	primary := &fakeProvider{err: errors.New("permanent provider error: status 400")}
+	secondary := &fakeProvider{}
+	r := NewRouter(
+		Route{Name: "a", Channel: domain.ChannelSMS, Provider: primary, Role: RolePrimary},
+		Route{Name: "b", Channel: domain.ChannelSMS, Provider: secondary, Role: RoleSecondary},
+	)
+
+	_, err := r.Send(context.Background(), testNotification())
+
+	var providerErr *port.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, "a", providerErr.Provider)
+	assert.Zero(t, secondary.calls)
+}
+
+func TestRouter_SkipsOpenBreaker(t *testing.T) {
+	flaky := &fakeProvider{err: domain.ErrProviderUnavailable}
+	healthy := &fakeProvider{}
+	r := NewRouter(
+		Route{Name: "flaky", Channel: domain.ChannelSMS, Provider: flaky, Weight: 100},
+		Route{Name: "healthy", Channel: domain.ChannelSMS, Provider: healthy, Weight: 1},
+	)
+	r.intn = func(int) int { return 0 }
+
+	for range 5 {
+		_, err := r.Send(context.Background(), testNotification())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 5, flaky.calls)
+
+	resp, err := r.Send(context.Background(), testNotification())
+
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", resp.Provider)
+	assert.Equal(t, 5, flaky.calls, "open breaker is tried last")
+}
+
+func TestRouter_BreakerSettingsPerRoute(t *testing.T) {
+	flaky := &fakeProvider{err: domain.ErrProviderUnavailable}
+	r := NewRouter(
+		Route{Name: "flaky", Channel: domain.ChannelSMS, Provider: flaky, Breaker: circuitbreaker.Settings{Failures: 2}},
+	)
+
+	for range 3 {
+		_, _ = r.Send(context.Background(), testNotification())
+	}
+
+	assert.Equal(t, 2, flaky.calls)
+	breakers := r.CircuitBreakers()
+	require.Len(t, breakers, 1)
+	assert.Equal(t, domain.BreakerOpen, breakers[0].State)
+	assert.Equal(t, int64(1), breakers[0].Transitions[domain.BreakerOpen])
+	assert.NotNil(t, breakers[0].OpenUntil)
+}
+
+func TestRouter_ForceOpenAndReset(t *testing.T) {
+	primary := &fakeProvider{}
+	secondary := &fakeProvider{}
+	r := NewRouter(
+		Route{Name: "a", Channel: domain.ChannelSMS, Provider: primary, Role: RolePrimary},
+		Route{Name: "b", Channel: domain.ChannelSMS, Provider: secondary, Role: RoleSecondary},
+	)
+
+	require.True(t, r.ForceOpen(domain.ChannelSMS, "a"))
+	assert.False(t, r.ForceOpen(domain.ChannelEmail, "a"))
+
+	resp, err := r.Send(context.Background(), testNotification())
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp.Provider)
+	assert.Zero(t, primary.calls)
+
+	require.True(t, r.Reset(domain.ChannelSMS, "a"))
+	resp, err = r.Send(context.Background(), testNotification())
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Provider)
+}
+
+func TestRouter_AllTransientReturnsRetryableError(t *testing.T) {
+	r := NewRouter(
+		Route{Name: "a", Channel: domain.ChannelSMS, Provider: &fakeProvider{err: domain.ErrProviderUnavailable}},
+		Route{Name: "b", Channel: domain.ChannelSMS, Provider: &fakeProvider{err: domain.ErrProviderUnavailable}},
+	)
+
+	_, err := r.Send(context.Background(), testNotification())
+
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+}
+
+func TestRouter_WeightedShuffle(t *testing.T) {
+	r := NewRouter()
+	routes := []*route{
+		{Route: Route{Name: "a", Weight: 1}},
+		{Route: Route{Name: "b", Weight: 3}},
+	}
+	r.intn = func(n int) int { return min(1, n-1) }
+
+	out := r.shuffle(routes)
+
+	require.Len(t, out, 2)
+	assert.Equal(t, "b", out[0].Name)
+	assert.Equal(t, "a", out[1].Name)
+}
+
+func TestRouter_PermanentErrorsDoNotTripBreaker(t *testing.T) {
+	rejecting := &fakeProvider{err: errors.New("permanent provider error: status 400")}
+	r := NewRouter(Route{Name: "a", Channel: domain.ChannelSMS, Provider: rejecting})
+
+	for range 10 {
+		_, err := r.Send(context.Background(), testNotification())
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, 10, rejecting.calls)
+	assert.False(t, r.routes[domain.ChannelSMS][0].breaker.Open())
+}
+
+func TestRouter_ThrottledRouteBacksOffAndIsSkipped(t *testing.T) {
+	throttled := port.NewProviderError(port.CategoryRateLimited, "429", domain.ErrProviderUnavailable)
+	throttled.RetryAfter = time.Minute
+	primary := &fakeProvider{err: throttled}
+	secondary := &fakeProvider{}
+	r := NewRouter(
+		Route{Name: "a", Channel: domain.ChannelSMS, Provider: primary, Role: RolePrimary, RateLimit: 100},
+		Route{Name: "b", Channel: domain.ChannelSMS, Provider: secondary, Role: RoleSecondary, RateLimit: 100},
+	)
+
+	resp, err := r.Send(context.Background(), testNotification())
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp.Provider)
+
+	resp, err = r.Send(context.Background(), testNotification())
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp.Provider)
+	assert.Equal(t, 1, primary.calls, "a paused route is not called")
+
+	rates := map[string]domain.ProviderRate{}
+	for _, rate := range r.ProviderRates() {
+		rates[rate.Provider] = rate
+	}
+	assert.Equal(t, 50.0, rates["a"].Rate)
+	assert.Equal(t, 100.0, rates["a"].Ceiling)
+	require.NotNil(t, rates["a"].PausedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *rates["a"].PausedUntil, time.Second)
+	assert.Equal(t, 100.0, rates["b"].Rate)
+	assert.Nil(t, rates["b"].PausedUntil)
+}
+
+func TestRouter_PausedRouteReturnsRetryAfter(t *testing.T) {
+	throttled := port.NewProviderError(port.CategoryRateLimited, "429", domain.ErrProviderUnavailable)
+	throttled.RetryAfter = 30 * time.Second
+	p := &fakeProvider{err: throttled}
+	r := NewRouter(Route{Name: "a", Channel: domain.ChannelSMS, Provider: p, RateLimit: 10})
+
+	_, _ = r.Send(context.Background(), testNotification())
+	_, err := r.Send(context.Background(), testNotification())
+
+	var providerErr *port.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, port.CategoryRateLimited, providerErr.Category)
+	assert.InDelta(t, 30*time.Second, providerErr.RetryAfter, float64(time.Second))
+	assert.Equal(t, 1, p.calls)
+}
+
+func TestRouter_SharedRateStoreLimitsAcrossRouters(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	newRouter := func() *Router {
+		return NewRouter(Route{Name: "a", Channel: domain.ChannelSMS, Provider: &fakeProvider{}, RateLimit: 2, RateStore: store})
+	}
+	workers := []*Router{newRouter(), newRouter()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	sent := 0
+	for _, r := range workers {
+		for range 2 {
+			if _, err := r.Send(ctx, testNotification()); err == nil {
+				sent++
+			}
+		}
+	}
+	assert.Equal(t, 2, sent, "both routers draw from one bucket")
+	assert.True(t, workers[0].ProviderRates()[0].Shared)
+}
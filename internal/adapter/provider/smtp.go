@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+// SMTPConfig configures an SMTPProvider. STARTTLS is used whenever the server
+// offers it; RequireTLS refuses to send over a server that does not. AUTH
+// PLAIN is used when Username is set, and only over TLS or to localhost.
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	Subject    string
+	RequireTLS bool
+	TLSConfig  *tls.Config
+	Timeout    time.Duration
+}
+
+// SMTPProvider delivers email notifications over SMTP. The subject comes from
+// the notification's "subject" template variable when set, else the
+// configured default.
+type SMTPProvider struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPProvider(cfg SMTPConfig) *SMTPProvider {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = "Notification"
+	}
+	return &SMTPProvider{cfg: cfg}
+}
+
+func (p *SMTPProvider) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "smtp.send")
+	defer span.End()
+
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	span.SetAttributes(
+		attribute.String("smtp.server", addr),
+		attribute.String("notification.channel", string(n.Channel)),
+		attribute.String("notification.recipient", n.Recipient),
+	)
+
+	from, err := mail.ParseAddress(p.cfg.From)
+	if err != nil {
+		sendErr := providerError(port.CategoryPermanent, "", "smtp from address: %v", err)
+		tracing.RecordError(span, sendErr)
+		return nil, sendErr
+	}
+
+	messageID, msg, err := p.buildMessage(from, n, time.Now())
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if err := p.deliver(ctx, addr, from.Address, n.Recipient, msg); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("smtp.message_id", messageID))
+
+	return &port.ProviderResponse{
+		MessageID: messageID,
+		Status:    "accepted",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func (p *SMTPProvider) deliver(ctx context.Context, addr, sender, recipient string, msg []byte) error {
+	dialer := net.Dialer{Timeout: p.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%w: smtp dial: %v", domain.ErrProviderUnavailable, err)
+	}
+
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return smtpError("greeting", err, true)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := client.Hello("localhost"); err != nil {
+		return smtpError("ehlo", err, true)
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		tlsConfig := p.cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: p.cfg.Host, MinVersion: tls.VersionTLS12}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return smtpError("starttls", err, true)
+		}
+	} else if p.cfg.RequireTLS {
+		return providerError(port.CategoryPermanent, "", "smtp server %s does not offer STARTTLS", addr)
+	}
+
+	if p.cfg.Username != "" {
+		auth := smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return smtpError("auth", err, false)
+		}
+	}
+
+	if err := client.Mail(sender); err != nil {
+		return smtpError("mail from", err, true)
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return smtpError(smtpStageRcpt, err, true)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return smtpError("data", err, true)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return smtpError("data", err, true)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError("data", err, true)
+	}
+
+	_ = client.Quit()
+	return nil
+}
+
+// smtpStageRcpt is the RCPT TO command, the only reply that speaks for the
+// recipient's address.
+const smtpStageRcpt = "rcpt to"
+
+// smtpError maps a reply to the error taxonomy. 4xx replies are transient
+// except 452, a full mailbox or exceeded limit. 5xx replies are refined by
+// the reply code and the enhanced status code (RFC 3463) the server puts
+// first in the text: 5.1.x to RCPT TO is a bad recipient, 5.7.x a policy
+// rejection. Errors without a reply code (I/O, TLS) are transient unless the
+// stage says otherwise.
+func smtpError(stage string, err error, transientIO bool) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		code := strconv.Itoa(reply.Code)
+		return providerError(smtpReplyCategory(stage, reply), code, "smtp %s: %d %s", stage, reply.Code, reply.Msg)
+	}
+	if transientIO {
+		return providerError(port.CategoryTransient, "", "smtp %s: %v", stage, err)
+	}
+	return providerError(port.CategoryPermanent, "", "smtp %s: %v", stage, err)
+}
+
+// smtpReplyCategory only blames the recipient for a rejected RCPT TO. The
+// same codes at MAIL FROM or DATA, and 5.1.7 and 5.1.8 at any stage, are
+// about the sender, and suppressing the recipient for them would be wrong.
+func smtpReplyCategory(stage string, reply *textproto.Error) port.ErrorCategory {
+	enhanced, _, _ := strings.Cut(reply.Msg, " ")
+	if !enhancedStatus.MatchString(enhanced) {
+		enhanced = ""
+	}
+	switch {
+	case reply.Code == 452, strings.HasPrefix(enhanced, "4.2.2"), strings.HasPrefix(enhanced, "5.2.2"):
+		return port.CategoryQuotaExceeded
+	case reply.Code >= 400 && reply.Code < 500:
+		return port.CategoryTransient
+	case reply.Code == 530, reply.Code == 534, reply.Code == 535:
+		return port.CategoryAuthFailure
+	case enhanced == "5.1.7", enhanced == "5.1.8":
+		return port.CategoryPermanent
+	case stage == smtpStageRcpt && (strings.HasPrefix(enhanced, "5.1.") || reply.Code == 550 && enhanced == "" || reply.Code == 551 || reply.Code == 553):
+		return port.CategoryInvalidRecipient
+	case strings.HasPrefix(enhanced, "5.7."), reply.Code == 554:
+		return port.CategoryContentRejected
+	default:
+		return port.CategoryPermanent
+	}
+}
+
+var enhancedStatus = regexp.MustCompile(`^[245]\.\d{1,3}\.\d{1,3}$`)
+
+var htmlTag = regexp.MustCompile(`<[a-zA-Z/!][^>]*>`)
+
+// buildMessage renders n as a multipart/alternative message with a plain
+// text and an HTML part. HTML content gets a text part with the tags
+// stripped; plain content gets an escaped HTML part.
+func (p *SMTPProvider) buildMessage(from *mail.Address, n *domain.Notification, now time.Time) (string, []byte, error) {
+	subject := p.cfg.Subject
+	if s := n.TemplateVariables["subject"]; s != "" {
+		subject = s
+	}
+
+	text, htmlBody := n.Content, n.Content
+	if htmlTag.MatchString(n.Content) {
+		text = strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(n.Content, "")))
+	} else {
+		htmlBody = "<p>" + strings.ReplaceAll(html.EscapeString(n.Content), "\n", "<br>") + "</p>"
+	}
+
+	domainPart := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	messageID := uuid.New().String() + "@" + domainPart
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", n.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s>\r\n", messageID)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return "", nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return messageID, buf.Bytes(), nil
+}
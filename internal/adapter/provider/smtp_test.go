@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+type receivedMail struct {
+	from, to string
+	data     []byte
+	tls      bool
+	user     string
+}
+
+// smtpStandIn is just enough of an SMTP server to exercise SMTPProvider:
+// EHLO, optional STARTTLS, AUTH PLAIN, MAIL, RCPT, DATA and QUIT.
+type smtpStandIn struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	mailReply string
+	rcptReply string
+
+	mu       sync.Mutex
+	received []receivedMail
+}
+
+func newSMTPStandIn(t *testing.T, tlsConfig *tls.Config) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &smtpStandIn{ln: ln, tlsConfig: tlsConfig, mailReply: "250 OK", rcptReply: "250 OK"}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) messages() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.received...)
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	tp := textproto.NewConn(conn)
+	var current receivedMail
+	reply := func(line string) { _ = tp.PrintfLine("%s", line) }
+
+	reply("220 stand-in ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.tlsConfig != nil && !current.tls {
+				reply("250-stand-in")
+				reply("250-STARTTLS")
+			} else {
+				reply("250-stand-in")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			current.tls = true
+		case "AUTH":
+			creds, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			parts := strings.Split(string(creds), "\x00")
+			if len(parts) == 3 && parts[2] == "secret" {
+				current.user = parts[1]
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		case "MAIL":
+			current.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply(s.mailReply)
+		case "RCPT":
+			current.to = strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = data
+			s.mu.Lock()
+			s.received = append(s.received, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func selfSignedTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, pool
+}
+
+func newEmail(content string) *domain.Notification {
+	return &domain.Notification{
+		Channel:   domain.ChannelEmail,
+		Recipient: "user@example.com",
+		Content:   content,
+	}
+}
+
+func readParts(t *testing.T, data []byte) (string, map[string]string) {
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(bufio.NewReader(part))
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	return subject, parts
+}
+
+func TestSMTPProvider_SendsMultipartMessage(t *testing.T) {
+	server := newSMTPStandIn(t, nil)
+	p := NewSMTPProvider(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "mailer",
+		Password: "secret",
+		From:     "Notifications <noreply@example.com>",
+	})
+
+	n := newEmail("Hello & welcome\nSee you")
+	n.TemplateVariables = map[string]string{"subject": "Merhaba dünya"}
+
+	resp, err := p.Send(context.Background(), n)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(resp.MessageID, "@example.com"))
+
+	received := server.messages()
+	require.Len(t, received, 1)
+	assert.Equal(t, "noreply@example.com", received[0].from)
+	assert.Equal(t, "user@example.com", received[0].to)
+	assert.Equal(t, "mailer", received[0].user)
+
+	subject, parts := readParts(t, received[0].data)
+	assert.Equal(t, "Merhaba dünya", subject)
+	assert.Equal(t, "Hello & welcome\nSee you", parts["text/plain"])
+	assert.Equal(t, "<p>Hello &amp; welcome<br>See you</p>", parts["text/html"])
+}
+
+func TestSMTPProvider_HTMLContentGetsTextAlternative(t *testing.T) {
+	server := newSMTPStandIn(t, nil)
+	p := NewSMTPProvider(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@example.com"})
+
+	_, err := p.Send(context.Background(), newEmail("<h1>Order shipped</h1><p>Track it &raquo;</p>"))
+	require.NoError(t, err)
+
+	_, parts := readParts(t, server.messages()[0].data)
+	assert.Equal(t, "Order shippedTrack it »", parts["text/plain"])
+	assert.Equal(t, "<h1>Order shipped</h1><p>Track it &raquo;</p>", parts["text/html"])
+}
+
+func TestSMTPProvider_UpgradesWithSTARTTLS(t *testing.T) {
+	serverTLS, pool := selfSignedTLS(t)
+	server := newSMTPStandIn(t, serverTLS)
+	p := NewSMTPProvider(SMTPConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		Username:   "mailer",
+		Password:   "secret",
+		From:       "noreply@example.com",
+		RequireTLS: true,
+		TLSConfig:  &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+	})
+
+	_, err := p.Send(context.Background(), newEmail("hi"))
+	require.NoError(t, err)
+
+	received := server.messages()
+	require.Len(t, received, 1)
+	assert.True(t, received[0].tls)
+}
+
+func TestSMTPProvider_RequireTLSWithoutSTARTTLS(t *testing.T) {
+	server := newSMTPStandIn(t, nil)
+	p := NewSMTPProvider(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@example.com", RequireTLS: true})
+
+	_, err := p.Send(context.Background(), newEmail("hi"))
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrProviderUnavailable)
+	assert.Empty(t, server.messages())
+}
+
+func TestSMTPProvider_MapsReplyCodes(t *testing.T) {
+	tests := []struct {
+		reply    string
+		category port.ErrorCategory
+	}{
+		{"450 mailbox busy", port.CategoryTransient},
+		{"421 service not available", port.CategoryTransient},
+		{"452 4.2.2 mailbox full", port.CategoryQuotaExceeded},
+		{"550 5.1.1 no such user", port.CategoryInvalidRecipient},
+		{"550 no such user", port.CategoryInvalidRecipient},
+		{"553 mailbox name not allowed", port.CategoryInvalidRecipient},
+		{"550 5.7.1 message rejected as spam", port.CategoryContentRejected},
+		{"552 5.2.2 over quota", port.CategoryQuotaExceeded},
+		{"552 message size exceeds limit", port.CategoryPermanent},
+		{"553 5.1.8 sender domain does not exist", port.CategoryPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			server := newSMTPStandIn(t, nil)
+			server.rcptReply = tt.reply
+			p := NewSMTPProvider(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@example.com"})
+
+			_, err := p.Send(context.Background(), newEmail("hi"))
+
+			require.Error(t, err)
+			assert.Equal(t, tt.category, port.Classify(err))
+			assert.Equal(t, tt.category.Policy().Retry, errors.Is(err, domain.ErrProviderUnavailable))
+		})
+	}
+}
+
+func TestSMTPProvider_SenderRejectionIsNotRecipientError(t *testing.T) {
+	for _, reply := range []string{"550 5.1.7 bad sender address", "553 sender not allowed", "550 5.1.1 unknown sender"} {
+		t.Run(reply, func(t *testing.T) {
+			server := newSMTPStandIn(t, nil)
+			server.mailReply = reply
+			p := NewSMTPProvider(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@example.com"})
+
+			_, err := p.Send(context.Background(), newEmail("hi"))
+
+			require.Error(t, err)
+			assert.Equal(t, port.CategoryPermanent, port.Classify(err))
+		})
+	}
+}
+
+func TestSMTPProvider_AuthFailure(t *testing.T) {
+	server := newSMTPStandIn(t, nil)
+	p := NewSMTPProvider(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "mailer",
+		Password: "wrong",
+		From:     "noreply@example.com",
+	})
+
+	_, err := p.Send(context.Background(), newEmail("hi"))
+
+	require.Error(t, err)
+	assert.Equal(t, port.CategoryAuthFailure, port.Classify(err))
+}
+
+func TestSMTPProvider_UnreachableServerIsTransient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	p := NewSMTPProvider(SMTPConfig{Host: "127.0.0.1", Port: port, From: "noreply@example.com", Timeout: time.Second})
+
+	_, err = p.Send(context.Background(), newEmail("hi"))
+
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tokenCache holds a bearer token until shortly before it expires. fetch is
+// called with the lock held, so concurrent senders share one refresh.
+type tokenCache struct {
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	fetch  func(ctx context.Context) (string, time.Time, error)
+	now    func() time.Time
+}
+
+const tokenRefreshMargin = time.Minute
+
+func newTokenCache(fetch func(ctx context.Context) (string, time.Time, error)) *tokenCache {
+	return &tokenCache{fetch: fetch, now: time.Now}
+}
+
+func (c *tokenCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.now().Add(tokenRefreshMargin).Before(c.expiry) {
+		return c.token, nil
+	}
+
+	token, expiry, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiry = token, expiry
+	return token, nil
+}
+
+// invalidate drops a token the server rejected, so the next get refreshes.
+func (c *tokenCache) invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// signJWT builds a compact JWS. ES256 signatures are the raw r||s pair the
+// spec requires rather than the ASN.1 form crypto/ecdsa produces.
+func signJWT(header, claims any, key crypto.Signer) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(h) + "." + enc.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, signErr := ecdsa.Sign(rand.Reader, k, digest[:])
+		if signErr != nil {
+			return "", signErr
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	default:
+		return "", fmt.Errorf("unsupported signing key %T", key)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
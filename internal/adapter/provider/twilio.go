@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+const twilioDefaultBaseURL = "https://api.twilio.com"
+
+// TwilioConfig configures a TwilioProvider. From is the sender number; set
+// MessagingServiceSID instead to let the service pick one. StatusCallbackURL
+// is passed on every message so delivery receipts come back to our API.
+type TwilioConfig struct {
+	AccountSID          string
+	AuthToken           string
+	From                string
+	MessagingServiceSID string
+	StatusCallbackURL   string
+	BaseURL             string
+	Timeout             time.Duration
+}
+
+// TwilioProvider sends SMS through the Twilio Messages API, which most SMS
+// aggregators mirror: a form-encoded POST with basic auth and a JSON reply
+// carrying a numeric error code. Like every provider it is wrapped in a
+// circuitbreaker.Breaker by the Router.
+type TwilioProvider struct {
+	cfg        TwilioConfig
+	httpClient *http.Client
+}
+
+func NewTwilioProvider(cfg TwilioConfig) *TwilioProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = twilioDefaultBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &TwilioProvider{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+type twilioMessage struct {
+	SID         string `json:"sid"`
+	Status      string `json:"status"`
+	DateCreated string `json:"date_created"`
+}
+
+type twilioError struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	MoreInfo string `json:"more_info"`
+	Status   int    `json:"status"`
+}
+
+func (p *TwilioProvider) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "twilio.send")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("notification.channel", string(n.Channel)),
+		attribute.String("notification.recipient", n.Recipient),
+	)
+
+	form := url.Values{
+		"To":   {n.Recipient},
+		"Body": {n.Content},
+	}
+	if p.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", p.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", p.cfg.From)
+	}
+	if p.cfg.StatusCallbackURL != "" {
+		form.Set("StatusCallback", p.cfg.StatusCallbackURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.cfg.BaseURL, url.PathEscape(p.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr twilioError
+		_ = json.Unmarshal(body, &apiErr)
+		sendErr := classifyTwilioError(resp.StatusCode, resp.Header, apiErr)
+		span.SetAttributes(attribute.Int("twilio.error_code", apiErr.Code))
+		tracing.RecordError(span, sendErr)
+		return nil, sendErr
+	}
+
+	var msg twilioMessage
+	if err := json.Unmarshal(body, &msg); err != nil || msg.SID == "" {
+		err = fmt.Errorf("%w: unreadable twilio response: %s", domain.ErrProviderUnavailable, body)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("twilio.message_sid", msg.SID))
+
+	return &port.ProviderResponse{
+		MessageID:     msg.SID,
+		Status:        msg.Status,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		AwaitsReceipt: p.cfg.StatusCallbackURL != "",
+	}, nil
+}
+
+// twilioCodeCategories maps Twilio API error codes to the error taxonomy.
+// Codes not listed fall back to the HTTP status. 30003, an unreachable
+// handset, is deliberately not an invalid recipient: a phone that is off or
+// out of coverage comes back, and invalid recipients are suppressed.
+var twilioCodeCategories = map[int]port.ErrorCategory{
+	21211: port.CategoryInvalidRecipient, // invalid 'To' number
+	21214: port.CategoryInvalidRecipient, // 'To' number cannot be reached
+	21610: port.CategoryInvalidRecipient, // recipient unsubscribed
+	21612: port.CategoryInvalidRecipient, // unroutable 'To' number
+	21614: port.CategoryInvalidRecipient, // 'To' is not a mobile number
+	30005: port.CategoryInvalidRecipient, // unknown destination
+	30006: port.CategoryInvalidRecipient, // landline or unreachable carrier
+	21602: port.CategoryContentRejected,  // body required
+	21617: port.CategoryContentRejected,  // body too long
+	30007: port.CategoryContentRejected,  // carrier filtered
+	20003: port.CategoryAuthFailure,      // authentication failed
+	20005: port.CategoryAuthFailure,      // account not active
+	30002: port.CategoryAuthFailure,      // account suspended
+	20429: port.CategoryRateLimited,      // too many requests
+	14107: port.CategoryRateLimited,      // send rate exceeded
+	30001: port.CategoryRateLimited,      // queue overflow
+	30022: port.CategoryRateLimited,      // US A2P 10DLC throughput exceeded
+	30023: port.CategoryQuotaExceeded,    // US A2P 10DLC daily cap reached
+	30027: port.CategoryQuotaExceeded,    // US A2P 10DLC T-Mobile daily cap reached
+	20500: port.CategoryTransient,        // internal server error
+	20503: port.CategoryTransient,        // service unavailable
+	30008: port.CategoryTransient,        // unknown error
+	30500: port.CategoryTransient,        // internal failure
+}
+
+func classifyTwilioError(status int, header http.Header, apiErr twilioError) *port.ProviderError {
+	category, ok := twilioCodeCategories[apiErr.Code]
+	if !ok {
+		category = statusCategory(status)
+	}
+	err := providerError(category, strconv.Itoa(apiErr.Code), "twilio status %d code %d: %s", status, apiErr.Code, apiErr.Message)
+	err.RetryAfter = retryAfter(header, time.Now())
+	return err
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+type twilioMock struct {
+	*httptest.Server
+	status int
+	body   string
+	path   string
+	user   string
+	pass   string
+	form   url.Values
+	header http.Header
+}
+
+func newTwilioMock(t *testing.T) *twilioMock {
+	m := &twilioMock{
+		status: http.StatusCreated,
+		body:   `{"sid":"SM123","status":"queued"}`,
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.path = r.URL.Path
+		m.user, m.pass, _ = r.BasicAuth()
+		require.NoError(t, r.ParseForm())
+		m.form = r.PostForm
+
+		for k, v := range m.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(m.status)
+		_, _ = w.Write([]byte(m.body))
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func newSMS() *domain.Notification {
+	return &domain.Notification{Channel: domain.ChannelSMS, Recipient: "+905551234567", Content: "Your code is 1234"}
+}
+
+func TestTwilioProvider_PostsFormWithStatusCallback(t *testing.T) {
+	mock := newTwilioMock(t)
+	p := NewTwilioProvider(TwilioConfig{
+		AccountSID:        "AC123",
+		AuthToken:         "token",
+		From:              "+15550001111",
+		StatusCallbackURL: "https://api.example.com/api/v1/providers/twilio/receipts",
+		BaseURL:           mock.URL,
+	})
+
+	resp, err := p.Send(context.Background(), newSMS())
+	require.NoError(t, err)
+
+	assert.Equal(t, "SM123", resp.MessageID)
+	assert.Equal(t, "queued", resp.Status)
+	assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", mock.path)
+	assert.Equal(t, "AC123", mock.user)
+	assert.Equal(t, "token", mock.pass)
+	assert.Equal(t, "+905551234567", mock.form.Get("To"))
+	assert.Equal(t, "+15550001111", mock.form.Get("From"))
+	assert.Equal(t, "Your code is 1234", mock.form.Get("Body"))
+	assert.Equal(t, "https://api.example.com/api/v1/providers/twilio/receipts", mock.form.Get("StatusCallback"))
+}
+
+func TestTwilioProvider_PrefersMessagingService(t *testing.T) {
+	mock := newTwilioMock(t)
+	p := NewTwilioProvider(TwilioConfig{
+		AccountSID:          "AC123",
+		From:                "+15550001111",
+		MessagingServiceSID: "MG123",
+		BaseURL:             mock.URL,
+	})
+
+	_, err := p.Send(context.Background(), newSMS())
+	require.NoError(t, err)
+
+	assert.Equal(t, "MG123", mock.form.Get("MessagingServiceSid"))
+	assert.Empty(t, mock.form.Get("From"))
+	assert.Empty(t, mock.form.Get("StatusCallback"))
+}
+
+func TestTwilioProvider_HonoursRetryAfter(t *testing.T) {
+	mock := newTwilioMock(t)
+	mock.status = http.StatusTooManyRequests
+	mock.body = `{"code":20429,"message":"Too Many Requests","status":429}`
+	mock.header = http.Header{"Retry-After": {"30"}}
+	p := NewTwilioProvider(TwilioConfig{AccountSID: "AC123", From: "+15550001111", BaseURL: mock.URL})
+
+	_, err := p.Send(context.Background(), newSMS())
+
+	var providerErr *port.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, "20429", providerErr.Code)
+	assert.Equal(t, 30*time.Second, providerErr.RetryAfter)
+	assert.Equal(t, 30*time.Second, providerErr.MinRetryDelay())
+}
+
+func TestTwilioProvider_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		category port.ErrorCategory
+	}{
+		{"invalid number", http.StatusBadRequest, `{"code":21211,"message":"Invalid 'To' Phone Number","status":400}`, port.CategoryInvalidRecipient},
+		{"unsubscribed", http.StatusBadRequest, `{"code":21610,"message":"Attempt to send to unsubscribed recipient","status":400}`, port.CategoryInvalidRecipient},
+		{"too long", http.StatusBadRequest, `{"code":21617,"message":"The concatenated message body exceeds the 1600 character limit","status":400}`, port.CategoryContentRejected},
+		{"auth", http.StatusUnauthorized, `{"code":20003,"message":"Authenticate","status":401}`, port.CategoryAuthFailure},
+		{"rate limited", http.StatusTooManyRequests, `{"code":20429,"message":"Too Many Requests","status":429}`, port.CategoryRateLimited},
+		{"queue overflow", http.StatusBadRequest, `{"code":30001,"message":"Queue overflow","status":400}`, port.CategoryRateLimited},
+		{"daily cap", http.StatusBadRequest, `{"code":30023,"message":"Daily message cap reached","status":400}`, port.CategoryQuotaExceeded},
+		{"server error", http.StatusServiceUnavailable, `{"code":20503,"message":"Service unavailable","status":503}`, port.CategoryTransient},
+		{"unknown code", http.StatusBadRequest, `{"code":21999,"message":"Something else","status":400}`, port.CategoryContentRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newTwilioMock(t)
+			mock.status = tt.status
+			mock.body = tt.body
+			p := NewTwilioProvider(TwilioConfig{AccountSID: "AC123", From: "+15550001111", BaseURL: mock.URL})
+
+			_, err := p.Send(context.Background(), newSMS())
+
+			require.Error(t, err)
+			assert.Equal(t, tt.category, port.Classify(err))
+			assert.Equal(t, tt.category.Policy().Retry, errors.Is(err, domain.ErrProviderUnavailable))
+		})
+	}
+}
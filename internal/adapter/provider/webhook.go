@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,33 +17,64 @@ import (
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 	"github.com/mehmetymw/event-driven-ns/internal/port"
-	"github.com/mehmetymw/event-driven-ns/pkg/circuitbreaker"
 	"github.com/mehmetymw/event-driven-ns/pkg/logger"
 	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+	"github.com/mehmetymw/event-driven-ns/pkg/webhooksig"
 )
 
-type WebhookProvider struct {
-	webhookURL string
+// defaultTransientStatuses are retried when an endpoint doesn't list its own.
+var defaultTransientStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// WebhookEndpoint is where one channel's notifications are posted. Secrets
+// sign each request, current secret first and, during a rotation, the one
+// it replaces second. Responses with a status in TransientStatuses are
+// retried; other statuses of 400 or above are classified by status.
+type WebhookEndpoint struct {
+	URL               string
+	Headers           map[string]string
+	Auth              WebhookAuth
+	Secrets           []string
+	Timeout           time.Duration
+	TransientStatuses []int
+}
+
+type webhookEndpoint struct {
+	WebhookEndpoint
 	httpClient *http.Client
-	breakers   map[domain.Channel]*circuitbreaker.Breaker
 }
 
-func NewWebhookProvider(webhookURL string) *WebhookProvider {
-	return &WebhookProvider{
-		webhookURL: webhookURL,
-		httpClient: &http.Client{
-			Timeout:   5 * time.Second,
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-		},
-		breakers: map[domain.Channel]*circuitbreaker.Breaker{
-			domain.ChannelSMS:   circuitbreaker.New("sms"),
-			domain.ChannelEmail: circuitbreaker.New("email"),
-			domain.ChannelPush:  circuitbreaker.New("push"),
-		},
+type WebhookProvider struct {
+	endpoints map[domain.Channel]*webhookEndpoint
+}
+
+func NewWebhookProvider(endpoints map[domain.Channel]WebhookEndpoint) *WebhookProvider {
+	p := &WebhookProvider{endpoints: make(map[domain.Channel]*webhookEndpoint, len(endpoints))}
+	for ch, ep := range endpoints {
+		if ep.Timeout <= 0 {
+			ep.Timeout = 5 * time.Second
+		}
+		if len(ep.TransientStatuses) == 0 {
+			ep.TransientStatuses = defaultTransientStatuses
+		}
+		p.endpoints[ch] = &webhookEndpoint{
+			WebhookEndpoint: ep,
+			httpClient: &http.Client{
+				Timeout:   ep.Timeout,
+				Transport: otelhttp.NewTransport(http.DefaultTransport),
+			},
+		}
 	}
+	return p
 }
 
 type webhookRequest struct {
+	ID      string `json:"id"`
 	To      string `json:"to"`
 	Channel string `json:"channel"`
 	Content string `json:"content"`
@@ -53,34 +86,29 @@ type webhookResponse struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// Send posts the notification to its channel's endpoint, signed with the
+// endpoint's secrets (see pkg/webhooksig). The notification ID in the body
+// lets receivers drop replays inside the signature's tolerance window.
+// Circuit breaking is left to the Router that wraps it.
 func (p *WebhookProvider) Send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
-	breaker, ok := p.breakers[n.Channel]
-	if !ok {
-		breaker = circuitbreaker.New(string(n.Channel))
-		p.breakers[n.Channel] = breaker
-	}
+	ctx, span := tracing.Tracer().Start(ctx, "webhook.send")
+	defer span.End()
 
-	result, err := breaker.Execute(func() (any, error) {
-		return p.doSend(ctx, n)
-	})
-	if err != nil {
+	ep, ok := p.endpoints[n.Channel]
+	if !ok {
+		err := providerError(port.CategoryPermanent, "", "no webhook endpoint for channel %s", n.Channel)
+		tracing.RecordError(span, err)
 		return nil, err
 	}
 
-	return result.(*port.ProviderResponse), nil
-}
-
-func (p *WebhookProvider) doSend(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
-	ctx, span := tracing.Tracer().Start(ctx, "webhook.send")
-	defer span.End()
-
 	span.SetAttributes(
-		attribute.String("webhook.url", p.webhookURL),
+		attribute.String("webhook.url", ep.URL),
 		attribute.String("notification.channel", string(n.Channel)),
 		attribute.String("notification.recipient", n.Recipient),
 	)
 
 	reqBody := webhookRequest{
+		ID:      n.ID.String(),
 		To:      n.Recipient,
 		Channel: string(n.Channel),
 		Content: n.Content,
@@ -92,12 +120,15 @@ func (p *WebhookProvider) doSend(ctx context.Context, n *domain.Notification) (*
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
 	if err != nil {
 		tracing.RecordError(span, err)
 		return nil, err
 	}
 
+	for name, value := range ep.Headers {
+		req.Header.Set(name, value)
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	correlationID := logger.CorrelationIDFromContext(ctx)
@@ -105,7 +136,23 @@ func (p *WebhookProvider) doSend(ctx context.Context, n *domain.Notification) (*
 		req.Header.Set("X-Correlation-ID", correlationID)
 	}
 
-	resp, err := p.httpClient.Do(req)
+	if ep.Auth != nil {
+		if err := ep.Auth.Authorize(ctx, req); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+
+	if len(ep.Secrets) > 0 {
+		if err := webhooksig.SignRequest(req.Header, ep.Secrets, body, time.Now()); err != nil {
+			sendErr := providerError(port.CategoryPermanent, "", "sign webhook: %v", err)
+			tracing.RecordError(span, sendErr)
+			return nil, sendErr
+		}
+		span.SetAttributes(attribute.Int("webhook.signatures", len(ep.Secrets)))
+	}
+
+	resp, err := ep.httpClient.Do(req)
 	if err != nil {
 		tracing.RecordError(span, err)
 		return nil, fmt.Errorf("%w: %v", domain.ErrProviderUnavailable, err)
@@ -120,16 +167,10 @@ func (p *WebhookProvider) doSend(ctx context.Context, n *domain.Notification) (*
 		return nil, err
 	}
 
-	if isTransientError(resp.StatusCode) {
-		transientErr := fmt.Errorf("%w: status %d", domain.ErrProviderUnavailable, resp.StatusCode)
-		tracing.RecordError(span, transientErr)
-		return nil, transientErr
-	}
-
 	if resp.StatusCode >= 400 {
-		permErr := fmt.Errorf("permanent provider error: status %d, body: %s", resp.StatusCode, string(respBody))
-		tracing.RecordError(span, permErr)
-		return nil, permErr
+		sendErr := webhookError(ep, req, resp, respBody)
+		tracing.RecordError(span, sendErr)
+		return nil, sendErr
 	}
 
 	var webhookResp webhookResponse
@@ -150,15 +191,33 @@ func (p *WebhookProvider) doSend(ctx context.Context, n *domain.Notification) (*
 	}, nil
 }
 
-func isTransientError(statusCode int) bool {
-	switch statusCode {
-	case http.StatusTooManyRequests,
-		http.StatusInternalServerError,
-		http.StatusBadGateway,
-		http.StatusServiceUnavailable,
-		http.StatusGatewayTimeout:
-		return true
+// webhookError classifies a webhook rejection. Statuses the endpoint lists as
+// transient are retried (rate limited for 429), honouring Retry-After. On an
+// auth failure the endpoint's auth drops any cached credentials, so the retry
+// fetches fresh ones.
+func webhookError(ep *webhookEndpoint, req *http.Request, resp *http.Response, body []byte) *port.ProviderError {
+	status := resp.StatusCode
+	code := strconv.Itoa(status)
+
+	var category port.ErrorCategory
+	switch {
+	case slices.Contains(ep.TransientStatuses, status) && status == http.StatusTooManyRequests:
+		category = port.CategoryRateLimited
+	case slices.Contains(ep.TransientStatuses, status):
+		category = port.CategoryTransient
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		if ep.Auth != nil {
+			ep.Auth.Rejected(req)
+		}
+		category = port.CategoryAuthFailure
+	case status == http.StatusTooManyRequests, status >= http.StatusInternalServerError:
+		// The endpoint chose not to list it as transient.
+		category = port.CategoryPermanent
 	default:
-		return false
+		category = statusCategory(status)
 	}
+
+	err := providerError(category, code, "status %d, body: %s", status, body)
+	err.RetryAfter = retryAfter(resp.Header, time.Now())
+	return err
 }
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// WebhookAuth authorizes a webhook request. Rejected is called when the
+// endpoint refuses the credentials Authorize set, so cached ones can be
+// dropped.
+type WebhookAuth interface {
+	Authorize(ctx context.Context, req *http.Request) error
+	Rejected(req *http.Request)
+}
+
+type bearerAuth struct{ token string }
+
+func NewBearerAuth(token string) WebhookAuth {
+	return bearerAuth{token: token}
+}
+
+func (a bearerAuth) Authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (bearerAuth) Rejected(*http.Request) {}
+
+type basicAuth struct{ username, password string }
+
+func NewBasicAuth(username, password string) WebhookAuth {
+	return basicAuth{username: username, password: password}
+}
+
+func (a basicAuth) Authorize(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (basicAuth) Rejected(*http.Request) {}
+
+// OAuth2Config is an OAuth2 client credentials grant.
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type oauth2Auth struct {
+	cfg        OAuth2Config
+	httpClient *http.Client
+	tokens     *tokenCache
+}
+
+// NewOAuth2Auth fetches access tokens with the client credentials grant and
+// caches them until shortly before they expire.
+func NewOAuth2Auth(cfg OAuth2Config) WebhookAuth {
+	a := &oauth2Auth{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+	a.tokens = newTokenCache(a.fetchToken)
+	return a
+}
+
+func (a *oauth2Auth) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := a.tokens.get(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *oauth2Auth) Rejected(req *http.Request) {
+	a.tokens.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+}
+
+func (a *oauth2Auth) fetchToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.cfg.ClientID), url.QueryEscape(a.cfg.ClientSecret))
+
+	now := time.Now()
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: oauth2 token: %v", domain.ErrProviderUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", time.Time{}, tokenError("oauth2", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("%w: oauth2 token: unreadable response", domain.ErrProviderUnavailable)
+	}
+
+	expiresIn := time.Duration(token.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	return token.AccessToken, now.Add(expiresIn), nil
+}
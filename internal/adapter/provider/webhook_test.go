@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/pkg/webhooksig"
+)
+
+func TestWebhookProvider_SignsWithChannelSecrets(t *testing.T) {
+	var verifyErr error
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, verifyErr = webhooksig.VerifyRequest(r, []string{"sms-old"}, 0)
+		_, _ = io.WriteString(w, `{"messageId":"m-1","status":"accepted"}`)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookProvider(map[domain.Channel]WebhookEndpoint{
+		domain.ChannelSMS:   {URL: srv.URL, Secrets: []string{"sms-new", "sms-old"}},
+		domain.ChannelEmail: {URL: srv.URL},
+	})
+
+	_, err := p.Send(context.Background(), newSMS())
+	require.NoError(t, err)
+	assert.NoError(t, verifyErr)
+	assert.NotEmpty(t, header.Get(webhooksig.TimestampHeader))
+
+	_, err = p.Send(context.Background(), newEmail("hi"))
+	require.NoError(t, err)
+	assert.Empty(t, header.Get(webhooksig.SignatureHeader))
+}
+
+func TestWebhookProvider_PerChannelEndpoints(t *testing.T) {
+	var smsHeader, emailHeader http.Header
+	sms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		smsHeader = r.Header.Clone()
+	}))
+	defer sms.Close()
+	email := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		emailHeader = r.Header.Clone()
+	}))
+	defer email.Close()
+
+	p := NewWebhookProvider(map[domain.Channel]WebhookEndpoint{
+		domain.ChannelSMS: {
+			URL:     sms.URL,
+			Headers: map[string]string{"X-Tenant": "acme"},
+			Auth:    NewBearerAuth("sms-token"),
+		},
+		domain.ChannelEmail: {
+			URL:  email.URL,
+			Auth: NewBasicAuth("mailer", "secret"),
+		},
+	})
+
+	_, err := p.Send(context.Background(), newSMS())
+	require.NoError(t, err)
+	_, err = p.Send(context.Background(), newEmail("hi"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", smsHeader.Get("X-Tenant"))
+	assert.Equal(t, "Bearer sms-token", smsHeader.Get("Authorization"))
+	assert.Empty(t, emailHeader.Get("X-Tenant"))
+	user, pass, ok := (&http.Request{Header: emailHeader}).BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, "mailer", user)
+	assert.Equal(t, "secret", pass)
+
+	_, err = p.Send(context.Background(), &domain.Notification{Channel: domain.ChannelPush, Recipient: "tok", Content: "hi"})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, domain.ErrProviderUnavailable))
+}
+
+func TestWebhookProvider_TransientStatuses(t *testing.T) {
+	status := http.StatusConflict
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookProvider(map[domain.Channel]WebhookEndpoint{
+		domain.ChannelSMS: {URL: srv.URL, TransientStatuses: []int{http.StatusConflict}},
+	})
+
+	_, err := p.Send(context.Background(), newSMS())
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+
+	status = http.StatusServiceUnavailable
+	_, err = p.Send(context.Background(), newSMS())
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, domain.ErrProviderUnavailable))
+}
+
+func TestWebhookProvider_OAuth2RefreshesRejectedToken(t *testing.T) {
+	var issued atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "notify", r.PostForm.Get("scope"))
+		id, secret, _ := r.BasicAuth()
+		assert.Equal(t, "client", id)
+		assert.Equal(t, "client-secret", secret)
+
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, n)
+	}))
+	defer tokenSrv.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewWebhookProvider(map[domain.Channel]WebhookEndpoint{
+		domain.ChannelSMS: {
+			URL: srv.URL,
+			Auth: NewOAuth2Auth(OAuth2Config{
+				TokenURL:     tokenSrv.URL,
+				ClientID:     "client",
+				ClientSecret: "client-secret",
+				Scopes:       []string{"notify"},
+			}),
+		},
+	})
+
+	_, err := p.Send(context.Background(), newSMS())
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+
+	_, err = p.Send(context.Background(), newSMS())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), issued.Load())
+}
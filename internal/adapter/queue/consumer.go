@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math"
 	"math/rand/v2"
 	"sync"
@@ -12,10 +13,10 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
 	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
 )
 
@@ -23,7 +24,24 @@ type ConsumerConfig struct {
 	Brokers        []string
 	Group          string
 	RatePerChannel int
+	Concurrency    int
 	Logger         *zap.Logger
+
+	// RateStore, when set, holds the channel rate limits so they apply across
+	// every worker instead of to each one.
+	RateStore ratelimit.Store
+
+	// Ordered processes messages that share a Kafka key one at a time and in
+	// fetch order, including across retries. Pair it with an ordered Producer.
+	Ordered bool
+
+	// PriorityWeights sets each priority's share of worker picks when several
+	// lanes have work. PriorityAging is how long a buffered message may wait
+	// before it is served ahead of the weights. HighReservePercent is the
+	// slice of each channel's rate limit that only high priority may use.
+	PriorityWeights    map[domain.Priority]int
+	PriorityAging      time.Duration
+	HighReservePercent int
 }
 
 var priorityTopics = []string{
@@ -32,21 +50,35 @@ var priorityTopics = []string{
 	"notifications.low",
 }
 
+// messageWriter is the part of *kafka.Writer the consumer uses.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
 type Consumer struct {
-	cfg      ConsumerConfig
-	readers  []*kafka.Reader
-	writer   *kafka.Writer
-	limiters map[string]*rate.Limiter
-	logger   *zap.Logger
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
+	cfg        ConsumerConfig
+	readers    []*kafka.Reader
+	writer     messageWriter
+	limiters   ChannelLimiters
+	dispatcher *dispatcher
+	logger     *zap.Logger
+	// writeBackoff is the wait between attempts to write a retry or dead
+	// letter message.
+	writeBackoff time.Duration
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
 }
 
 func NewConsumer(cfg ConsumerConfig) *Consumer {
-	limiters := map[string]*rate.Limiter{
-		string(domain.ChannelSMS):   rate.NewLimiter(rate.Limit(cfg.RatePerChannel), cfg.RatePerChannel),
-		string(domain.ChannelEmail): rate.NewLimiter(rate.Limit(cfg.RatePerChannel), cfg.RatePerChannel),
-		string(domain.ChannelPush):  rate.NewLimiter(rate.Limit(cfg.RatePerChannel), cfg.RatePerChannel),
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PriorityWeights == nil {
+		cfg.PriorityWeights = defaultPriorityWeights
+	}
+	if cfg.PriorityAging <= 0 {
+		cfg.PriorityAging = defaultPriorityAging
 	}
 
 	writer := &kafka.Writer{
@@ -56,10 +88,12 @@ func NewConsumer(cfg ConsumerConfig) *Consumer {
 	}
 
 	return &Consumer{
-		cfg:      cfg,
-		writer:   writer,
-		limiters: limiters,
-		logger:   cfg.Logger,
+		cfg:          cfg,
+		writer:       writer,
+		limiters:     NewChannelLimiters(cfg.RatePerChannel, cfg.HighReservePercent, cfg.RateStore),
+		dispatcher:   newDispatcher(cfg.Concurrency, cfg.PriorityWeights, cfg.PriorityAging, cfg.Ordered),
+		logger:       cfg.Logger,
+		writeBackoff: time.Second,
 	}
 }
 
@@ -68,6 +102,7 @@ func (c *Consumer) Start(ctx context.Context, handler port.MessageHandler) error
 	c.cancel = cancel
 
 	for _, topic := range priorityTopics {
+		priority := priorityForTopic[topic]
 		reader := kafka.NewReader(kafka.ReaderConfig{
 			Brokers:        c.cfg.Brokers,
 			Topic:          topic,
@@ -79,14 +114,23 @@ func (c *Consumer) Start(ctx context.Context, handler port.MessageHandler) error
 		})
 		c.readers = append(c.readers, reader)
 
+		l := c.dispatcher.addLane(priority, c.cfg.Concurrency)
+		l.reader = reader
+
 		c.wg.Add(1)
-		go c.consume(ctx, reader, handler)
+		go c.fetch(ctx, l)
+	}
+
+	for range c.cfg.Concurrency {
+		c.wg.Add(1)
+		go c.work(ctx, handler)
 	}
 
 	c.logger.Info("kafka consumer started",
 		zap.Strings("brokers", c.cfg.Brokers),
 		zap.String("group", c.cfg.Group),
 		zap.Int("topic_count", len(priorityTopics)),
+		zap.Int("concurrency", c.cfg.Concurrency),
 	)
 
 	<-ctx.Done()
@@ -99,6 +143,15 @@ func (c *Consumer) Stop(_ context.Context) error {
 	}
 	c.wg.Wait()
 
+	for _, l := range c.dispatcher.lanes {
+		if n := l.tracker.inFlight(); n > 0 {
+			c.logger.Info("leaving offsets uncommitted for redelivery",
+				zap.String("priority", string(l.priority)),
+				zap.Int("messages", n),
+			)
+		}
+	}
+
 	var firstErr error
 	for _, r := range c.readers {
 		if err := r.Close(); err != nil && firstErr == nil {
@@ -111,14 +164,20 @@ func (c *Consumer) Stop(_ context.Context) error {
 	return firstErr
 }
 
-func (c *Consumer) consume(ctx context.Context, reader *kafka.Reader, handler port.MessageHandler) {
+// fetch reads one priority topic into its dispatcher lane. push blocks while
+// the lane is full, which keeps fetching in step with the worker pool.
+func (c *Consumer) fetch(ctx context.Context, l *lane) {
 	defer c.wg.Done()
 
-	topic := reader.Config().Topic
-	c.logger.Info("consumer goroutine started", zap.String("topic", topic))
+	topic := l.reader.Config().Topic
+	c.logger.Info("consumer goroutine started",
+		zap.String("topic", topic),
+		zap.String("priority", string(l.priority)),
+		zap.Int("weight", c.cfg.PriorityWeights[l.priority]),
+	)
 
 	for {
-		msg, err := reader.FetchMessage(ctx)
+		msg, err := l.reader.FetchMessage(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
@@ -131,83 +190,165 @@ func (c *Consumer) consume(ctx context.Context, reader *kafka.Reader, handler po
 			continue
 		}
 
-		var payload NotificationPayload
-		if err := json.Unmarshal(msg.Value, &payload); err != nil {
-			c.logger.Error("unmarshal payload failed",
-				zap.String("topic", topic),
-				zap.Error(err),
-			)
-			_ = reader.CommitMessages(ctx, msg)
-			continue
+		l.tracker.track(msg)
+		if !c.dispatcher.push(ctx, l, msg) {
+			return
 		}
+	}
+}
 
-		msgCtx := ctx
-		if len(payload.Carrier) > 0 {
-			msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(payload.Carrier))
-		}
+// work takes messages from the dispatcher and commits their offsets through
+// the lane's tracker, so a slow message holds back commits on its partition
+// instead of being skipped.
+func (c *Consumer) work(ctx context.Context, handler port.MessageHandler) {
+	defer c.wg.Done()
 
-		msgCtx, span := tracing.Tracer().Start(msgCtx, "kafka.consume")
-		span.SetAttributes(
-			attribute.String("messaging.system", "kafka"),
-			attribute.String("messaging.source.name", msg.Topic),
-			attribute.String("messaging.operation.type", "receive"),
-			attribute.String("messaging.consumer.group.id", c.cfg.Group),
-			attribute.String("notification.id", payload.NotificationID),
-			attribute.String("notification.channel", payload.Channel),
-			attribute.Int64("messaging.kafka.message.offset", msg.Offset),
-			attribute.Int("messaging.kafka.destination.partition", msg.Partition),
-		)
+	commitCtx := context.WithoutCancel(ctx)
 
-		if limiter, ok := c.limiters[payload.Channel]; ok {
-			_ = limiter.Wait(msgCtx)
+	for {
+		j, ok := c.dispatcher.pop(ctx)
+		if !ok || ctx.Err() != nil {
+			return
 		}
 
-		c.logger.Info("processing notification",
-			zap.String("notification_id", payload.NotificationID),
-			zap.String("topic", msg.Topic),
-			zap.Int64("offset", msg.Offset),
-		)
-
-		if err := handler(msgCtx, payload.NotificationID); err != nil {
-			span.SetAttributes(attribute.Bool("delivery.will_retry", true))
-			tracing.RecordError(span, err)
-			span.End()
-			c.retry(ctx, msg, payload)
-			_ = reader.CommitMessages(ctx, msg)
+		again := c.handle(ctx, j.msg, j.lane.priority, handler)
+		if ctx.Err() != nil {
+			// Leave the offset uncommitted; the message is redelivered and the
+			// delivery claim skips it if it already went out.
+			return
+		}
+		if again != nil {
+			j.msg = *again
+			c.dispatcher.retryLater(ctx, j, retryDue(j.msg))
 			continue
 		}
-
-		span.End()
-		_ = reader.CommitMessages(ctx, msg)
+		c.dispatcher.done(j)
+		j.lane.tracker.complete(j.msg, func(msg kafka.Message) {
+			if err := j.lane.reader.CommitMessages(commitCtx, msg); err != nil {
+				c.logger.Error("commit offset failed",
+					zap.String("topic", msg.Topic),
+					zap.Int("partition", msg.Partition),
+					zap.Int64("offset", msg.Offset),
+					zap.Error(err),
+				)
+			}
+		})
 	}
 }
 
-func (c *Consumer) retry(ctx context.Context, original kafka.Message, payload NotificationPayload) {
-	delay := retryDelay(payload.NotificationID)
-	time.Sleep(delay)
+// handle delivers msg. An ordered message that failed but may still succeed
+// comes back, its headers carrying the attempt, for the caller to run again
+// once due; every other outcome is settled here.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message, priority domain.Priority, handler port.MessageHandler) *kafka.Message {
+	var payload NotificationPayload
+	if err := json.Unmarshal(msg.Value, &payload); err != nil {
+		c.logger.Error("unmarshal payload failed",
+			zap.String("topic", msg.Topic),
+			zap.Error(err),
+		)
+		c.deadLetter(ctx, msg, err)
+		return nil
+	}
+
+	msgCtx := ctx
+	if len(payload.Carrier) > 0 {
+		msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(payload.Carrier))
+	}
 
-	if err := c.writer.WriteMessages(ctx, kafka.Message{
-		Topic: original.Topic,
-		Key:   original.Key,
-		Value: original.Value,
-	}); err != nil {
-		c.logger.Error("retry re-enqueue failed",
+	msgCtx, span := tracing.Tracer().Start(msgCtx, "kafka.consume")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.source.name", msg.Topic),
+		attribute.String("messaging.operation.type", "receive"),
+		attribute.String("messaging.consumer.group.id", c.cfg.Group),
+		attribute.String("notification.id", payload.NotificationID),
+		attribute.String("notification.channel", payload.Channel),
+		attribute.String("notification.priority", string(priority)),
+		attribute.Int64("messaging.kafka.message.offset", msg.Offset),
+		attribute.Int("messaging.kafka.destination.partition", msg.Partition),
+	)
+
+	_ = c.limiters.Wait(msgCtx, payload.Channel, priority)
+
+	c.logger.Info("processing notification",
+		zap.String("notification_id", payload.NotificationID),
+		zap.String("topic", msg.Topic),
+		zap.Int64("offset", msg.Offset),
+	)
+
+	err := handler(msgCtx, payload.NotificationID)
+
+	if errors.Is(err, domain.ErrDeliveryFailed) {
+		span.SetAttributes(attribute.Bool("delivery.dead_lettered", true))
+		c.deadLetter(ctx, msg, err)
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	if retriesExhausted(msg) {
+		span.SetAttributes(attribute.Bool("delivery.dead_lettered", true))
+		tracing.RecordError(span, err)
+		c.deadLetter(ctx, msg, err)
+		return nil
+	}
+
+	span.SetAttributes(
+		attribute.Bool("delivery.will_retry", true),
+		attribute.Int("delivery.attempt", messageAttempt(msg)+1),
+	)
+	tracing.RecordError(span, err)
+
+	// An ordered message keeps its key until it succeeds or is dead-lettered,
+	// so it waits out its backoff in the dispatcher: parking it on a retry
+	// tier would let the next message for the same key overtake it.
+	if c.ordered(msg) {
+		msg.Headers = retryMessage(msg, err, time.Now()).Headers
+		c.logger.Info("ordered notification scheduled for retry",
 			zap.String("notification_id", payload.NotificationID),
-			zap.Error(err),
+			zap.Int("attempt", messageAttempt(msg)),
+			zap.Time("due", retryDue(msg)),
 		)
+		return &msg
 	}
+
+	c.retry(ctx, msg, payload, err)
+	return nil
 }
 
-func retryDelay(id string) time.Duration {
-	baseDelay := 2 * time.Second
-	maxDelay := 30 * time.Second
-	jitter := time.Duration(rand.Int64N(1000)) * time.Millisecond
+func (c *Consumer) ordered(msg kafka.Message) bool {
+	return c.cfg.Ordered && len(msg.Key) > 0
+}
 
-	delay := baseDelay + jitter
-	if delay > maxDelay {
-		delay = maxDelay
+// retry parks the message on a retry tier instead of sleeping, so the worker
+// is free for the next message as soon as the write is acknowledged.
+func (c *Consumer) retry(ctx context.Context, original kafka.Message, payload NotificationPayload, cause error) {
+	retryMsg := retryMessage(original, cause, time.Now())
+
+	if !c.write(ctx, retryMsg) {
+		return
 	}
-	return delay
+
+	c.logger.Info("notification scheduled for retry",
+		zap.String("notification_id", payload.NotificationID),
+		zap.String("retry_topic", retryMsg.Topic),
+		zap.Int("attempt", messageAttempt(retryMsg)),
+	)
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, original kafka.Message, cause error) {
+	if !c.write(ctx, deadLetterMessage(original, cause, time.Now())) {
+		return
+	}
+
+	c.logger.Warn("message dead-lettered",
+		zap.String("topic", original.Topic),
+		zap.Int("partition", original.Partition),
+		zap.Int64("offset", original.Offset),
+		zap.Error(cause),
+	)
 }
 
 func RetryDelayForAttempt(attempt int) time.Duration {
@@ -223,3 +364,39 @@ func RetryDelayForAttempt(attempt int) time.Duration {
 	}
 	return delay
 }
+
+// RetryDelay stretches an attempt's backoff to the wait the send error asks
+// for: the floor of its category, or the provider's Retry-After.
+func RetryDelay(backoff time.Duration, cause error) time.Duration {
+	var providerErr *port.ProviderError
+	if errors.As(cause, &providerErr) {
+		return max(backoff, providerErr.MinRetryDelay())
+	}
+	return backoff
+}
+
+// write keeps writing msg until it is acknowledged, as the retry consumer
+// republishes, so the offset of the message it replaces is never committed
+// past a write that failed. It returns false only when ctx is cancelled,
+// which leaves that offset uncommitted for redelivery.
+func (c *Consumer) write(ctx context.Context, msg kafka.Message) bool {
+	for {
+		err := c.writer.WriteMessages(ctx, msg)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		c.logger.Error("write failed, retrying",
+			zap.String("topic", msg.Topic),
+			zap.Error(err),
+		)
+
+		select {
+		case <-time.After(c.writeBackoff):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+const deadLetterTopic = "notifications.dlq"
+
+const (
+	headerDeadLetterID    = "x-dead-letter-id"
+	headerOriginPartition = "x-origin-partition"
+	headerOriginOffset    = "x-origin-offset"
+	headerError           = "x-error"
+)
+
+// deadLetterMessage wraps original for the dead-letter topic. The value is
+// copied verbatim; everything known about the failure travels in headers.
+func deadLetterMessage(original kafka.Message, cause error, now time.Time) kafka.Message {
+	attempt := messageAttempt(original) + 1
+
+	return kafka.Message{
+		Topic: deadLetterTopic,
+		Key:   original.Key,
+		Value: original.Value,
+		Headers: []kafka.Header{
+			{Key: headerDeadLetterID, Value: []byte(uuid.Must(uuid.NewV7()).String())},
+			{Key: headerOriginTopic, Value: []byte(original.Topic)},
+			{Key: headerOriginPartition, Value: []byte(strconv.Itoa(original.Partition))},
+			{Key: headerOriginOffset, Value: []byte(strconv.FormatInt(original.Offset, 10))},
+			{Key: headerError, Value: []byte(cause.Error())},
+			{Key: headerAttempt, Value: []byte(strconv.Itoa(attempt))},
+			{Key: headerHistory, Value: withAttempt(original, attempt, cause, now)},
+		},
+	}
+}
+
+func decodeDeadLetter(msg kafka.Message) *domain.DeadLetter {
+	id, err := uuid.Parse(headerValue(msg, headerDeadLetterID))
+	if err != nil {
+		// Written by something other than the consumer; derive a stable ID so
+		// redelivery of the same message is still stored once.
+		id = uuid.NewSHA1(uuid.NameSpaceOID, []byte(msg.Topic+"/"+strconv.Itoa(msg.Partition)+"/"+strconv.FormatInt(msg.Offset, 10)))
+	}
+
+	partition, _ := strconv.Atoi(headerValue(msg, headerOriginPartition))
+	offset, _ := strconv.ParseInt(headerValue(msg, headerOriginOffset), 10, 64)
+
+	d := &domain.DeadLetter{
+		ID:        id,
+		Topic:     originTopic(msg),
+		Partition: partition,
+		Offset:    offset,
+		Payload:   msg.Value,
+		Error:     headerValue(msg, headerError),
+		Attempts:  attemptHistory(msg),
+		CreatedAt: msg.Time.UTC(),
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+
+	var payload NotificationPayload
+	if err := json.Unmarshal(msg.Value, &payload); err == nil {
+		if nid, err := uuid.Parse(payload.NotificationID); err == nil {
+			d.NotificationID = &nid
+		}
+	}
+
+	return d
+}
+
+type DeadLetterConsumer struct {
+	cfg    ConsumerConfig
+	reader *kafka.Reader
+	logger *zap.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDeadLetterConsumer(cfg ConsumerConfig) *DeadLetterConsumer {
+	return &DeadLetterConsumer{
+		cfg:    cfg,
+		logger: cfg.Logger,
+	}
+}
+
+func (c *DeadLetterConsumer) Start(ctx context.Context, handler port.DeadLetterHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	c.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        c.cfg.Brokers,
+		Topic:          deadLetterTopic,
+		GroupID:        c.cfg.Group,
+		MinBytes:       1,
+		MaxBytes:       10e6,
+		CommitInterval: time.Second,
+		StartOffset:    kafka.FirstOffset,
+	})
+
+	c.wg.Add(1)
+	go c.consume(ctx, handler)
+
+	c.logger.Info("dead letter consumer started", zap.String("topic", deadLetterTopic))
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *DeadLetterConsumer) Stop(_ context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	if c.reader == nil {
+		return nil
+	}
+	return c.reader.Close()
+}
+
+func (c *DeadLetterConsumer) consume(ctx context.Context, handler port.DeadLetterHandler) {
+	defer c.wg.Done()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("fetch dead letter failed", zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+
+		deadLetter := decodeDeadLetter(msg)
+
+		// The DLQ is the last stop, so keep retrying the store rather than
+		// committing past an entry that was never recorded.
+		for {
+			err := handler(ctx, deadLetter)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("dead letter handler failed",
+				zap.String("dead_letter_id", deadLetter.ID.String()),
+				zap.Error(err),
+			)
+			time.Sleep(time.Second)
+		}
+
+		_ = c.reader.CommitMessages(ctx, msg)
+	}
+}
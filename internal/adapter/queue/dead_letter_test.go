@@ -0,0 +1,48 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetter_RoundTrip(t *testing.T) {
+	now := time.Now()
+	original := kafka.Message{
+		Topic:     "notifications.normal",
+		Partition: 2,
+		Offset:    41,
+		Value:     []byte(`{"notification_id":"0192f3a4-0000-7000-8000-000000000001","channel":"sms"}`),
+	}
+	retried := retryMessage(original, errors.New("timeout"), now)
+	retried.Topic = "notifications.normal"
+	retried.Partition = 2
+	retried.Offset = 97
+
+	d := decodeDeadLetter(deadLetterMessage(retried, errors.New("status 400"), now))
+
+	require.NotNil(t, d.NotificationID)
+	assert.Equal(t, "0192f3a4-0000-7000-8000-000000000001", d.NotificationID.String())
+	assert.Equal(t, "notifications.normal", d.Topic)
+	assert.Equal(t, 2, d.Partition)
+	assert.Equal(t, int64(97), d.Offset)
+	assert.Equal(t, "status 400", d.Error)
+	assert.Equal(t, original.Value, d.Payload)
+	require.Len(t, d.Attempts, 2)
+	assert.Equal(t, "timeout", d.Attempts[0].Error)
+	assert.Equal(t, 2, d.Attempts[1].Attempt)
+}
+
+func TestDeadLetter_UndecodablePayload(t *testing.T) {
+	original := kafka.Message{Topic: "notifications.high", Value: []byte("not json")}
+
+	d := decodeDeadLetter(deadLetterMessage(original, errors.New("invalid character"), time.Now()))
+
+	assert.Nil(t, d.NotificationID)
+	assert.False(t, d.CanRedrive())
+	assert.Equal(t, []byte("not json"), d.Payload)
+}
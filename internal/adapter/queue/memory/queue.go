@@ -0,0 +1,378 @@
+// Package memory is an in-process queue backend built on channels. Messages
+// live only as long as the worker process, so it suits local development and
+// tests; Postgres remains the source of truth for notification state.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+var ErrClosed = errors.New("memory queue closed")
+
+type Config struct {
+	Concurrency int
+	Buffer      int
+	RetryDelay  func(attempt int) time.Duration
+	DeadLetters port.DeadLetterHandler
+	Logger      *zap.Logger
+
+	// RatePerChannel caps each channel's sends per second; zero leaves
+	// channels unlimited. RateStore, when set, shares the caps across workers.
+	RatePerChannel int
+	RateStore      ratelimit.Store
+}
+
+type message struct {
+	notificationID string
+	channel        string
+	priority       domain.Priority
+	carrier        map[string]string
+	attempt        int
+	history        []domain.DeadLetterAttempt
+	seq            int64
+	readyAt        time.Time
+}
+
+// priorityLane buffers one priority's messages. slots bounds the buffer, so a
+// publisher blocks once it is full.
+type priorityLane struct {
+	queue []message
+	slots chan struct{}
+}
+
+type scheduledMessage struct {
+	notificationID string
+	dueAt          time.Time
+	carrier        map[string]string
+}
+
+// Queue implements port.QueuePublisher and port.QueueConsumer. Workers pick
+// between the priorities with a queue.LanePicker, the same way the Kafka
+// consumer does.
+type Queue struct {
+	cfg       Config
+	lanes     map[domain.Priority]*priorityLane
+	items     chan struct{}
+	picker    *queue.LanePicker
+	scheduled chan scheduledMessage
+	limiters  queue.ChannelLimiters
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	seq    int64
+	closed bool
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(cfg Config) *Queue {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Buffer < 1 {
+		cfg.Buffer = 1024
+	}
+	if cfg.RetryDelay == nil {
+		cfg.RetryDelay = queue.RetryDelayForAttempt
+	}
+
+	q := &Queue{
+		cfg:       cfg,
+		lanes:     make(map[domain.Priority]*priorityLane),
+		items:     make(chan struct{}, 3*cfg.Buffer),
+		picker:    queue.NewLanePicker(nil, 0),
+		scheduled: make(chan scheduledMessage, cfg.Buffer),
+		limiters:  queue.NewChannelLimiters(cfg.RatePerChannel, 0, cfg.RateStore),
+		logger:    cfg.Logger,
+	}
+	for _, p := range []domain.Priority{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow} {
+		q.lanes[p] = &priorityLane{slots: make(chan struct{}, cfg.Buffer)}
+	}
+	return q
+}
+
+func (q *Queue) Enqueue(ctx context.Context, n *domain.Notification) error {
+	lane, ok := q.lanes[n.Priority]
+	if !ok {
+		return errors.New("unknown priority: " + string(n.Priority))
+	}
+
+	return q.push(ctx, lane, message{
+		notificationID: n.ID.String(),
+		channel:        string(n.Channel),
+		priority:       n.Priority,
+		carrier:        carrier(ctx),
+	})
+}
+
+func (q *Queue) EnqueueScheduled(ctx context.Context, n *domain.Notification) error {
+	if n.ScheduledAt == nil {
+		return q.Enqueue(ctx, n)
+	}
+	if q.isClosed() {
+		return ErrClosed
+	}
+
+	select {
+	case q.scheduled <- scheduledMessage{notificationID: n.ID.String(), dueAt: *n.ScheduledAt, carrier: carrier(ctx)}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	return nil
+}
+
+func (q *Queue) Start(ctx context.Context, handler port.MessageHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Stop may run on another goroutine; it must see cancel and the workers
+	// together.
+	q.mu.Lock()
+	q.cancel = cancel
+	q.wg.Add(q.cfg.Concurrency)
+	q.mu.Unlock()
+
+	for range q.cfg.Concurrency {
+		go q.work(ctx, handler)
+	}
+
+	q.logger.Info("memory queue started", zap.Int("concurrency", q.cfg.Concurrency))
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (q *Queue) Stop(_ context.Context) error {
+	q.mu.Lock()
+	cancel := q.cancel
+	q.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	q.wg.Wait()
+	return nil
+}
+
+// Scheduled returns the consumer side of the delay channel, which feeds the
+// scheduler's timing wheel the same way the Kafka delay topic does.
+func (q *Queue) Scheduled() *ScheduledConsumer {
+	return &ScheduledConsumer{queue: q}
+}
+
+func (q *Queue) push(ctx context.Context, l *priorityLane, msg message) error {
+	if q.isClosed() {
+		return ErrClosed
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	q.seq++
+	msg.seq = q.seq
+	msg.readyAt = time.Now()
+	l.queue = append(l.queue, msg)
+	q.mu.Unlock()
+
+	q.items <- struct{}{}
+	return nil
+}
+
+func (q *Queue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *Queue) next(ctx context.Context) (message, bool) {
+	select {
+	case <-q.items:
+	case <-ctx.Done():
+		return message{}, false
+	}
+
+	q.mu.Lock()
+	oldest := make(map[domain.Priority]time.Time, len(q.lanes))
+	for p, l := range q.lanes {
+		if len(l.queue) > 0 {
+			oldest[p] = l.queue[0].readyAt
+		}
+	}
+	p, _ := q.picker.Pick(time.Now(), oldest)
+	l := q.lanes[p]
+	msg := l.queue[0]
+	l.queue = l.queue[1:]
+	q.mu.Unlock()
+
+	<-l.slots
+	return msg, true
+}
+
+func (q *Queue) work(ctx context.Context, handler port.MessageHandler) {
+	defer q.wg.Done()
+
+	for {
+		msg, ok := q.next(ctx)
+		if !ok {
+			return
+		}
+
+		msgCtx := ctx
+		if len(msg.carrier) > 0 {
+			msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(msg.carrier))
+		}
+
+		if err := q.limiters.Wait(msgCtx, msg.channel, msg.priority); err != nil {
+			return
+		}
+
+		err := handler(msgCtx, msg.notificationID)
+		switch {
+		case err == nil:
+		case errors.Is(err, domain.ErrDeliveryFailed), msg.attempt+1 >= queue.MaxAttempts:
+			q.deadLetter(ctx, msg, err)
+		default:
+			q.retry(ctx, msg, err)
+		}
+	}
+}
+
+func (q *Queue) retry(ctx context.Context, msg message, cause error) {
+	msg.history = append(msg.history, failure(msg, cause))
+	msg.attempt++
+	delay := queue.RetryDelay(q.cfg.RetryDelay(msg.attempt), cause)
+
+	q.logger.Info("notification scheduled for retry",
+		zap.String("notification_id", msg.notificationID),
+		zap.Int("attempt", msg.attempt),
+		zap.Duration("delay", delay),
+	)
+
+	time.AfterFunc(delay, func() {
+		if err := q.push(ctx, q.lanes[msg.priority], msg); err != nil {
+			q.logger.Warn("dropping retry, queue is stopping",
+				zap.String("notification_id", msg.notificationID),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+func (q *Queue) deadLetter(ctx context.Context, msg message, cause error) {
+	if q.cfg.DeadLetters == nil {
+		return
+	}
+
+	d := &domain.DeadLetter{
+		ID:        uuid.Must(uuid.NewV7()),
+		Topic:     lane(msg.priority),
+		Offset:    msg.seq,
+		Payload:   []byte(msg.notificationID),
+		Error:     cause.Error(),
+		Attempts:  append(msg.history, failure(msg, cause)),
+		CreatedAt: time.Now().UTC(),
+	}
+	if id, err := uuid.Parse(msg.notificationID); err == nil {
+		d.NotificationID = &id
+	}
+
+	if err := q.cfg.DeadLetters(ctx, d); err != nil {
+		q.logger.Error("dead letter handler failed",
+			zap.String("notification_id", msg.notificationID),
+			zap.Error(err),
+		)
+	}
+}
+
+func failure(msg message, cause error) domain.DeadLetterAttempt {
+	return domain.DeadLetterAttempt{
+		Attempt:  msg.attempt + 1,
+		Topic:    lane(msg.priority),
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+}
+
+func lane(p domain.Priority) string {
+	return "memory." + string(p)
+}
+
+func carrier(ctx context.Context) map[string]string {
+	c := make(map[string]string)
+	propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(c))
+	return c
+}
+
+type ScheduledConsumer struct {
+	queue  *Queue
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (c *ScheduledConsumer) Start(ctx context.Context, handler port.ScheduledMessageHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.cancel = cancel
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-c.queue.scheduled:
+				msgCtx := ctx
+				if len(msg.carrier) > 0 {
+					msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(msg.carrier))
+				}
+				if err := handler(msgCtx, msg.notificationID, msg.dueAt); err != nil {
+					c.queue.logger.Error("scheduled handler failed",
+						zap.String("notification_id", msg.notificationID),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *ScheduledConsumer) Stop(_ context.Context) error {
+	c.mu.Lock()
+	cancel := c.cancel
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	c.wg.Wait()
+	return nil
+}
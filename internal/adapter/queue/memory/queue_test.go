@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newTestQueue(concurrency int, deadLetters *[]*domain.DeadLetter) *Queue {
+	var mu sync.Mutex
+	return New(Config{
+		Concurrency: concurrency,
+		RetryDelay:  func(int) time.Duration { return time.Millisecond },
+		DeadLetters: func(_ context.Context, d *domain.DeadLetter) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*deadLetters = append(*deadLetters, d)
+			return nil
+		},
+		Logger: zap.NewNop(),
+	})
+}
+
+func newNotification(t *testing.T, priority domain.Priority) *domain.Notification {
+	n, err := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", priority, nil)
+	require.NoError(t, err)
+	return n
+}
+
+func TestQueue_DrainsHigherPriorityFirst(t *testing.T) {
+	var dead []*domain.DeadLetter
+	q := newTestQueue(1, &dead)
+
+	low := newNotification(t, domain.PriorityLow)
+	normal := newNotification(t, domain.PriorityNormal)
+	high := newNotification(t, domain.PriorityHigh)
+	for _, n := range []*domain.Notification{low, normal, high} {
+		require.NoError(t, q.Enqueue(context.Background(), n))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []string
+	go func() {
+		_ = q.Start(ctx, func(_ context.Context, id string) error {
+			got = append(got, id)
+			if len(got) == 3 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Equal(t, []string{high.ID.String(), normal.ID.String(), low.ID.String()}, got)
+}
+
+func TestQueue_LowPriorityGetsItsShare(t *testing.T) {
+	var dead []*domain.DeadLetter
+	q := newTestQueue(1, &dead)
+
+	for _, p := range []domain.Priority{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow} {
+		for range 10 {
+			require.NoError(t, q.Enqueue(context.Background(), newNotification(t, p)))
+		}
+	}
+	priorities := make(map[string]domain.Priority)
+	for p, l := range q.lanes {
+		for _, msg := range l.queue {
+			priorities[msg.notificationID] = p
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	counts := make(map[domain.Priority]int)
+	handled := 0
+	go func() {
+		_ = q.Start(ctx, func(_ context.Context, id string) error {
+			counts[priorities[id]]++
+			if handled++; handled == 10 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Equal(t, 6, counts[domain.PriorityHigh])
+	assert.Equal(t, 3, counts[domain.PriorityNormal])
+	assert.Equal(t, 1, counts[domain.PriorityLow], "low priority is served while high has work")
+}
+
+func TestQueue_RetriesThenDeadLetters(t *testing.T) {
+	var dead []*domain.DeadLetter
+	q := newTestQueue(2, &dead)
+
+	n := newNotification(t, domain.PriorityNormal)
+	require.NoError(t, q.Enqueue(context.Background(), n))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	calls := 0
+	go func() {
+		_ = q.Start(ctx, func(_ context.Context, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls < 3 {
+				return fmt.Errorf("%w: timeout", domain.ErrProviderUnavailable)
+			}
+			cancel()
+			return fmt.Errorf("%w: status 400", domain.ErrDeliveryFailed)
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, q.Stop(context.Background()))
+	require.False(t, errors.Is(ctx.Err(), context.DeadlineExceeded), "handler was not called three times")
+
+	require.Len(t, dead, 1)
+	assert.Equal(t, n.ID, *dead[0].NotificationID)
+	assert.Equal(t, "memory.normal", dead[0].Topic)
+	require.Len(t, dead[0].Attempts, 3)
+	assert.Equal(t, 3, dead[0].Attempts[2].Attempt)
+}
+
+func TestQueue_ScheduledFeedsHandler(t *testing.T) {
+	var dead []*domain.DeadLetter
+	q := newTestQueue(1, &dead)
+
+	n := newNotification(t, domain.PriorityHigh)
+	due := time.Now().Add(time.Minute)
+	n.ScheduledAt = &due
+	require.NoError(t, q.EnqueueScheduled(context.Background(), n))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sc := q.Scheduled()
+	var gotDue time.Time
+	go func() {
+		_ = sc.Start(ctx, func(_ context.Context, id string, dueAt time.Time) error {
+			gotDue = dueAt
+			cancel()
+			return nil
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, sc.Stop(context.Background()))
+	assert.True(t, due.Equal(gotDue))
+}
+
+func TestQueue_RateLimitsPerChannel(t *testing.T) {
+	q := New(Config{Concurrency: 4, RatePerChannel: 2, Logger: zap.NewNop()})
+
+	for range 5 {
+		require.NoError(t, q.Enqueue(context.Background(), newNotification(t, domain.PriorityNormal)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	handled := 0
+	go func() {
+		_ = q.Start(ctx, func(_ context.Context, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled++
+			return nil
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, q.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, handled, "the sms limit holds back the rest of the burst")
+}
+
+func TestQueue_CapsRetriesOfUncountedErrors(t *testing.T) {
+	var dead []*domain.DeadLetter
+	q := newTestQueue(1, &dead)
+
+	require.NoError(t, q.Enqueue(context.Background(), newNotification(t, domain.PriorityNormal)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	calls := 0
+	go func() {
+		_ = q.Start(ctx, func(_ context.Context, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls == queue.MaxAttempts {
+				defer cancel()
+			}
+			return errors.New("connection refused")
+		})
+	}()
+
+	<-ctx.Done()
+	require.NoError(t, q.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, queue.MaxAttempts, calls)
+	require.Len(t, dead, 1)
+	assert.Len(t, dead[0].Attempts, queue.MaxAttempts)
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker records fetched messages per partition in fetch order and only
+// releases an offset for commit once every earlier message on the same
+// partition has finished, so a commit never skips past unfinished work.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []kafka.Message
+	done    map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+func (t *offsetTracker) track(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg)
+}
+
+// complete marks msg as finished and calls commit with the highest message
+// that is now safe to commit, if any. commit runs under the tracker lock so
+// commits for a partition are issued in offset order.
+func (t *offsetTracker) complete(msg kafka.Message, commit func(kafka.Message)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return
+	}
+	p.done[msg.Offset] = true
+
+	var last *kafka.Message
+	for len(p.pending) > 0 && p.done[p.pending[0].Offset] {
+		head := p.pending[0]
+		delete(p.done, head.Offset)
+		p.pending = p.pending[1:]
+		last = &head
+	}
+
+	if last != nil {
+		commit(*last)
+	}
+}
+
+func (t *offsetTracker) inFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, p := range t.partitions {
+		n += len(p.pending)
+	}
+	return n
+}
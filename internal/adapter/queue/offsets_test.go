@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetTracker_CommitsInOrder(t *testing.T) {
+	tr := newOffsetTracker()
+	msgs := []kafka.Message{
+		{Partition: 0, Offset: 10},
+		{Partition: 0, Offset: 11},
+		{Partition: 0, Offset: 12},
+	}
+	for _, m := range msgs {
+		tr.track(m)
+	}
+
+	var committed []int64
+	commit := func(m kafka.Message) { committed = append(committed, m.Offset) }
+
+	tr.complete(msgs[2], commit)
+	tr.complete(msgs[1], commit)
+	assert.Empty(t, committed, "nothing is safe while offset 10 is unfinished")
+	assert.Equal(t, 3, tr.inFlight())
+
+	tr.complete(msgs[0], commit)
+	assert.Equal(t, []int64{12}, committed)
+	assert.Equal(t, 0, tr.inFlight())
+}
+
+func TestOffsetTracker_PartitionsAreIndependent(t *testing.T) {
+	tr := newOffsetTracker()
+	slow := kafka.Message{Partition: 0, Offset: 5}
+	fast := kafka.Message{Partition: 1, Offset: 7}
+	tr.track(slow)
+	tr.track(fast)
+
+	var committed []kafka.Message
+	commit := func(m kafka.Message) { committed = append(committed, m) }
+
+	tr.complete(fast, commit)
+	assert.Equal(t, []kafka.Message{fast}, committed)
+	assert.Equal(t, 1, tr.inFlight())
+}
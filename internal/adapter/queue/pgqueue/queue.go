@@ -0,0 +1,367 @@
+// Package pgqueue is a queue backend on a Postgres table for deployments that
+// do not run Kafka. Workers claim rows with FOR UPDATE SKIP LOCKED, so any
+// number of worker replicas can share the table without double delivery.
+package pgqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/adapter/queue"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+var priorityRank = map[domain.Priority]int{
+	domain.PriorityHigh:   0,
+	domain.PriorityNormal: 1,
+	domain.PriorityLow:    2,
+}
+
+type Config struct {
+	Concurrency  int
+	PollInterval time.Duration
+	Lease        time.Duration
+	RetryDelay   func(attempt int) time.Duration
+	DeadLetters  port.DeadLetterHandler
+	Logger       *zap.Logger
+
+	// RatePerChannel caps each channel's sends per second; zero leaves
+	// channels unlimited. RateStore, when set, shares the caps across workers.
+	RatePerChannel int
+	RateStore      ratelimit.Store
+}
+
+type messageRow struct {
+	ID             int64           `db:"id"`
+	NotificationID uuid.UUID       `db:"notification_id"`
+	Channel        string          `db:"channel"`
+	Priority       string          `db:"priority"`
+	PriorityRank   int             `db:"priority_rank"`
+	TraceCarrier   json.RawMessage `db:"trace_carrier"`
+	Attempts       int             `db:"attempts"`
+	AttemptHistory json.RawMessage `db:"attempt_history"`
+	AvailableAt    time.Time       `db:"available_at"`
+	LockedUntil    *time.Time      `db:"locked_until"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// Queue implements port.QueuePublisher and port.QueueConsumer. Workers pick
+// between the priorities with a queue.LanePicker, the same way the Kafka
+// consumer does, and serve each priority's rows by availability.
+type Queue struct {
+	db       *sqlx.DB
+	cfg      Config
+	picker   *queue.LanePicker
+	limiters queue.ChannelLimiters
+	logger   *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(db *sqlx.DB, cfg Config) *Queue {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 250 * time.Millisecond
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	if cfg.RetryDelay == nil {
+		cfg.RetryDelay = queue.RetryDelayForAttempt
+	}
+
+	return &Queue{
+		db:       db,
+		cfg:      cfg,
+		picker:   queue.NewLanePicker(nil, 0),
+		limiters: queue.NewChannelLimiters(cfg.RatePerChannel, 0, cfg.RateStore),
+		logger:   cfg.Logger,
+	}
+}
+
+func (q *Queue) Enqueue(ctx context.Context, n *domain.Notification) error {
+	rank, ok := priorityRank[n.Priority]
+	if !ok {
+		return fmt.Errorf("unknown priority: %s", n.Priority)
+	}
+
+	carrier := make(map[string]string)
+	propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(carrier))
+	raw, _ := json.Marshal(carrier)
+
+	_, err := q.db.ExecContext(ctx,
+		`INSERT INTO queue_messages (notification_id, channel, priority, priority_rank, trace_carrier)
+		VALUES ($1, $2, $3, $4, $5)`,
+		n.ID, n.Channel, n.Priority, rank, raw,
+	)
+	return err
+}
+
+// EnqueueScheduled only enqueues notifications that carry no schedule. The
+// notifications table already holds scheduled_at, and the scheduler polls it
+// and enqueues each row when it is due.
+func (q *Queue) EnqueueScheduled(ctx context.Context, n *domain.Notification) error {
+	if n.ScheduledAt == nil {
+		return q.Enqueue(ctx, n)
+	}
+	return nil
+}
+
+func (q *Queue) Close() error {
+	return nil
+}
+
+func (q *Queue) Start(ctx context.Context, handler port.MessageHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Stop may run on another goroutine; it must see cancel and the workers
+	// together.
+	q.mu.Lock()
+	q.cancel = cancel
+	q.wg.Add(q.cfg.Concurrency)
+	q.mu.Unlock()
+
+	for range q.cfg.Concurrency {
+		go q.work(ctx, handler)
+	}
+
+	q.logger.Info("postgres queue started",
+		zap.Int("concurrency", q.cfg.Concurrency),
+		zap.Duration("poll_interval", q.cfg.PollInterval),
+	)
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (q *Queue) Stop(_ context.Context) error {
+	q.mu.Lock()
+	cancel := q.cancel
+	q.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	q.wg.Wait()
+	return nil
+}
+
+func (q *Queue) work(ctx context.Context, handler port.MessageHandler) {
+	defer q.wg.Done()
+
+	for {
+		row, err := q.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Error("failed to claim queue message", zap.Error(err))
+		}
+		if row == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.cfg.PollInterval):
+			}
+			continue
+		}
+
+		q.process(ctx, row, handler)
+	}
+}
+
+// readyCondition matches rows that are due and not leased. An expired lease
+// makes the row claimable again, so a worker that dies mid-delivery does not
+// strand its message.
+const readyCondition = `available_at <= NOW() AND (locked_until IS NULL OR locked_until < NOW())`
+
+// claim leases the next ready row from the priority the picker chooses. When
+// another worker has just taken that priority's last ready row, it leases
+// the next ready row of any priority instead.
+func (q *Queue) claim(ctx context.Context) (*messageRow, error) {
+	rank, ok, err := q.pick(ctx)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	row, err := q.claimWhere(ctx, `priority_rank = $2 AND `+readyCondition, rank)
+	if row != nil || err != nil {
+		return row, err
+	}
+	return q.claimWhere(ctx, readyCondition)
+}
+
+// pick reads when the oldest ready row of each priority became available,
+// on the database clock, and lets the picker choose among them.
+func (q *Queue) pick(ctx context.Context) (int, bool, error) {
+	var heads []struct {
+		Rank        int       `db:"priority_rank"`
+		AvailableAt time.Time `db:"available_at"`
+		Now         time.Time `db:"now"`
+	}
+	err := q.db.SelectContext(ctx, &heads,
+		`SELECT r.rank AS priority_rank, head.available_at, NOW() AS now
+		FROM (VALUES (0), (1), (2)) AS r(rank)
+		CROSS JOIN LATERAL (
+			SELECT available_at FROM queue_messages
+			WHERE priority_rank = r.rank AND `+readyCondition+`
+			ORDER BY available_at, id
+			LIMIT 1
+		) head`,
+	)
+	if err != nil || len(heads) == 0 {
+		return 0, false, err
+	}
+
+	oldest := make(map[domain.Priority]time.Time, len(heads))
+	for _, h := range heads {
+		for priority, rank := range priorityRank {
+			if rank == h.Rank {
+				oldest[priority] = h.AvailableAt
+			}
+		}
+	}
+	priority, ok := q.picker.Pick(heads[0].Now, oldest)
+	return priorityRank[priority], ok, nil
+}
+
+func (q *Queue) claimWhere(ctx context.Context, where string, args ...any) (*messageRow, error) {
+	var row messageRow
+	err := q.db.GetContext(ctx, &row,
+		`UPDATE queue_messages SET locked_until = NOW() + $1::interval
+		WHERE id = (
+			SELECT id FROM queue_messages
+			WHERE `+where+`
+			ORDER BY priority_rank, available_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		append([]any{q.cfg.Lease.String()}, args...)...,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (q *Queue) process(ctx context.Context, row *messageRow, handler port.MessageHandler) {
+	msgCtx := ctx
+	if row.TraceCarrier != nil {
+		var carrier map[string]string
+		if json.Unmarshal(row.TraceCarrier, &carrier) == nil && len(carrier) > 0 {
+			msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(carrier))
+		}
+	}
+
+	if err := q.limiters.Wait(msgCtx, row.Channel, domain.Priority(row.Priority)); err != nil {
+		// Leave the lease to expire so another worker picks the row up.
+		return
+	}
+
+	err := handler(msgCtx, row.NotificationID.String())
+	if ctx.Err() != nil {
+		// Leave the lease to expire so another worker picks the row up.
+		return
+	}
+
+	switch {
+	case err == nil:
+		q.delete(ctx, row.ID)
+	case errors.Is(err, domain.ErrDeliveryFailed), row.Attempts+1 >= queue.MaxAttempts:
+		q.deadLetter(ctx, row, err)
+		q.delete(ctx, row.ID)
+	default:
+		q.retry(ctx, row, err)
+	}
+}
+
+func (q *Queue) retry(ctx context.Context, row *messageRow, cause error) {
+	history, _ := json.Marshal(append(attemptHistory(row), failure(row, cause)))
+	delay := queue.RetryDelay(q.cfg.RetryDelay(row.Attempts+1), cause)
+
+	_, err := q.db.ExecContext(ctx,
+		`UPDATE queue_messages SET attempts = attempts + 1, attempt_history = $1,
+			available_at = NOW() + $2::interval, locked_until = NULL
+		WHERE id = $3`,
+		history, delay.String(), row.ID,
+	)
+	if err != nil {
+		q.logger.Error("failed to reschedule queue message", zap.Int64("id", row.ID), zap.Error(err))
+		return
+	}
+
+	q.logger.Info("notification scheduled for retry",
+		zap.String("notification_id", row.NotificationID.String()),
+		zap.Int("attempt", row.Attempts+1),
+		zap.Duration("delay", delay),
+	)
+}
+
+func (q *Queue) deadLetter(ctx context.Context, row *messageRow, cause error) {
+	if q.cfg.DeadLetters == nil {
+		return
+	}
+
+	nid := row.NotificationID
+	d := &domain.DeadLetter{
+		ID:             uuid.Must(uuid.NewV7()),
+		NotificationID: &nid,
+		Topic:          lane(row.Priority),
+		Offset:         row.ID,
+		Payload:        []byte(nid.String()),
+		Error:          cause.Error(),
+		Attempts:       append(attemptHistory(row), failure(row, cause)),
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := q.cfg.DeadLetters(ctx, d); err != nil {
+		q.logger.Error("dead letter handler failed",
+			zap.String("notification_id", nid.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (q *Queue) delete(ctx context.Context, id int64) {
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM queue_messages WHERE id = $1`, id); err != nil {
+		q.logger.Error("failed to delete queue message", zap.Int64("id", id), zap.Error(err))
+	}
+}
+
+func attemptHistory(row *messageRow) []domain.DeadLetterAttempt {
+	var history []domain.DeadLetterAttempt
+	if row.AttemptHistory != nil {
+		_ = json.Unmarshal(row.AttemptHistory, &history)
+	}
+	return history
+}
+
+func failure(row *messageRow, cause error) domain.DeadLetterAttempt {
+	return domain.DeadLetterAttempt{
+		Attempt:  row.Attempts + 1,
+		Topic:    lane(row.Priority),
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+}
+
+func lane(priority string) string {
+	return "queue_messages." + priority
+}
@@ -0,0 +1,331 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+var defaultPriorityWeights = map[domain.Priority]int{
+	domain.PriorityHigh:   6,
+	domain.PriorityNormal: 3,
+	domain.PriorityLow:    1,
+}
+
+const (
+	defaultPriorityAging  = 10 * time.Second
+	defaultHighReservePct = 20
+)
+
+type job struct {
+	msg        kafka.Message
+	lane       *lane
+	enqueuedAt time.Time
+	// retried jobs are back on the lane without a slot; see retryLater.
+	retried bool
+}
+
+// lane buffers fetched messages for one priority topic. slots bounds the
+// buffer so a busy topic stops fetching instead of growing without limit.
+type lane struct {
+	priority domain.Priority
+	queue    []job
+	slots    chan struct{}
+	reader   *kafka.Reader
+	tracker  *offsetTracker
+}
+
+// dispatcher hands buffered messages to workers in the order its LanePicker
+// chooses between the non-empty lanes.
+//
+// When ordered, a key is owned from the moment one of its messages becomes
+// runnable until done is called for it. Later messages for an owned key wait
+// in owned[key] and are released one at a time, in fetch order.
+type dispatcher struct {
+	mu      sync.Mutex
+	lanes   []*lane
+	picker  *LanePicker
+	items   chan struct{}
+	now     func() time.Time
+	ordered bool
+	owned   map[string][]job
+}
+
+func newDispatcher(capacity int, weights map[domain.Priority]int, aging time.Duration, ordered bool) *dispatcher {
+	return &dispatcher{
+		picker:  NewLanePicker(weights, aging),
+		items:   make(chan struct{}, capacity*len(priorityTopics)),
+		now:     time.Now,
+		ordered: ordered,
+		owned:   make(map[string][]job),
+	}
+}
+
+func (d *dispatcher) addLane(priority domain.Priority, capacity int) *lane {
+	l := &lane{
+		priority: priority,
+		slots:    make(chan struct{}, capacity),
+		tracker:  newOffsetTracker(),
+	}
+	d.mu.Lock()
+	d.lanes = append(d.lanes, l)
+	d.mu.Unlock()
+	return l
+}
+
+// push blocks until the lane has room. It returns false when ctx is done.
+func (d *dispatcher) push(ctx context.Context, l *lane, msg kafka.Message) bool {
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	j := job{msg: msg, lane: l, enqueuedAt: d.now()}
+
+	d.mu.Lock()
+	if d.ordered && len(msg.Key) > 0 {
+		key := string(msg.Key)
+		if waiting, ok := d.owned[key]; ok {
+			d.owned[key] = append(waiting, j)
+			d.mu.Unlock()
+			return true
+		}
+		d.owned[key] = nil
+	}
+	l.queue = append(l.queue, j)
+	d.mu.Unlock()
+
+	d.items <- struct{}{}
+	return true
+}
+
+// done releases the key held by j, making the next waiting message for the
+// same key runnable.
+func (d *dispatcher) done(j job) {
+	if !d.ordered || len(j.msg.Key) == 0 {
+		return
+	}
+	key := string(j.msg.Key)
+
+	d.mu.Lock()
+	waiting := d.owned[key]
+	if len(waiting) == 0 {
+		delete(d.owned, key)
+		d.mu.Unlock()
+		return
+	}
+	next := waiting[0]
+	d.owned[key] = waiting[1:]
+	next.lane.queue = append(next.lane.queue, next)
+	d.mu.Unlock()
+
+	d.items <- struct{}{}
+}
+
+// retryLater puts j back on its lane once due without releasing its key, so
+// no later message for the key can overtake it and no worker waits out the
+// backoff. It goes back without taking a slot: later messages for the key may
+// hold every slot while they wait for it, and waiting for one of those would
+// stall the lane for good. The message is dropped when ctx ends first; its
+// offset is still uncommitted, so it is redelivered.
+func (d *dispatcher) retryLater(ctx context.Context, j job, due time.Time) {
+	time.AfterFunc(time.Until(due), func() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.mu.Lock()
+		j.enqueuedAt = d.now()
+		j.retried = true
+		j.lane.queue = append(j.lane.queue, j)
+		d.mu.Unlock()
+
+		select {
+		case d.items <- struct{}{}:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// pop blocks until a message is buffered. It returns false when ctx is done.
+func (d *dispatcher) pop(ctx context.Context) (job, bool) {
+	select {
+	case <-d.items:
+	case <-ctx.Done():
+		return job{}, false
+	}
+
+	d.mu.Lock()
+	l := d.next()
+	j := l.queue[0]
+	l.queue = l.queue[1:]
+	d.mu.Unlock()
+
+	if !j.retried {
+		<-l.slots
+	}
+	return j, true
+}
+
+func (d *dispatcher) next() *lane {
+	oldest := make(map[domain.Priority]time.Time, len(d.lanes))
+	for _, l := range d.lanes {
+		if len(l.queue) > 0 {
+			oldest[l.priority] = l.queue[0].enqueuedAt
+		}
+	}
+	priority, _ := d.picker.Pick(d.now(), oldest)
+	for _, l := range d.lanes {
+		if l.priority == priority {
+			return l
+		}
+	}
+	return nil
+}
+
+// priorities is the order LanePicker breaks ties in.
+var priorities = []domain.Priority{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow}
+
+// LanePicker chooses the priority a worker serves next, by smooth weighted
+// round robin across the priorities with work waiting. A message that has
+// waited longer than aging is served first regardless of weight, so low
+// priority work keeps moving under a sustained high priority flood. Every
+// queue backend picks this way, so a priority gets the same share of the
+// workers whichever backend carries it.
+type LanePicker struct {
+	mu      sync.Mutex
+	weights map[domain.Priority]int
+	current map[domain.Priority]int
+	aging   time.Duration
+}
+
+// NewLanePicker falls back to the default weights (high 6, normal 3, low 1)
+// when weights is nil and to the default aging of 10s when aging is not
+// positive.
+func NewLanePicker(weights map[domain.Priority]int, aging time.Duration) *LanePicker {
+	if weights == nil {
+		weights = defaultPriorityWeights
+	}
+	if aging <= 0 {
+		aging = defaultPriorityAging
+	}
+	return &LanePicker{
+		weights: weights,
+		current: make(map[domain.Priority]int, len(priorities)),
+		aging:   aging,
+	}
+}
+
+// Pick returns the priority to serve next. oldest holds, for each priority
+// with work waiting, when its oldest waiting message became ready. It
+// returns false when nothing is waiting.
+func (p *LanePicker) Pick(now time.Time, oldest map[domain.Priority]time.Time) (domain.Priority, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var aged domain.Priority
+	for _, priority := range priorities {
+		at, ok := oldest[priority]
+		if !ok || now.Sub(at) < p.aging {
+			continue
+		}
+		if aged == "" || at.Before(oldest[aged]) {
+			aged = priority
+		}
+	}
+	if aged != "" {
+		return aged, true
+	}
+
+	var best domain.Priority
+	total := 0
+	for _, priority := range priorities {
+		if _, ok := oldest[priority]; !ok {
+			continue
+		}
+		p.current[priority] += p.weights[priority]
+		total += p.weights[priority]
+		if best == "" || p.current[priority] > p.current[best] {
+			best = priority
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	p.current[best] -= total
+	return best, true
+}
+
+// channelLimiter splits a channel's rate between a reserve only high
+// priority may draw from and a pool every priority shares. High priority
+// takes a reserved token when one is free and otherwise competes for the
+// shared pool, so the combined rate never exceeds the channel limit. With a
+// store, both buckets are shared by every worker.
+type channelLimiter struct {
+	reserved *ratelimit.Limiter
+	shared   *ratelimit.Limiter
+}
+
+func newChannelLimiter(channel string, perSecond, reservePct int, store ratelimit.Store) *channelLimiter {
+	reserved := perSecond * reservePct / 100
+	shared := perSecond - reserved
+
+	l := &channelLimiter{shared: newLimiter(float64(shared), store, "channel/"+channel)}
+	if reserved > 0 {
+		l.reserved = newLimiter(float64(reserved), store, "channel/"+channel+"/high")
+	}
+	return l
+}
+
+func newLimiter(perSecond float64, store ratelimit.Store, key string) *ratelimit.Limiter {
+	if store == nil {
+		return ratelimit.New(perSecond)
+	}
+	return ratelimit.NewShared(perSecond, store, key)
+}
+
+func (l *channelLimiter) Wait(ctx context.Context, priority domain.Priority) error {
+	if priority == domain.PriorityHigh && l.reserved != nil && l.reserved.Allow(ctx) {
+		return nil
+	}
+	return l.shared.Wait(ctx)
+}
+
+// ChannelLimiters rate limits each delivery channel before a message reaches
+// the handler. Every queue backend builds one from the same settings, so
+// RATE_LIMIT_PER_CHANNEL and RATE_LIMIT_STORE mean the same thing whichever
+// backend carries the messages.
+type ChannelLimiters map[string]*channelLimiter
+
+// NewChannelLimiters reserves reservePct of each channel's perSecond for high
+// priority; a percentage outside (0, 100) falls back to the default reserve.
+// A perSecond of zero or less leaves every channel unlimited.
+func NewChannelLimiters(perSecond, reservePct int, store ratelimit.Store) ChannelLimiters {
+	if perSecond <= 0 {
+		return nil
+	}
+	if reservePct <= 0 || reservePct >= 100 {
+		reservePct = defaultHighReservePct
+	}
+
+	limiters := make(ChannelLimiters)
+	for _, ch := range []domain.Channel{domain.ChannelSMS, domain.ChannelEmail, domain.ChannelPush} {
+		limiters[string(ch)] = newChannelLimiter(string(ch), perSecond, reservePct, store)
+	}
+	return limiters
+}
+
+// Wait blocks until channel has capacity for a message of priority. Unknown
+// channels are not limited.
+func (l ChannelLimiters) Wait(ctx context.Context, channel string, priority domain.Priority) error {
+	if limiter, ok := l[channel]; ok {
+		return limiter.Wait(ctx, priority)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/pkg/ratelimit"
+)
+
+func newTestDispatcher(aging time.Duration) (*dispatcher, map[domain.Priority]*lane) {
+	d := newDispatcher(16, defaultPriorityWeights, aging, false)
+	lanes := make(map[domain.Priority]*lane)
+	for _, p := range []domain.Priority{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow} {
+		lanes[p] = d.addLane(p, 16)
+	}
+	return d, lanes
+}
+
+func fill(t *testing.T, d *dispatcher, l *lane, n int) {
+	for i := range n {
+		require.True(t, d.push(context.Background(), l, kafka.Message{Offset: int64(i)}))
+	}
+}
+
+func TestDispatcher_WeightedShare(t *testing.T) {
+	d, lanes := newTestDispatcher(time.Hour)
+	for _, l := range lanes {
+		fill(t, d, l, 10)
+	}
+
+	counts := make(map[domain.Priority]int)
+	for range 10 {
+		j, ok := d.pop(context.Background())
+		require.True(t, ok)
+		counts[j.lane.priority]++
+	}
+
+	assert.Equal(t, 6, counts[domain.PriorityHigh])
+	assert.Equal(t, 3, counts[domain.PriorityNormal])
+	assert.Equal(t, 1, counts[domain.PriorityLow])
+}
+
+func TestDispatcher_AgedMessageJumpsAhead(t *testing.T) {
+	d, lanes := newTestDispatcher(5 * time.Second)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	fill(t, d, lanes[domain.PriorityLow], 1)
+	now = now.Add(6 * time.Second)
+	fill(t, d, lanes[domain.PriorityHigh], 5)
+
+	j, ok := d.pop(context.Background())
+	require.True(t, ok)
+	assert.Equal(t, domain.PriorityLow, j.lane.priority)
+}
+
+func TestLanePicker_Pick(t *testing.T) {
+	p := NewLanePicker(nil, 5*time.Second)
+	now := time.Now()
+
+	_, ok := p.Pick(now, nil)
+	assert.False(t, ok)
+
+	got, ok := p.Pick(now, map[domain.Priority]time.Time{domain.PriorityLow: now})
+	require.True(t, ok)
+	assert.Equal(t, domain.PriorityLow, got, "a lone lane is served whatever its weight")
+
+	got, _ = p.Pick(now, map[domain.Priority]time.Time{
+		domain.PriorityHigh: now,
+		domain.PriorityLow:  now.Add(-6 * time.Second),
+	})
+	assert.Equal(t, domain.PriorityLow, got, "an aged message is served ahead of the weights")
+}
+
+func TestDispatcher_PopStopsOnCancel(t *testing.T) {
+	d, _ := newTestDispatcher(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, ok := d.pop(ctx)
+	assert.False(t, ok)
+}
+
+func TestDispatcher_OrderedHoldsKeyUntilDone(t *testing.T) {
+	d := newDispatcher(16, defaultPriorityWeights, time.Hour, true)
+	l := d.addLane(domain.PriorityNormal, 16)
+	ctx := context.Background()
+
+	for i, key := range []string{"a", "a", "b"} {
+		require.True(t, d.push(ctx, l, kafka.Message{Key: []byte(key), Offset: int64(i)}))
+	}
+
+	first, ok := d.pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), first.msg.Offset)
+
+	second, ok := d.pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), second.msg.Offset, "key b is not blocked by key a")
+
+	popCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, ok = d.pop(popCtx)
+	assert.False(t, ok, "second message for key a waits for the first")
+
+	d.done(first)
+	third, ok := d.pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), third.msg.Offset)
+}
+
+func TestDispatcher_RetryLaterKeepsKey(t *testing.T) {
+	d := newDispatcher(16, defaultPriorityWeights, time.Hour, true)
+	l := d.addLane(domain.PriorityNormal, 16)
+	ctx := context.Background()
+
+	for i := range 2 {
+		require.True(t, d.push(ctx, l, kafka.Message{Key: []byte("a"), Offset: int64(i)}))
+	}
+
+	first, ok := d.pop(ctx)
+	require.True(t, ok)
+	d.retryLater(ctx, first, time.Now().Add(20*time.Millisecond))
+
+	popCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	_, ok = d.pop(popCtx)
+	assert.False(t, ok, "nothing is runnable while the first message backs off")
+
+	again, ok := d.pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), again.msg.Offset, "the retry comes back before the next message for its key")
+
+	d.done(again)
+	next, ok := d.pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), next.msg.Offset)
+}
+
+func TestDispatcher_RetryLaterWithFullLane(t *testing.T) {
+	d := newDispatcher(2, defaultPriorityWeights, time.Hour, true)
+	l := d.addLane(domain.PriorityNormal, 2)
+	ctx := context.Background()
+
+	require.True(t, d.push(ctx, l, kafka.Message{Key: []byte("a"), Offset: 0}))
+	first, ok := d.pop(ctx)
+	require.True(t, ok)
+
+	// Two later messages for the key take both slots while they wait for it.
+	for i := 1; i <= 2; i++ {
+		require.True(t, d.push(ctx, l, kafka.Message{Key: []byte("a"), Offset: int64(i)}))
+	}
+	d.retryLater(ctx, first, time.Now())
+
+	popCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	again, ok := d.pop(popCtx)
+	require.True(t, ok, "the retry must not wait for a slot its own key holds")
+	assert.Equal(t, int64(0), again.msg.Offset)
+
+	for i := 1; i <= 2; i++ {
+		d.done(again)
+		again, ok = d.pop(popCtx)
+		require.True(t, ok)
+		assert.Equal(t, int64(i), again.msg.Offset)
+	}
+	assert.Empty(t, l.slots, "every slot is released")
+}
+
+func TestChannelLimiter_ReservesCapacityForHigh(t *testing.T) {
+	l := newChannelLimiter("sms", 10, 20, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for range 8 {
+		require.NoError(t, l.Wait(ctx, domain.PriorityLow))
+	}
+	assert.Error(t, l.Wait(ctx, domain.PriorityLow), "shared pool is drained")
+
+	assert.NoError(t, l.Wait(ctx, domain.PriorityHigh))
+	assert.NoError(t, l.Wait(ctx, domain.PriorityHigh))
+}
+
+func TestChannelLimiter_SharedAcrossWorkers(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	a := newChannelLimiter("sms", 10, 20, store)
+	b := newChannelLimiter("sms", 10, 20, store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for range 4 {
+		require.NoError(t, a.Wait(ctx, domain.PriorityLow))
+		require.NoError(t, b.Wait(ctx, domain.PriorityLow))
+	}
+	assert.Error(t, b.Wait(ctx, domain.PriorityLow), "the two workers drained one shared pool")
+
+	assert.NoError(t, a.Wait(ctx, domain.PriorityHigh))
+	assert.NoError(t, b.Wait(ctx, domain.PriorityHigh))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,18 +20,33 @@ var topicForPriority = map[domain.Priority]string{
 	domain.PriorityLow:    "notifications.low",
 }
 
+var priorityForTopic = map[string]domain.Priority{
+	"notifications.high":   domain.PriorityHigh,
+	"notifications.normal": domain.PriorityNormal,
+	"notifications.low":    domain.PriorityLow,
+}
+
+const scheduledTopic = "notifications.scheduled"
+
 type NotificationPayload struct {
 	NotificationID string            `json:"notification_id"`
 	Channel        string            `json:"channel"`
+	ScheduledAt    *time.Time        `json:"scheduled_at,omitempty"`
 	Carrier        map[string]string `json:"carrier,omitempty"`
 }
 
+// Producer keys messages by notification ID, spreading load evenly across
+// partitions. With ordered set it keys them by Notification.PartitionKey
+// instead, so every message for one recipient or ordering key lands on the
+// same partition in enqueue order.
 type Producer struct {
-	writer *kafka.Writer
+	writer  *kafka.Writer
+	ordered bool
 }
 
-func NewProducer(brokers []string) *Producer {
+func NewProducer(brokers []string, ordered bool) *Producer {
 	return &Producer{
+		ordered: ordered,
 		writer: &kafka.Writer{
 			Addr:         kafka.TCP(brokers...),
 			Balancer:     &kafka.Hash{},
@@ -74,7 +90,7 @@ func (p *Producer) Enqueue(ctx context.Context, n *domain.Notification) error {
 
 	if err := p.writer.WriteMessages(ctx, kafka.Message{
 		Topic: topic,
-		Key:   []byte(n.ID.String()),
+		Key:   p.key(n),
 		Value: value,
 	}); err != nil {
 		tracing.RecordError(span, err)
@@ -84,10 +100,54 @@ func (p *Producer) Enqueue(ctx context.Context, n *domain.Notification) error {
 	return nil
 }
 
-func (p *Producer) EnqueueScheduled(_ context.Context, _ *domain.Notification) error {
+func (p *Producer) EnqueueScheduled(ctx context.Context, n *domain.Notification) error {
+	ctx, span := tracing.Tracer().Start(ctx, "kafka.produce_scheduled")
+	defer span.End()
+
+	if n.ScheduledAt == nil {
+		return p.Enqueue(ctx, n)
+	}
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", scheduledTopic),
+		attribute.String("messaging.operation.type", "publish"),
+		attribute.String("notification.id", n.ID.String()),
+		attribute.String("notification.scheduled_at", n.ScheduledAt.Format(time.RFC3339Nano)),
+	)
+
+	payload := NotificationPayload{
+		NotificationID: n.ID.String(),
+		Channel:        string(n.Channel),
+		ScheduledAt:    n.ScheduledAt,
+		Carrier:        propagateTraceContext(ctx),
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if err := p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: scheduledTopic,
+		Key:   []byte(n.ID.String()),
+		Value: value,
+	}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
 	return nil
 }
 
+func (p *Producer) key(n *domain.Notification) []byte {
+	if p.ordered {
+		return []byte(n.PartitionKey())
+	}
+	return []byte(n.ID.String())
+}
+
 func (p *Producer) Close() error {
 	return p.writer.Close()
 }
@@ -0,0 +1,271 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+const (
+	headerAttempt     = "x-attempt"
+	headerRetryDue    = "x-retry-due"
+	headerOriginTopic = "x-origin-topic"
+	headerHistory     = "x-attempt-history"
+)
+
+// MaxAttempts caps how often a message is handled before it is
+// dead-lettered. Provider failures reach their per-priority retry limit well
+// before it; the cap catches errors the delivery service does not count, such
+// as an unparseable notification ID or a database that keeps failing.
+const MaxAttempts = 10
+
+// retriesExhausted reports whether msg has used up its attempts, counting the
+// one that just failed.
+func retriesExhausted(msg kafka.Message) bool {
+	return messageAttempt(msg)+1 >= MaxAttempts
+}
+
+type retryTier struct {
+	topic string
+	delay time.Duration
+}
+
+// retryTiers are ordered by delay. A failed message goes to the first tier
+// whose delay covers its backoff; the longest tier takes everything beyond.
+var retryTiers = []retryTier{
+	{topic: "notifications.retry.5s", delay: 5 * time.Second},
+	{topic: "notifications.retry.1m", delay: time.Minute},
+	{topic: "notifications.retry.10m", delay: 10 * time.Minute},
+}
+
+func retryTierFor(delay time.Duration) retryTier {
+	for _, tier := range retryTiers {
+		if delay <= tier.delay {
+			return tier
+		}
+	}
+	return retryTiers[len(retryTiers)-1]
+}
+
+func headerValue(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func messageAttempt(msg kafka.Message) int {
+	attempt, err := strconv.Atoi(headerValue(msg, headerAttempt))
+	if err != nil {
+		return 0
+	}
+	return attempt
+}
+
+func attemptHistory(msg kafka.Message) []domain.DeadLetterAttempt {
+	var history []domain.DeadLetterAttempt
+	if raw := headerValue(msg, headerHistory); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &history)
+	}
+	return history
+}
+
+// withAttempt returns the history carried by msg with one more failure
+// appended, encoded for the attempt history header.
+func withAttempt(msg kafka.Message, attempt int, cause error, now time.Time) []byte {
+	history := append(attemptHistory(msg), domain.DeadLetterAttempt{
+		Attempt:  attempt,
+		Topic:    msg.Topic,
+		Error:    cause.Error(),
+		FailedAt: now.UTC(),
+	})
+	raw, _ := json.Marshal(history)
+	return raw
+}
+
+func originTopic(msg kafka.Message) string {
+	if origin := headerValue(msg, headerOriginTopic); origin != "" {
+		return origin
+	}
+	return msg.Topic
+}
+
+func retryDue(msg kafka.Message) time.Time {
+	ms, err := strconv.ParseInt(headerValue(msg, headerRetryDue), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// retryMessage builds the message that parks original on a retry tier. The
+// origin topic survives repeated retries so the message always returns to
+// its priority lane. A provider's Retry-After can push it to a slower tier
+// than its attempt alone would.
+func retryMessage(original kafka.Message, cause error, now time.Time) kafka.Message {
+	attempt := messageAttempt(original) + 1
+	delay := RetryDelay(RetryDelayForAttempt(attempt), cause)
+	tier := retryTierFor(delay)
+
+	return kafka.Message{
+		Topic: tier.topic,
+		Key:   original.Key,
+		Value: original.Value,
+		Headers: []kafka.Header{
+			{Key: headerAttempt, Value: []byte(strconv.Itoa(attempt))},
+			{Key: headerRetryDue, Value: []byte(strconv.FormatInt(now.Add(delay).UnixMilli(), 10))},
+			{Key: headerOriginTopic, Value: []byte(originTopic(original))},
+			{Key: headerHistory, Value: withAttempt(original, attempt, cause, now)},
+		},
+	}
+}
+
+// RetryConsumer drains the retry tiers. Each tier has its own reader, so a
+// message waiting out a 10 minute backoff never holds up the 5 second tier,
+// and none of them block the priority topics.
+type RetryConsumer struct {
+	cfg     ConsumerConfig
+	readers []*kafka.Reader
+	writer  *kafka.Writer
+	logger  *zap.Logger
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewRetryConsumer(cfg ConsumerConfig) *RetryConsumer {
+	return &RetryConsumer{
+		cfg: cfg,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireOne,
+		},
+		logger: cfg.Logger,
+	}
+}
+
+func (c *RetryConsumer) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	for _, tier := range retryTiers {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        c.cfg.Brokers,
+			Topic:          tier.topic,
+			GroupID:        c.cfg.Group,
+			MinBytes:       1,
+			MaxBytes:       10e6,
+			CommitInterval: time.Second,
+			StartOffset:    kafka.FirstOffset,
+		})
+		c.readers = append(c.readers, reader)
+
+		c.wg.Add(1)
+		go c.consume(ctx, reader)
+	}
+
+	c.logger.Info("retry consumer started", zap.Int("tier_count", len(retryTiers)))
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *RetryConsumer) Stop(_ context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	var firstErr error
+	for _, r := range c.readers {
+		if err := r.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := c.writer.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+func (c *RetryConsumer) consume(ctx context.Context, reader *kafka.Reader) {
+	defer c.wg.Done()
+
+	topic := reader.Config().Topic
+
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("fetch retry message failed",
+				zap.String("topic", topic),
+				zap.Error(err),
+			)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if wait := time.Until(retryDue(msg)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		origin := headerValue(msg, headerOriginTopic)
+		if origin == "" {
+			c.logger.Error("retry message missing origin topic",
+				zap.String("topic", topic),
+				zap.Int64("offset", msg.Offset),
+			)
+			_ = reader.CommitMessages(ctx, msg)
+			continue
+		}
+
+		if !c.republish(ctx, msg, origin) {
+			return
+		}
+
+		_ = reader.CommitMessages(ctx, msg)
+	}
+}
+
+// republish writes msg back to its origin topic, retrying until it succeeds
+// so the retry offset is never committed past an unpublished message. It
+// returns false only when ctx is cancelled.
+func (c *RetryConsumer) republish(ctx context.Context, msg kafka.Message, origin string) bool {
+	for {
+		err := c.writer.WriteMessages(ctx, kafka.Message{
+			Topic:   origin,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: msg.Headers,
+		})
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		c.logger.Error("retry republish failed",
+			zap.String("origin_topic", origin),
+			zap.Int("attempt", messageAttempt(msg)),
+			zap.Error(err),
+		)
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+func TestRetryTierFor(t *testing.T) {
+	assert.Equal(t, "notifications.retry.5s", retryTierFor(2*time.Second).topic)
+	assert.Equal(t, "notifications.retry.5s", retryTierFor(5*time.Second).topic)
+	assert.Equal(t, "notifications.retry.1m", retryTierFor(8*time.Second).topic)
+	assert.Equal(t, "notifications.retry.10m", retryTierFor(5*time.Minute).topic)
+	assert.Equal(t, "notifications.retry.10m", retryTierFor(time.Hour).topic)
+}
+
+func TestRetryMessage_FirstAttempt(t *testing.T) {
+	now := time.Now()
+	original := kafka.Message{Topic: "notifications.high", Key: []byte("k"), Value: []byte("v")}
+
+	msg := retryMessage(original, errors.New("timeout"), now)
+
+	assert.Equal(t, "notifications.retry.5s", msg.Topic)
+	assert.Equal(t, 1, messageAttempt(msg))
+	assert.Equal(t, "notifications.high", headerValue(msg, headerOriginTopic))
+	assert.Equal(t, []byte("v"), msg.Value)
+	assert.WithinDuration(t, now.Add(2*time.Second), retryDue(msg), 600*time.Millisecond)
+
+	history := attemptHistory(msg)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Attempt)
+	assert.Equal(t, "notifications.high", history[0].Topic)
+	assert.Equal(t, "timeout", history[0].Error)
+}
+
+func TestRetryMessage_KeepsOriginAcrossAttempts(t *testing.T) {
+	original := kafka.Message{
+		Topic: "notifications.low",
+		Headers: []kafka.Header{
+			{Key: headerAttempt, Value: []byte("5")},
+			{Key: headerOriginTopic, Value: []byte("notifications.low")},
+		},
+	}
+
+	msg := retryMessage(original, errors.New("timeout"), time.Now())
+
+	assert.Equal(t, 6, messageAttempt(msg))
+	assert.Equal(t, "notifications.retry.10m", msg.Topic)
+	assert.Equal(t, "notifications.low", headerValue(msg, headerOriginTopic))
+}
+
+func TestRetryMessage_HonoursProviderRetryAfter(t *testing.T) {
+	now := time.Now()
+	original := kafka.Message{Topic: "notifications.normal"}
+	cause := port.NewProviderError(port.CategoryRateLimited, "429", errors.New("slow down"))
+	cause.RetryAfter = 30 * time.Second
+
+	msg := retryMessage(original, cause, now)
+
+	assert.Equal(t, 1, messageAttempt(msg))
+	assert.Equal(t, "notifications.retry.1m", msg.Topic)
+	assert.WithinDuration(t, now.Add(30*time.Second), retryDue(msg), time.Millisecond)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, RetryDelay(2*time.Second, errors.New("timeout")))
+	assert.Equal(t, 5*time.Second, RetryDelay(2*time.Second, port.NewProviderError(port.CategoryRateLimited, "", errors.New("429"))))
+	assert.Equal(t, 10*time.Minute, RetryDelay(2*time.Second, port.NewProviderError(port.CategoryQuotaExceeded, "", errors.New("cap"))))
+	assert.Equal(t, time.Minute, RetryDelay(time.Minute, port.NewProviderError(port.CategoryTransient, "", errors.New("503"))))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	msg := kafka.Message{Topic: "notifications.normal"}
+	for range MaxAttempts - 1 {
+		assert.False(t, retriesExhausted(msg))
+		msg = retryMessage(msg, errors.New("invalid UUID length: 3"), time.Now())
+	}
+	assert.True(t, retriesExhausted(msg), "errors the delivery service does not count still stop")
+}
+
+type flakyWriter struct {
+	failures int
+	written  []kafka.Message
+}
+
+func (w *flakyWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("leader not available")
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+func (w *flakyWriter) Close() error { return nil }
+
+func TestConsumer_WriteRetriesUntilAcknowledged(t *testing.T) {
+	writer := &flakyWriter{failures: 2}
+	c := &Consumer{writer: writer, logger: zap.NewNop(), writeBackoff: time.Millisecond}
+
+	original := kafka.Message{Topic: "notifications.normal", Value: []byte(`{"notification_id":"x"}`)}
+	c.retry(context.Background(), original, NotificationPayload{NotificationID: "x"}, errors.New("timeout"))
+
+	require.Len(t, writer.written, 1, "a failed write is retried, not dropped")
+	assert.Equal(t, "notifications.retry.5s", writer.written[0].Topic)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	writer.failures = 1
+	assert.False(t, c.write(ctx, original), "a cancelled write leaves the offset uncommitted")
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+type ScheduledConsumer struct {
+	cfg    ConsumerConfig
+	reader *kafka.Reader
+	logger *zap.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduledConsumer(cfg ConsumerConfig) *ScheduledConsumer {
+	return &ScheduledConsumer{
+		cfg:    cfg,
+		logger: cfg.Logger,
+	}
+}
+
+func (c *ScheduledConsumer) Start(ctx context.Context, handler port.ScheduledMessageHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	c.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        c.cfg.Brokers,
+		Topic:          scheduledTopic,
+		GroupID:        c.cfg.Group,
+		MinBytes:       1,
+		MaxBytes:       10e6,
+		CommitInterval: time.Second,
+		StartOffset:    kafka.FirstOffset,
+	})
+
+	c.wg.Add(1)
+	go c.consume(ctx, handler)
+
+	c.logger.Info("scheduled consumer started", zap.String("topic", scheduledTopic))
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *ScheduledConsumer) Stop(_ context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	if c.reader == nil {
+		return nil
+	}
+	return c.reader.Close()
+}
+
+func (c *ScheduledConsumer) consume(ctx context.Context, handler port.ScheduledMessageHandler) {
+	defer c.wg.Done()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("fetch scheduled message failed", zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+
+		var payload NotificationPayload
+		if err := json.Unmarshal(msg.Value, &payload); err != nil || payload.ScheduledAt == nil {
+			c.logger.Error("invalid scheduled payload",
+				zap.Int64("offset", msg.Offset),
+				zap.Error(err),
+			)
+			_ = c.reader.CommitMessages(ctx, msg)
+			continue
+		}
+
+		msgCtx := ctx
+		if len(payload.Carrier) > 0 {
+			msgCtx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(payload.Carrier))
+		}
+
+		if err := handler(msgCtx, payload.NotificationID, *payload.ScheduledAt); err != nil {
+			c.logger.Error("scheduled handler failed",
+				zap.String("notification_id", payload.NotificationID),
+				zap.Error(err),
+			)
+		}
+
+		_ = c.reader.CommitMessages(ctx, msg)
+	}
+}
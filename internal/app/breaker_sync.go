@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// BreakerSyncInterval is how often a worker applies breaker controls and
+// saves its breaker states. It bounds how long a force-open, a reset or,
+// with shared state, another worker's trip takes to reach every worker.
+const BreakerSyncInterval = 2 * time.Second
+
+// BreakerSync keeps a worker's circuit breakers in step with the rest of the
+// fleet. Each round it applies the controls admins set through the API,
+// publishes its own trips when shared is on so the other workers stop
+// sending to the provider too, and saves its breaker states for the metrics
+// API.
+type BreakerSync struct {
+	breakers port.BreakerController
+	repo     port.CircuitBreakerRepository
+	instance string
+	shared   bool
+	logger   *zap.Logger
+
+	// started stands in for the last reset of every breaker: resets from
+	// before this worker started don't need applying.
+	started time.Time
+	resets  map[string]time.Time
+	opens   map[string]int64
+	now     func() time.Time
+}
+
+func NewBreakerSync(breakers port.BreakerController, repo port.CircuitBreakerRepository, instance string, shared bool, logger *zap.Logger) *BreakerSync {
+	return &BreakerSync{
+		breakers: breakers,
+		repo:     repo,
+		instance: instance,
+		shared:   shared,
+		logger:   logger,
+		started:  time.Now(),
+		resets:   make(map[string]time.Time),
+		opens:    make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+func (s *BreakerSync) Run(ctx context.Context) {
+	ticker := time.NewTicker(BreakerSyncInterval)
+	defer ticker.Stop()
+
+	s.sync(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sync(ctx)
+		}
+	}
+}
+
+func (s *BreakerSync) sync(ctx context.Context) {
+	controls, err := s.repo.ListControls(ctx)
+	if err != nil {
+		s.logger.Warn("failed to load circuit breaker controls", zap.Error(err))
+	}
+	for _, c := range controls {
+		s.apply(c)
+	}
+
+	now := s.now().UTC()
+	breakers := s.breakers.CircuitBreakers()
+	for i := range breakers {
+		breakers[i].InstanceID = s.instance
+		breakers[i].UpdatedAt = now
+		if s.shared {
+			s.publishTrip(ctx, breakers[i])
+		}
+	}
+	if err := s.repo.SaveStates(ctx, breakers); err != nil {
+		s.logger.Warn("failed to save circuit breaker states", zap.Error(err))
+	}
+}
+
+func (s *BreakerSync) apply(c domain.BreakerControl) {
+	key := breakerKey(c.Channel, c.Provider)
+
+	if c.ResetAt != nil && c.ResetAt.After(s.lastReset(key)) {
+		s.resets[key] = *c.ResetAt
+		if s.breakers.Reset(c.Channel, c.Provider) {
+			s.logger.Info("circuit breaker reset",
+				zap.String("breaker", key), zap.String("by", c.UpdatedBy))
+		}
+	}
+	if c.ForcedOpen {
+		s.breakers.ForceOpen(c.Channel, c.Provider)
+	}
+	if s.shared && c.OpenUntil != nil && c.OpenUntil.After(s.now()) {
+		s.breakers.HoldOpen(c.Channel, c.Provider, *c.OpenUntil)
+	}
+}
+
+func (s *BreakerSync) lastReset(key string) time.Time {
+	if last, ok := s.resets[key]; ok {
+		return last
+	}
+	return s.started
+}
+
+// publishTrip shares a trip since the last round. Breakers that are forced
+// open have no end to share.
+func (s *BreakerSync) publishTrip(ctx context.Context, b domain.CircuitBreaker) {
+	key := breakerKey(b.Channel, b.Provider)
+	opens := b.Transitions[domain.BreakerOpen]
+	if opens <= s.opens[key] {
+		return
+	}
+	s.opens[key] = opens
+
+	if b.State != domain.BreakerOpen || b.ForcedOpen || b.OpenUntil == nil {
+		return
+	}
+	if err := s.repo.HoldOpen(ctx, b.Channel, b.Provider, *b.OpenUntil, s.instance); err != nil {
+		s.logger.Warn("failed to share circuit breaker trip", zap.String("breaker", key), zap.Error(err))
+	}
+}
+
+func breakerKey(channel domain.Channel, provider string) string {
+	return string(channel) + "/" + provider
+}
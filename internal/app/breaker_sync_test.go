@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/pkg/circuitbreaker"
+)
+
+// fakeBreakers is a worker's breakers without the router around them.
+type fakeBreakers map[string]*circuitbreaker.Breaker
+
+func newFakeBreakers(names ...string) fakeBreakers {
+	f := make(fakeBreakers)
+	for _, name := range names {
+		f[name] = circuitbreaker.New(name, circuitbreaker.Settings{Failures: 1, OpenTimeout: time.Minute})
+	}
+	return f
+}
+
+func (f fakeBreakers) trip(name string) {
+	_, _ = f[name].Execute(func() (any, error) { return nil, errors.New("down") })
+}
+
+func (f fakeBreakers) CircuitBreakers() []domain.CircuitBreaker {
+	var breakers []domain.CircuitBreaker
+	for name, b := range f {
+		channel, provider, _ := strings.Cut(name, "/")
+		snap := b.Snapshot()
+		cb := domain.CircuitBreaker{
+			Channel:     domain.Channel(channel),
+			Provider:    provider,
+			State:       domain.BreakerState(snap.State),
+			ForcedOpen:  snap.Forced,
+			Transitions: make(map[domain.BreakerState]int64),
+		}
+		for state, n := range snap.Transitions {
+			cb.Transitions[domain.BreakerState(state)] = n
+		}
+		if !snap.OpenUntil.IsZero() {
+			cb.OpenUntil = &snap.OpenUntil
+		}
+		breakers = append(breakers, cb)
+	}
+	return breakers
+}
+
+func (f fakeBreakers) ForceOpen(channel domain.Channel, provider string) bool {
+	return f.with(channel, provider, (*circuitbreaker.Breaker).ForceOpen)
+}
+
+func (f fakeBreakers) HoldOpen(channel domain.Channel, provider string, until time.Time) bool {
+	return f.with(channel, provider, func(b *circuitbreaker.Breaker) { b.HoldOpen(until) })
+}
+
+func (f fakeBreakers) Reset(channel domain.Channel, provider string) bool {
+	return f.with(channel, provider, (*circuitbreaker.Breaker).Reset)
+}
+
+func (f fakeBreakers) with(channel domain.Channel, provider string, fn func(*circuitbreaker.Breaker)) bool {
+	b, ok := f[breakerKey(channel, provider)]
+	if ok {
+		fn(b)
+	}
+	return ok
+}
+
+func TestBreakerSync_AppliesForceOpenAndReset(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCircuitBreakerRepo()
+	breakers := newFakeBreakers("sms/twilio")
+	sync := NewBreakerSync(breakers, repo, "worker-1", false, zap.NewNop())
+
+	require.NoError(t, repo.ForceOpen(ctx, domain.ChannelSMS, "twilio", "api"))
+	sync.sync(ctx)
+
+	assert.True(t, breakers["sms/twilio"].Open())
+	states, _ := repo.ListStatesSince(ctx, time.Time{})
+	require.Len(t, states, 1)
+	assert.Equal(t, "worker-1", states[0].InstanceID)
+	assert.Equal(t, domain.BreakerOpen, states[0].State)
+	assert.True(t, states[0].ForcedOpen)
+
+	require.NoError(t, repo.Reset(ctx, domain.ChannelSMS, "twilio", "api"))
+	sync.sync(ctx)
+	assert.Equal(t, circuitbreaker.StateClosed, breakers["sms/twilio"].State())
+
+	// A reset is applied once, not on every round.
+	breakers.trip("sms/twilio")
+	sync.sync(ctx)
+	assert.True(t, breakers["sms/twilio"].Open())
+}
+
+func TestBreakerSync_IgnoresResetsFromBeforeStart(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCircuitBreakerRepo()
+	require.NoError(t, repo.Reset(ctx, domain.ChannelSMS, "twilio", "api"))
+
+	breakers := newFakeBreakers("sms/twilio")
+	sync := NewBreakerSync(breakers, repo, "worker-1", false, zap.NewNop())
+	breakers.trip("sms/twilio")
+
+	sync.sync(ctx)
+
+	assert.True(t, breakers["sms/twilio"].Open())
+}
+
+func TestBreakerSync_SharesTripsWhenShared(t *testing.T) {
+	for _, shared := range []bool{true, false} {
+		ctx := context.Background()
+		repo := newMockCircuitBreakerRepo()
+		first := newFakeBreakers("sms/twilio", "email/smtp")
+		second := newFakeBreakers("sms/twilio", "email/smtp")
+		firstSync := NewBreakerSync(first, repo, "worker-1", shared, zap.NewNop())
+		secondSync := NewBreakerSync(second, repo, "worker-2", shared, zap.NewNop())
+
+		first.trip("sms/twilio")
+		firstSync.sync(ctx)
+		secondSync.sync(ctx)
+
+		assert.Equal(t, shared, second["sms/twilio"].Open(), "shared=%v", shared)
+		assert.False(t, second["email/smtp"].Open())
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// breakerAdmin is recorded as UpdatedBy on controls set through the API.
+const breakerAdmin = "api"
+
+// BreakerStatus is one provider's circuit breaker across the fleet: the
+// control every worker applies, if any, and what each worker reported
+// recently.
+type BreakerStatus struct {
+	Channel  domain.Channel
+	Provider string
+	Control  *domain.BreakerControl
+	Workers  []domain.CircuitBreaker
+}
+
+// CircuitBreakerService lets operators force a provider's breaker open
+// during an incident and reset it afterwards. The API has no breakers of its
+// own; it records a control that every worker applies within
+// BreakerSyncInterval.
+type CircuitBreakerService struct {
+	repo   port.CircuitBreakerRepository
+	logger *zap.Logger
+}
+
+func NewCircuitBreakerService(repo port.CircuitBreakerRepository, logger *zap.Logger) *CircuitBreakerService {
+	return &CircuitBreakerService{repo: repo, logger: logger}
+}
+
+func (s *CircuitBreakerService) List(ctx context.Context) ([]BreakerStatus, error) {
+	controls, err := s.repo.ListControls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	breakers, err := s.repo.ListStatesSince(ctx, time.Now().Add(-3*BreakerSyncInterval))
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []BreakerStatus
+	index := make(map[string]int)
+	status := func(channel domain.Channel, provider string) *BreakerStatus {
+		key := breakerKey(channel, provider)
+		if i, ok := index[key]; ok {
+			return &statuses[i]
+		}
+		index[key] = len(statuses)
+		statuses = append(statuses, BreakerStatus{Channel: channel, Provider: provider})
+		return &statuses[len(statuses)-1]
+	}
+
+	for _, c := range controls {
+		status(c.Channel, c.Provider).Control = &c
+	}
+	for _, b := range breakers {
+		st := status(b.Channel, b.Provider)
+		st.Workers = append(st.Workers, b)
+	}
+	return statuses, nil
+}
+
+func (s *CircuitBreakerService) ForceOpen(ctx context.Context, channel domain.Channel, provider string) error {
+	if err := s.checkKnown(ctx, channel, provider); err != nil {
+		return err
+	}
+	if err := s.repo.ForceOpen(ctx, channel, provider, breakerAdmin); err != nil {
+		return err
+	}
+	s.logger.Warn("circuit breaker forced open", zap.String("breaker", breakerKey(channel, provider)))
+	return nil
+}
+
+func (s *CircuitBreakerService) Reset(ctx context.Context, channel domain.Channel, provider string) error {
+	if err := s.checkKnown(ctx, channel, provider); err != nil {
+		return err
+	}
+	if err := s.repo.Reset(ctx, channel, provider, breakerAdmin); err != nil {
+		return err
+	}
+	s.logger.Info("circuit breaker reset", zap.String("breaker", breakerKey(channel, provider)))
+	return nil
+}
+
+// checkKnown guards against typos: a breaker is known once any worker has
+// reported it, within the day the reports are kept.
+func (s *CircuitBreakerService) checkKnown(ctx context.Context, channel domain.Channel, provider string) error {
+	breakers, err := s.repo.ListStatesSince(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	for _, b := range breakers {
+		if b.Channel == channel && b.Provider == provider {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", domain.ErrBreakerNotFound, breakerKey(channel, provider))
+}
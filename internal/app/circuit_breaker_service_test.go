@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func TestCircuitBreakerService_ControlsKnownBreakers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCircuitBreakerRepo()
+	NewBreakerSync(newFakeBreakers("sms/twilio"), repo, "worker-1", false, zap.NewNop()).sync(ctx)
+	svc := NewCircuitBreakerService(repo, zap.NewNop())
+
+	require.NoError(t, svc.ForceOpen(ctx, domain.ChannelSMS, "twilio"))
+	assert.ErrorIs(t, svc.ForceOpen(ctx, domain.ChannelSMS, "twillio"), domain.ErrBreakerNotFound)
+
+	statuses, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	require.NotNil(t, statuses[0].Control)
+	assert.True(t, statuses[0].Control.ForcedOpen)
+	assert.Equal(t, "api", statuses[0].Control.UpdatedBy)
+	assert.Len(t, statuses[0].Workers, 1)
+
+	require.NoError(t, svc.Reset(ctx, domain.ChannelSMS, "twilio"))
+	statuses, _ = svc.List(ctx)
+	assert.False(t, statuses[0].Control.ForcedOpen)
+	assert.NotNil(t, statuses[0].Control.ResetAt)
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// ContactService manages recipient profiles: users and the addresses
+// notifications to them are resolved to.
+type ContactService struct {
+	repo   port.ContactRepository
+	logger *zap.Logger
+}
+
+func NewContactService(repo port.ContactRepository, logger *zap.Logger) *ContactService {
+	return &ContactService{repo: repo, logger: logger}
+}
+
+type CreateUserInput struct {
+	ID        string
+	Locale    string
+	TimeZone  string
+	Addresses []AddAddressInput
+}
+
+type AddAddressInput struct {
+	Channel  domain.Channel
+	Address  string
+	Verified bool
+}
+
+// UpdateAddressInput changes an address's flags. Nil fields are left as
+// they are.
+type UpdateAddressInput struct {
+	Verified *bool
+	Active   *bool
+}
+
+func (s *ContactService) CreateUser(ctx context.Context, input CreateUserInput) (*domain.User, error) {
+	user, err := domain.NewUser(input.ID, input.Locale, input.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range input.Addresses {
+		a, err := domain.NewContactAddress(user.ID, in.Channel, in.Address, in.Verified)
+		if err != nil {
+			return nil, err
+		}
+		user.Addresses = append(user.Addresses, a)
+	}
+
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user created", zap.String("user_id", user.ID), zap.Int("addresses", len(user.Addresses)))
+	return user, nil
+}
+
+func (s *ContactService) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	return s.repo.GetUser(ctx, id)
+}
+
+func (s *ContactService) ListUsers(ctx context.Context, cursor *string, limit int) ([]*domain.User, error) {
+	return s.repo.ListUsers(ctx, cursor, limit)
+}
+
+func (s *ContactService) UpdateUser(ctx context.Context, id, locale, timeZone string) (*domain.User, error) {
+	user, err := s.repo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.Update(locale, timeZone); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *ContactService) DeleteUser(ctx context.Context, id string) error {
+	if err := s.repo.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("user deleted", zap.String("user_id", id))
+	return nil
+}
+
+func (s *ContactService) AddAddress(ctx context.Context, userID string, input AddAddressInput) (*domain.ContactAddress, error) {
+	a, err := domain.NewContactAddress(userID, input.Channel, input.Address, input.Verified)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddAddress(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *ContactService) UpdateAddress(ctx context.Context, userID string, id uuid.UUID, input UpdateAddressInput) (*domain.ContactAddress, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range user.Addresses {
+		if a.ID != id {
+			continue
+		}
+		if input.Verified != nil {
+			a.Verified = *input.Verified
+		}
+		if input.Active != nil {
+			a.Active = *input.Active
+		}
+		a.UpdatedAt = time.Now().UTC()
+		if err := s.repo.UpdateAddress(ctx, a); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+	return nil, domain.ErrAddressNotFound
+}
+
+func (s *ContactService) DeleteAddress(ctx context.Context, userID string, id uuid.UUID) error {
+	return s.repo.DeleteAddress(ctx, userID, id)
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newTestContactService() (*ContactService, *mockContactRepo) {
+	repo := newMockContactRepo()
+	return NewContactService(repo, zap.NewNop()), repo
+}
+
+func TestContactService_CreateUser(t *testing.T) {
+	svc, _ := newTestContactService()
+
+	user, err := svc.CreateUser(context.Background(), CreateUserInput{
+		ID:       "user-42",
+		Locale:   "en-GB",
+		TimeZone: "Europe/London",
+		Addresses: []AddAddressInput{
+			{Channel: domain.ChannelEmail, Address: "user@example.com", Verified: true},
+			{Channel: domain.ChannelPush, Address: "device-token"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, user.Addresses, 2)
+
+	stored, err := svc.GetUser(context.Background(), "user-42")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/London", stored.TimeZone)
+
+	_, err = svc.CreateUser(context.Background(), CreateUserInput{ID: "user-42"})
+	assert.ErrorIs(t, err, domain.ErrDuplicateUser)
+}
+
+func TestContactService_CreateUser_InvalidAddress(t *testing.T) {
+	svc, repo := newTestContactService()
+
+	_, err := svc.CreateUser(context.Background(), CreateUserInput{
+		ID:        "user-42",
+		Addresses: []AddAddressInput{{Channel: domain.ChannelSMS, Address: "12345"}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidRecipient)
+	assert.Empty(t, repo.users)
+}
+
+func TestContactService_UpdateAddress(t *testing.T) {
+	svc, _ := newTestContactService()
+	_, err := svc.CreateUser(context.Background(), CreateUserInput{ID: "user-42"})
+	require.NoError(t, err)
+
+	a, err := svc.AddAddress(context.Background(), "user-42", AddAddressInput{Channel: domain.ChannelPush, Address: "device-token"})
+	require.NoError(t, err)
+	assert.False(t, a.Verified)
+
+	inactive := false
+	verified := true
+	updated, err := svc.UpdateAddress(context.Background(), "user-42", a.ID, UpdateAddressInput{Verified: &verified, Active: &inactive})
+	require.NoError(t, err)
+	assert.True(t, updated.Verified)
+	assert.False(t, updated.Active)
+
+	_, err = svc.UpdateAddress(context.Background(), "user-42", uuid.Must(uuid.NewV7()), UpdateAddressInput{})
+	assert.ErrorIs(t, err, domain.ErrAddressNotFound)
+
+	_, err = svc.AddAddress(context.Background(), "nobody", AddAddressInput{Channel: domain.ChannelPush, Address: "device-token"})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestContactService_UpdateUser(t *testing.T) {
+	svc, _ := newTestContactService()
+	_, err := svc.CreateUser(context.Background(), CreateUserInput{ID: "user-42", Locale: "en"})
+	require.NoError(t, err)
+
+	user, err := svc.UpdateUser(context.Background(), "user-42", "tr-TR", "Europe/Istanbul")
+	require.NoError(t, err)
+	assert.Equal(t, "tr-TR", user.Locale)
+
+	_, err = svc.UpdateUser(context.Background(), "user-42", "", "Nowhere/Special")
+	assert.ErrorIs(t, err, domain.ErrInvalidUser)
+}
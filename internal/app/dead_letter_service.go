@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+const maxRedriveBatch = 1000
+
+type DeadLetterService struct {
+	repo   port.DeadLetterRepository
+	logger *zap.Logger
+}
+
+func NewDeadLetterService(repo port.DeadLetterRepository, logger *zap.Logger) *DeadLetterService {
+	return &DeadLetterService{repo: repo, logger: logger}
+}
+
+// Record stores an entry read from the dead-letter topic. It is the handler
+// the worker passes to the dead-letter consumer.
+func (s *DeadLetterService) Record(ctx context.Context, d *domain.DeadLetter) error {
+	if err := s.repo.Save(ctx, d); err != nil {
+		return err
+	}
+
+	fields := []zap.Field{
+		zap.String("dead_letter_id", d.ID.String()),
+		zap.String("topic", d.Topic),
+		zap.Int64("offset", d.Offset),
+		zap.String("error", d.Error),
+	}
+	if d.NotificationID != nil {
+		fields = append(fields, zap.String("notification_id", d.NotificationID.String()))
+	}
+	s.logger.Warn("dead letter recorded", fields...)
+
+	return nil
+}
+
+func (s *DeadLetterService) GetByID(ctx context.Context, id uuid.UUID) (*domain.DeadLetter, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *DeadLetterService) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *DeadLetterService) Redrive(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Redrive(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("dead letter redriven", zap.String("dead_letter_id", id.String()))
+	return nil
+}
+
+type RedriveResult struct {
+	Redriven int
+	Skipped  int
+}
+
+// RedriveMatching redrives up to limit entries that match filter and have not
+// been redriven yet. Entries that cannot be redriven are counted as skipped.
+func (s *DeadLetterService) RedriveMatching(ctx context.Context, filter domain.DeadLetterFilter, limit int) (RedriveResult, error) {
+	if limit <= 0 || limit > maxRedriveBatch {
+		limit = maxRedriveBatch
+	}
+
+	notRedriven := false
+	filter.Redriven = &notRedriven
+	filter.PageSize = 100
+
+	var result RedriveResult
+	for result.Redriven+result.Skipped < limit {
+		page, err := s.repo.List(ctx, filter)
+		if err != nil {
+			return result, err
+		}
+
+		for _, d := range page {
+			if result.Redriven+result.Skipped >= limit {
+				break
+			}
+			err := s.repo.Redrive(ctx, d.ID)
+			switch {
+			case err == nil:
+				result.Redriven++
+			case errors.Is(err, domain.ErrDeadLetterNotRedrivable):
+				result.Skipped++
+			default:
+				return result, err
+			}
+		}
+
+		if len(page) < filter.PageSize {
+			break
+		}
+		filter.Cursor = page[len(page)-1].Cursor()
+	}
+
+	s.logger.Info("dead letters redriven by filter",
+		zap.Int("redriven", result.Redriven),
+		zap.Int("skipped", result.Skipped),
+	)
+
+	return result, nil
+}
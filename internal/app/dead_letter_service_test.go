@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newDeadLetter(topic string, notificationID *uuid.UUID) *domain.DeadLetter {
+	return &domain.DeadLetter{
+		ID:             uuid.Must(uuid.NewV7()),
+		NotificationID: notificationID,
+		Topic:          topic,
+		Payload:        []byte(`{}`),
+		Error:          "boom",
+		CreatedAt:      time.Now(),
+	}
+}
+
+func TestDeadLetterService_Redrive(t *testing.T) {
+	repo := newMockDeadLetterRepo()
+	svc := NewDeadLetterService(repo, zap.NewNop())
+
+	nid := uuid.New()
+	d := newDeadLetter("notifications.high", &nid)
+	require.NoError(t, svc.Record(context.Background(), d))
+
+	require.NoError(t, svc.Redrive(context.Background(), d.ID))
+	assert.NotNil(t, d.RedrivenAt)
+
+	err := svc.Redrive(context.Background(), d.ID)
+	assert.ErrorIs(t, err, domain.ErrDeadLetterNotRedrivable)
+}
+
+func TestDeadLetterService_Redrive_UndecodablePayload(t *testing.T) {
+	repo := newMockDeadLetterRepo()
+	svc := NewDeadLetterService(repo, zap.NewNop())
+
+	d := newDeadLetter("notifications.high", nil)
+	require.NoError(t, svc.Record(context.Background(), d))
+
+	err := svc.Redrive(context.Background(), d.ID)
+	assert.ErrorIs(t, err, domain.ErrDeadLetterNotRedrivable)
+}
+
+func TestDeadLetterService_RedriveMatching(t *testing.T) {
+	repo := newMockDeadLetterRepo()
+	svc := NewDeadLetterService(repo, zap.NewNop())
+
+	for range 3 {
+		nid := uuid.New()
+		require.NoError(t, svc.Record(context.Background(), newDeadLetter("notifications.low", &nid)))
+	}
+	require.NoError(t, svc.Record(context.Background(), newDeadLetter("notifications.low", nil)))
+	other := uuid.New()
+	require.NoError(t, svc.Record(context.Background(), newDeadLetter("notifications.high", &other)))
+
+	topic := "notifications.low"
+	result, err := svc.RedriveMatching(context.Background(), domain.DeadLetterFilter{Topic: &topic}, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Redriven)
+	assert.Equal(t, 1, result.Skipped)
+
+	remaining, _ := repo.List(context.Background(), domain.DeadLetterFilter{Redriven: new(bool), PageSize: 10})
+	assert.Len(t, remaining, 2)
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,26 +16,32 @@ import (
 )
 
 type DeliveryService struct {
-	repo        port.NotificationRepository
-	provider    port.DeliveryProvider
-	broadcaster port.StatusBroadcaster
-	metrics     *MetricsCollector
-	logger      *zap.Logger
+	repo         port.NotificationRepository
+	provider     port.DeliveryProvider
+	pushTokens   port.PushTokenRepository
+	suppressions port.SuppressionRepository
+	broadcaster  port.StatusBroadcaster
+	metrics      *MetricsCollector
+	logger       *zap.Logger
 }
 
 func NewDeliveryService(
 	repo port.NotificationRepository,
 	provider port.DeliveryProvider,
+	pushTokens port.PushTokenRepository,
+	suppressions port.SuppressionRepository,
 	broadcaster port.StatusBroadcaster,
 	metrics *MetricsCollector,
 	logger *zap.Logger,
 ) *DeliveryService {
 	return &DeliveryService{
-		repo:        repo,
-		provider:    provider,
-		broadcaster: broadcaster,
-		metrics:     metrics,
-		logger:      logger,
+		repo:         repo,
+		provider:     provider,
+		pushTokens:   pushTokens,
+		suppressions: suppressions,
+		broadcaster:  broadcaster,
+		metrics:      metrics,
+		logger:       logger,
 	}
 }
 
@@ -52,7 +59,15 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 		return err
 	}
 
-	notification, err := s.repo.GetByID(ctx, id)
+	notification, err := s.repo.ClaimForDelivery(ctx, id)
+	if errors.Is(err, domain.ErrClaimConflict) {
+		span.SetAttributes(attribute.Bool("delivery.skipped", true))
+		s.logger.Debug("notification not claimable, skipping",
+			zap.String("id", notificationID),
+			zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+		)
+		return nil
+	}
 	if err != nil {
 		tracing.RecordError(span, err)
 		return err
@@ -61,30 +76,51 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 	span.SetAttributes(
 		attribute.String("notification.channel", string(notification.Channel)),
 		attribute.String("notification.priority", string(notification.Priority)),
-		attribute.String("notification.status", string(notification.Status)),
 		attribute.Int("notification.retry_count", notification.RetryCount),
 	)
 
-	if notification.Status == domain.StatusCancelled || notification.Status == domain.StatusDelivered {
-		span.SetAttributes(attribute.Bool("delivery.skipped", true))
-		return nil
-	}
-
-	notification.MarkProcessing()
-	if err := s.repo.UpdateStatus(ctx, notification); err != nil {
+	suppressed, err := s.isSuppressed(ctx, notification)
+	if err != nil {
+		// Sending without knowing could message someone who opted out, so
+		// hand the notification back to be retried instead.
+		notification.MarkRetrying()
+		if err := s.repo.UpdateStatus(ctx, notification); err != nil {
+			s.logger.Error("failed to update retry status", zap.Error(err))
+		}
+		s.logger.Warn("suppression lookup failed, will retry",
+			zap.String("id", notificationID),
+			zap.Error(err),
+			zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+		)
 		tracing.RecordError(span, err)
 		return err
 	}
+	if suppressed {
+		span.SetAttributes(attribute.Bool("delivery.suppressed", true))
+		s.skip(ctx, notification)
+		return nil
+	}
 
-	resp, sendErr := s.provider.Send(ctx, notification)
+	resp, sendErr := s.send(ctx, notification)
 
 	latency := time.Since(start)
 	span.SetAttributes(attribute.Int64("delivery.latency_ms", latency.Milliseconds()))
 
 	if sendErr != nil {
+		category := port.Classify(sendErr)
+		span.SetAttributes(attribute.String("delivery.error_category", string(category)))
+
+		var providerErr *port.ProviderError
+		if errors.As(sendErr, &providerErr) {
+			notification.RecordProvider(providerErr.Provider)
+			if category == port.CategoryInvalidRecipient && providerErr.Provider != "" {
+				s.suppress(ctx, notification, providerErr)
+			}
+		}
 		notification.IncrementRetry()
 
-		if isTransient(sendErr) && notification.HasRetriesLeft() {
+		if shouldRetry(notification, category.Policy()) {
+			notification.MarkRetrying()
 			span.SetAttributes(
 				attribute.Bool("delivery.will_retry", true),
 				attribute.Int("delivery.retry_count", notification.RetryCount),
@@ -96,6 +132,7 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 			s.logger.Warn("delivery failed, will retry",
 				zap.String("id", notificationID),
 				zap.Int("retry", notification.RetryCount),
+				zap.String("category", string(category)),
 				zap.Error(sendErr),
 				zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
 			)
@@ -114,25 +151,31 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 
 		s.metrics.RecordFailure(string(notification.Channel))
 		s.broadcastStatus(notification)
+		startFallback(ctx, s.repo, s.logger, notification)
 
 		span.SetAttributes(attribute.Bool("delivery.permanently_failed", true))
 		tracing.RecordError(span, sendErr)
 
 		s.logger.Error("delivery permanently failed",
 			zap.String("id", notificationID),
+			zap.String("category", string(category)),
 			zap.Error(sendErr),
 			zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
 		)
-		return nil
+		return fmt.Errorf("%w: %v", domain.ErrDeliveryFailed, sendErr)
 	}
 
-	notification.MarkDelivered(resp.MessageID)
+	notification.RecordProvider(resp.Provider)
+	notification.MarkSent(resp.MessageID)
+	if !resp.AwaitsReceipt {
+		notification.ClearFallbackTimeout()
+	}
 	if err := s.repo.UpdateStatus(ctx, notification); err != nil {
-		s.logger.Error("failed to update delivered status", zap.Error(err))
+		s.logger.Error("failed to update sent status", zap.Error(err))
 	}
 
 	if notification.BatchID != nil {
-		_ = s.repo.IncrementBatchCounter(ctx, *notification.BatchID, domain.StatusDelivered)
+		_ = s.repo.IncrementBatchCounter(ctx, *notification.BatchID, domain.StatusSent)
 	}
 
 	s.metrics.RecordSuccess(string(notification.Channel), latency)
@@ -141,10 +184,12 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 	span.SetAttributes(
 		attribute.Bool("delivery.success", true),
 		attribute.String("delivery.provider_message_id", resp.MessageID),
+		attribute.String("delivery.provider", resp.Provider),
 	)
 
-	s.logger.Info("notification delivered",
+	s.logger.Info("notification sent",
 		zap.String("id", notificationID),
+		zap.String("provider", resp.Provider),
 		zap.String("provider_message_id", resp.MessageID),
 		zap.Duration("latency", latency),
 		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
@@ -153,10 +198,95 @@ func (s *DeliveryService) ProcessDelivery(ctx context.Context, notificationID st
 	return nil
 }
 
+// isSuppressed reports whether the recipient is on the suppression list.
+func (s *DeliveryService) isSuppressed(ctx context.Context, n *domain.Notification) (bool, error) {
+	suppressed, err := s.suppressions.IsSuppressed(ctx, n.Channel, n.Recipient)
+	if err != nil {
+		return false, fmt.Errorf("suppression lookup: %w", err)
+	}
+	return suppressed, nil
+}
+
+// skip records a claimed notification as suppressed without sending it. It
+// isn't a failure, so nothing is retried, but a fallback still starts.
+func (s *DeliveryService) skip(ctx context.Context, n *domain.Notification) {
+	n.MarkSuppressed()
+	if err := s.repo.UpdateStatus(ctx, n); err != nil {
+		s.logger.Error("failed to update suppressed status", zap.Error(err))
+	}
+
+	if n.BatchID != nil {
+		_ = s.repo.IncrementBatchCounter(ctx, *n.BatchID, domain.StatusSuppressed)
+	}
+
+	s.broadcastStatus(n)
+	startFallback(ctx, s.repo, s.logger, n)
+
+	s.logger.Info("notification suppressed",
+		zap.String("id", n.ID.String()),
+		zap.String("channel", string(n.Channel)),
+		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+	)
+}
+
+// send skips the provider for push tokens already known to be unregistered.
+func (s *DeliveryService) send(ctx context.Context, n *domain.Notification) (*port.ProviderResponse, error) {
+	if n.Channel == domain.ChannelPush {
+		invalid, err := s.pushTokens.IsInvalid(ctx, n.Recipient)
+		if err != nil {
+			s.logger.Warn("push token lookup failed", zap.Error(err))
+		}
+		if invalid {
+			return nil, port.NewProviderError(port.CategoryInvalidRecipient, "",
+				fmt.Errorf("%w: token was invalidated by an earlier delivery", domain.ErrUnregisteredToken))
+		}
+	}
+	return s.provider.Send(ctx, n)
+}
+
+// suppress stops further deliveries to a recipient a provider rejected as
+// invalid. Push tokens go on the invalid token list, which clients read to
+// prune their device records; other recipients are suppressed for the
+// channel.
+func (s *DeliveryService) suppress(ctx context.Context, n *domain.Notification, cause *port.ProviderError) {
+	now := time.Now().UTC()
+	var err error
+	if n.Channel == domain.ChannelPush {
+		err = s.pushTokens.Invalidate(ctx, &domain.InvalidPushToken{
+			Token:         n.Recipient,
+			Provider:      cause.Provider,
+			Reason:        cause.Err.Error(),
+			InvalidatedAt: now,
+		})
+	} else {
+		err = s.suppressions.Suppress(ctx, &domain.Suppression{
+			Channel:   n.Channel,
+			Recipient: n.Recipient,
+			Reason:    cause.Err.Error(),
+			Source:    cause.Provider,
+			CreatedAt: now,
+		})
+	}
+	if err != nil {
+		s.logger.Error("failed to suppress recipient", zap.String("channel", string(n.Channel)), zap.Error(err))
+		return
+	}
+	s.logger.Info("recipient suppressed",
+		zap.String("channel", string(n.Channel)),
+		zap.String("provider", cause.Provider),
+		zap.String("code", cause.Code),
+	)
+}
+
 func (s *DeliveryService) broadcastStatus(n *domain.Notification) {
 	s.broadcaster.Broadcast(n.ID.String(), string(n.Status), time.Now().UTC().Format(time.RFC3339))
 }
 
-func isTransient(err error) bool {
-	return errors.Is(err, domain.ErrProviderUnavailable) || errors.Is(err, domain.ErrCircuitOpen)
+// shouldRetry reports whether a failed attempt is retried under the error
+// category's policy. IncrementRetry has already counted the attempt.
+func shouldRetry(n *domain.Notification, policy port.RetryPolicy) bool {
+	if !policy.Retry || !n.HasRetriesLeft() {
+		return false
+	}
+	return policy.MaxRetries == 0 || n.RetryCount <= policy.MaxRetries
 }
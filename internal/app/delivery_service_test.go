@@ -2,8 +2,11 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +18,16 @@ import (
 )
 
 func newTestDeliveryService() (*DeliveryService, *mockNotificationRepo, *mockDeliveryProvider, *mockBroadcaster, *MetricsCollector) {
+	svc, repo, provider, broadcaster, metrics, _ := newTestDeliveryServiceWithTokens()
+	return svc, repo, provider, broadcaster, metrics
+}
+
+func newTestDeliveryServiceWithTokens() (*DeliveryService, *mockNotificationRepo, *mockDeliveryProvider, *mockBroadcaster, *MetricsCollector, *mockPushTokenRepo) {
+	svc, repo, provider, broadcaster, metrics, tokens, _ := newTestDeliveryServiceWithStores()
+	return svc, repo, provider, broadcaster, metrics, tokens
+}
+
+func newTestDeliveryServiceWithStores() (*DeliveryService, *mockNotificationRepo, *mockDeliveryProvider, *mockBroadcaster, *MetricsCollector, *mockPushTokenRepo, *mockSuppressionRepo) {
 	repo := newMockNotificationRepo()
 	provider := &mockDeliveryProvider{
 		response: &port.ProviderResponse{
@@ -24,10 +37,12 @@ func newTestDeliveryService() (*DeliveryService, *mockNotificationRepo, *mockDel
 		},
 	}
 	broadcaster := &mockBroadcaster{}
-	metrics := NewMetricsCollector(repo)
+	metrics := NewMetricsCollector(repo, newMockProviderRateRepo(), newMockCircuitBreakerRepo(), newMockOutboxRepo())
 	logger := zap.NewNop()
-	svc := NewDeliveryService(repo, provider, broadcaster, metrics, logger)
-	return svc, repo, provider, broadcaster, metrics
+	tokens := newMockPushTokenRepo()
+	suppressions := newMockSuppressionRepo()
+	svc := NewDeliveryService(repo, provider, tokens, suppressions, broadcaster, metrics, logger)
+	return svc, repo, provider, broadcaster, metrics, tokens, suppressions
 }
 
 func TestDeliveryService_ProcessDelivery_Success(t *testing.T) {
@@ -41,13 +56,13 @@ func TestDeliveryService_ProcessDelivery_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	updated, _ := repo.GetByID(context.Background(), n.ID)
-	assert.Equal(t, domain.StatusDelivered, updated.Status)
+	assert.Equal(t, domain.StatusSent, updated.Status)
 	assert.NotNil(t, updated.ProviderMessageID)
 	assert.Equal(t, "provider-msg-001", *updated.ProviderMessageID)
 
 	assert.Len(t, broadcaster.broadcasts, 1)
 	assert.Equal(t, n.ID.String(), broadcaster.broadcasts[0].NotificationID)
-	assert.Equal(t, string(domain.StatusDelivered), broadcaster.broadcasts[0].Status)
+	assert.Equal(t, string(domain.StatusSent), broadcaster.broadcasts[0].Status)
 
 	snapshot := metrics.Snapshot(context.Background())
 	assert.Equal(t, int64(1), snapshot.Channels["sms"].Sent)
@@ -69,7 +84,7 @@ func TestDeliveryService_ProcessDelivery_TransientError_WithRetry(t *testing.T)
 
 	updated, _ := repo.GetByID(context.Background(), n.ID)
 	assert.Equal(t, 1, updated.RetryCount)
-	assert.NotEqual(t, domain.StatusFailed, updated.Status)
+	assert.Equal(t, domain.StatusPending, updated.Status)
 
 	snapshot := metrics.Snapshot(context.Background())
 	assert.Equal(t, int64(0), snapshot.Channels["sms"].Failed)
@@ -87,7 +102,7 @@ func TestDeliveryService_ProcessDelivery_TransientError_RetriesExhausted(t *test
 
 	err := svc.ProcessDelivery(context.Background(), n.ID.String())
 
-	require.NoError(t, err)
+	assert.ErrorIs(t, err, domain.ErrDeliveryFailed)
 
 	updated, _ := repo.GetByID(context.Background(), n.ID)
 	assert.Equal(t, domain.StatusFailed, updated.Status)
@@ -108,7 +123,7 @@ func TestDeliveryService_ProcessDelivery_PermanentError(t *testing.T) {
 
 	err := svc.ProcessDelivery(context.Background(), n.ID.String())
 
-	require.NoError(t, err)
+	assert.ErrorIs(t, err, domain.ErrDeliveryFailed)
 
 	updated, _ := repo.GetByID(context.Background(), n.ID)
 	assert.Equal(t, domain.StatusFailed, updated.Status)
@@ -133,7 +148,7 @@ func TestDeliveryService_ProcessDelivery_SkipDelivered(t *testing.T) {
 	svc, repo, _, broadcaster, _ := newTestDeliveryService()
 
 	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
-	n.MarkDelivered("already-delivered")
+	n.MarkSent("already-delivered")
 	_ = repo.Create(context.Background(), n)
 
 	err := svc.ProcessDelivery(context.Background(), n.ID.String())
@@ -177,7 +192,7 @@ func TestDeliveryService_ProcessDelivery_BatchCounterOnSuccess(t *testing.T) {
 	err := svc.ProcessDelivery(context.Background(), n.ID.String())
 	require.NoError(t, err)
 
-	assert.Equal(t, 1, batch.DeliveredCount)
+	assert.Equal(t, 1, batch.SentCount)
 	assert.Equal(t, 1, batch.PendingCount)
 }
 
@@ -198,3 +213,277 @@ func TestDeliveryService_ProcessDelivery_CircuitOpenRetry(t *testing.T) {
 	updated, _ := repo.GetByID(context.Background(), n.ID)
 	assert.Equal(t, 1, updated.RetryCount)
 }
+
+func TestDeliveryService_ProcessDelivery_SkipProcessing(t *testing.T) {
+	svc, repo, provider, broadcaster, _ := newTestDeliveryService()
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	n.MarkProcessing()
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, provider.calls)
+	assert.Len(t, broadcaster.broadcasts, 0)
+}
+
+func TestDeliveryService_ProcessDelivery_RedeliveredMessageSentOnce(t *testing.T) {
+	svc, repo, provider, _, _ := newTestDeliveryService()
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = svc.ProcessDelivery(context.Background(), n.ID.String())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestDeliveryService_ProcessDelivery_RecordsProvider(t *testing.T) {
+	svc, repo, provider, _, _ := newTestDeliveryService()
+	provider.response.Provider = "webhook"
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	require.NoError(t, svc.ProcessDelivery(context.Background(), n.ID.String()))
+
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	require.NotNil(t, updated.Provider)
+	assert.Equal(t, "webhook", *updated.Provider)
+}
+
+func TestDeliveryService_ProcessDelivery_RecordsFailedProvider(t *testing.T) {
+	svc, repo, provider, _, _ := newTestDeliveryService()
+	provider.response = nil
+	provider.err = &port.ProviderError{Provider: "webhook-fallback", Err: domain.ErrProviderUnavailable}
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+	require.ErrorIs(t, err, domain.ErrProviderUnavailable)
+
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	require.NotNil(t, updated.Provider)
+	assert.Equal(t, "webhook-fallback", *updated.Provider)
+}
+
+func TestDeliveryService_ProcessDelivery_UnregisteredTokenIsInvalidated(t *testing.T) {
+	svc, repo, provider, _, _, tokens := newTestDeliveryServiceWithTokens()
+	provider.response = nil
+	provider.err = &port.ProviderError{
+		Provider: "fcm",
+		Category: port.CategoryInvalidRecipient,
+		Err:      fmt.Errorf("%w: fcm UNREGISTERED", domain.ErrUnregisteredToken),
+	}
+
+	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+	require.ErrorIs(t, err, domain.ErrDeliveryFailed)
+
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusFailed, updated.Status)
+
+	invalid, _ := tokens.IsInvalid(context.Background(), "device-token")
+	assert.True(t, invalid)
+	assert.Equal(t, "fcm", tokens.invalid["device-token"].Provider)
+}
+
+func TestDeliveryService_ProcessDelivery_SkipsInvalidatedToken(t *testing.T) {
+	svc, repo, provider, _, _, tokens := newTestDeliveryServiceWithTokens()
+	_ = tokens.Invalidate(context.Background(), &domain.InvalidPushToken{Token: "device-token", Provider: "apns"})
+
+	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+
+	require.ErrorIs(t, err, domain.ErrDeliveryFailed)
+	assert.Zero(t, provider.calls)
+}
+
+func TestDeliveryService_ProcessDelivery_InvalidRecipientIsSuppressed(t *testing.T) {
+	svc, repo, provider, _, _, _, suppressions := newTestDeliveryServiceWithStores()
+	provider.response = nil
+	provider.err = &port.ProviderError{
+		Provider: "twilio",
+		Category: port.CategoryInvalidRecipient,
+		Code:     "21211",
+		Err:      errors.New("invalid 'To' phone number"),
+	}
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+	require.ErrorIs(t, err, domain.ErrDeliveryFailed)
+
+	suppressed, _ := suppressions.IsSuppressed(context.Background(), domain.ChannelSMS, "+90500000000")
+	assert.True(t, suppressed)
+	assert.Equal(t, "twilio", suppressions.entries[suppressionKey(domain.ChannelSMS, "+90500000000")].Source)
+}
+
+func TestDeliveryService_ProcessDelivery_SkipsSuppressedRecipient(t *testing.T) {
+	svc, repo, provider, _, _, _, suppressions := newTestDeliveryServiceWithStores()
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelEmail, Recipient: "gone@example.com", Source: "smtp"})
+
+	batchID := uuid.Must(uuid.NewV7())
+	batch := &domain.NotificationBatch{ID: batchID, TotalCount: 1, PendingCount: 1}
+	repo.batches[batchID] = batch
+
+	n, _ := domain.NewNotification(domain.ChannelEmail, "gone@example.com", "hello", domain.PriorityNormal, nil)
+	n.BatchID = &batchID
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+
+	require.NoError(t, err)
+	assert.Zero(t, provider.calls)
+
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusSuppressed, updated.Status)
+	assert.Zero(t, updated.RetryCount)
+	require.NotNil(t, updated.ErrorMessage)
+	assert.Contains(t, *updated.ErrorMessage, domain.ErrRecipientSuppressed.Error())
+	assert.Equal(t, 0, batch.PendingCount)
+	assert.Equal(t, 1, batch.SuppressedCount)
+}
+
+func TestDeliveryService_ProcessDelivery_SuppressionLookupFailureRetries(t *testing.T) {
+	svc, repo, provider, _, _, _, suppressions := newTestDeliveryServiceWithStores()
+	suppressions.lookupErr = errors.New("connection reset")
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+	require.Error(t, err, "the message is retried rather than sent unchecked")
+	assert.Zero(t, provider.calls)
+
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusPending, updated.Status)
+	assert.Zero(t, updated.RetryCount)
+
+	suppressions.lookupErr = nil
+	require.NoError(t, svc.ProcessDelivery(context.Background(), n.ID.String()))
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestDeliveryService_ProcessDelivery_ExpiredSuppressionIsIgnored(t *testing.T) {
+	svc, repo, provider, _, _, _, suppressions := newTestDeliveryServiceWithStores()
+	expired := time.Now().Add(-time.Minute)
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{
+		Channel: domain.ChannelSMS, Recipient: "+90500000000", Source: "api", ExpiresAt: &expired,
+	})
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+
+	require.NoError(t, svc.ProcessDelivery(context.Background(), n.ID.String()))
+
+	assert.Equal(t, 1, provider.calls)
+	updated, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusSent, updated.Status)
+}
+
+func TestDeliveryService_ProcessDelivery_CategoryRetryLimits(t *testing.T) {
+	tests := []struct {
+		category port.ErrorCategory
+		attempts int
+	}{
+		{port.CategoryTransient, 3},
+		{port.CategoryRateLimited, 3},
+		{port.CategoryQuotaExceeded, 3},
+		{port.CategoryAuthFailure, 2},
+		{port.CategoryContentRejected, 1},
+		{port.CategoryPermanent, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			svc, repo, provider, _, _ := newTestDeliveryService()
+			provider.response = nil
+			provider.err = port.NewProviderError(tt.category, "", errors.New("rejected"))
+
+			n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+			_ = repo.Create(context.Background(), n)
+
+			attempts := 0
+			for {
+				attempts++
+				err := svc.ProcessDelivery(context.Background(), n.ID.String())
+				if errors.Is(err, domain.ErrDeliveryFailed) {
+					break
+				}
+				require.Less(t, attempts, 10)
+			}
+
+			assert.Equal(t, tt.attempts, attempts)
+			updated, _ := repo.GetByID(context.Background(), n.ID)
+			assert.Equal(t, domain.StatusFailed, updated.Status)
+		})
+	}
+}
+
+func TestDeliveryService_ProcessDelivery_PermanentErrorStartsFallback(t *testing.T) {
+	svc, repo, provider, _, _ := newTestDeliveryService()
+
+	provider.response = nil
+	provider.err = &port.ProviderError{Provider: "twilio", Category: port.CategoryInvalidRecipient, Err: errors.New("invalid number")}
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityHigh, nil)
+	step, _ := domain.NewFallbackStep(domain.ChannelEmail, "user@example.com", "hello", 0)
+	require.NoError(t, n.SetFallbacks([]domain.FallbackStep{step}, 0))
+	_ = repo.Create(context.Background(), n)
+
+	err := svc.ProcessDelivery(context.Background(), n.ID.String())
+	assert.ErrorIs(t, err, domain.ErrDeliveryFailed)
+
+	failed, _ := repo.GetByID(context.Background(), n.ID)
+	require.NotNil(t, failed.NextAttemptID)
+
+	next, err := repo.GetByID(context.Background(), *failed.NextAttemptID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChannelEmail, next.Channel)
+	assert.Equal(t, "user@example.com", next.Recipient)
+	assert.Equal(t, domain.StatusPending, next.Status)
+	assert.Equal(t, n.ID, *next.ParentID)
+	assert.Len(t, repo.outbox, 2)
+}
+
+func TestDeliveryService_ProcessDelivery_SentWithoutReceiptEndsTimeout(t *testing.T) {
+	svc, repo, provider, _, _ := newTestDeliveryService()
+
+	n, _ := domain.NewNotification(domain.ChannelEmail, "user@example.com", "hello", domain.PriorityHigh, nil)
+	step, _ := domain.NewFallbackStep(domain.ChannelSMS, "+90500000000", "hello", 0)
+	require.NoError(t, n.SetFallbacks([]domain.FallbackStep{step}, time.Minute))
+	_ = repo.Create(context.Background(), n)
+
+	require.NoError(t, svc.ProcessDelivery(context.Background(), n.ID.String()))
+
+	sent, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Nil(t, sent.FallbackAt, "no receipt will come, so the timeout would only duplicate")
+	assert.False(t, sent.NeedsFallback(time.Now().Add(time.Hour)))
+
+	receipted, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityHigh, nil)
+	require.NoError(t, receipted.SetFallbacks([]domain.FallbackStep{step}, time.Minute))
+	_ = repo.Create(context.Background(), receipted)
+	provider.response.AwaitsReceipt = true
+
+	require.NoError(t, svc.ProcessDelivery(context.Background(), receipted.ID.String()))
+
+	awaiting, _ := repo.GetByID(context.Background(), receipted.ID)
+	assert.NotNil(t, awaiting.FallbackAt)
+	assert.True(t, awaiting.NeedsFallback(time.Now().Add(time.Hour)))
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+// startFallback queues the next step of n's fallback chain if n needs one.
+// Delivery and receipts call it as soon as an attempt fails for good; the
+// scheduler's sweep catches timeouts and anything those calls missed. The
+// repository lets only one caller win, so racing them is harmless.
+func startFallback(ctx context.Context, repo port.NotificationRepository, logger *zap.Logger, n *domain.Notification) {
+	if !n.NeedsFallback(time.Now().UTC()) {
+		return
+	}
+
+	next, err := n.NextAttempt()
+	if err != nil {
+		logger.Error("failed to build fallback attempt", zap.String("id", n.ID.String()), zap.Error(err))
+		return
+	}
+
+	err = repo.CreateFallback(ctx, n, next)
+	if errors.Is(err, domain.ErrClaimConflict) {
+		return
+	}
+	if err != nil {
+		logger.Error("failed to create fallback attempt", zap.String("id", n.ID.String()), zap.Error(err))
+		return
+	}
+	n.NextAttemptID = &next.ID
+
+	logger.Info("fallback attempt started",
+		zap.String("parent_id", n.ChainID().String()),
+		zap.String("from", n.ID.String()),
+		zap.String("id", next.ID.String()),
+		zap.String("channel", string(next.Channel)),
+		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+	)
+}
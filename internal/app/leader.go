@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+const SchedulerLeaseName = "scheduler"
+
+type LeaderElector struct {
+	leases port.LeaseStore
+	name   string
+	holder string
+	ttl    time.Duration
+	leader atomic.Bool
+	logger *zap.Logger
+}
+
+func NewLeaderElector(leases port.LeaseStore, name, holder string, logger *zap.Logger) *LeaderElector {
+	return &LeaderElector{
+		leases: leases,
+		name:   name,
+		holder: holder,
+		ttl:    15 * time.Second,
+		logger: logger,
+	}
+}
+
+func (e *LeaderElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			if e.leader.Swap(false) {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := e.leases.Release(releaseCtx, e.name, e.holder); err != nil {
+					e.logger.Warn("failed to release lease", zap.String("lease", e.name), zap.Error(err))
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *LeaderElector) Holder() string {
+	return e.holder
+}
+
+func (e *LeaderElector) campaign(ctx context.Context) {
+	acquired, err := e.leases.TryAcquire(ctx, e.name, e.holder, e.ttl)
+	if err != nil {
+		e.logger.Error("lease renewal failed", zap.String("lease", e.name), zap.Error(err))
+		acquired = false
+	}
+
+	was := e.leader.Swap(acquired)
+	switch {
+	case acquired && !was:
+		e.logger.Info("acquired lease", zap.String("lease", e.name), zap.String("holder", e.holder))
+	case !acquired && was:
+		e.logger.Warn("lost lease", zap.String("lease", e.name), zap.String("holder", e.holder))
+	}
+}
+
+type LeaseService struct {
+	leases port.LeaseStore
+}
+
+func NewLeaseService(leases port.LeaseStore) *LeaseService {
+	return &LeaseService{leases: leases}
+}
+
+func (s *LeaseService) Get(ctx context.Context, name string) (*domain.Lease, error) {
+	return s.leases.Get(ctx, name)
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func TestLeaderElector_SingleLeader(t *testing.T) {
+	leases := newMockLeaseStore()
+	a := NewLeaderElector(leases, SchedulerLeaseName, "worker-a", zap.NewNop())
+	b := NewLeaderElector(leases, SchedulerLeaseName, "worker-b", zap.NewNop())
+
+	a.campaign(context.Background())
+	b.campaign(context.Background())
+
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	lease, err := NewLeaseService(leases).Get(context.Background(), SchedulerLeaseName)
+	require.NoError(t, err)
+	assert.Equal(t, "worker-a", lease.Holder)
+}
+
+func TestLeaderElector_ReleaseOnShutdown(t *testing.T) {
+	leases := newMockLeaseStore()
+	a := NewLeaderElector(leases, SchedulerLeaseName, "worker-a", zap.NewNop())
+	b := NewLeaderElector(leases, SchedulerLeaseName, "worker-b", zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	a.Run(ctx)
+
+	assert.False(t, a.IsLeader())
+
+	b.campaign(context.Background())
+	assert.True(t, b.IsLeader())
+}
+
+func TestLeaderElector_StepsDownOnStoreError(t *testing.T) {
+	leases := newMockLeaseStore()
+	a := NewLeaderElector(leases, SchedulerLeaseName, "worker-a", zap.NewNop())
+	a.campaign(context.Background())
+	require.True(t, a.IsLeader())
+
+	leases.acquErr = assert.AnError
+	a.campaign(context.Background())
+
+	assert.False(t, a.IsLeader())
+}
+
+func TestLeaseService_NotFound(t *testing.T) {
+	_, err := NewLeaseService(newMockLeaseStore()).Get(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, domain.ErrLeaseNotFound)
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"time"
 
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
 	"github.com/mehmetymw/event-driven-ns/internal/port"
 )
 
 type MetricsCollector struct {
-	repo port.NotificationRepository
+	repo     port.NotificationRepository
+	rates    port.ProviderRateRepository
+	breakers port.CircuitBreakerRepository
+	outbox   port.OutboxRepository
 }
 
-func NewMetricsCollector(repo port.NotificationRepository) *MetricsCollector {
-	return &MetricsCollector{repo: repo}
+func NewMetricsCollector(repo port.NotificationRepository, rates port.ProviderRateRepository, breakers port.CircuitBreakerRepository, outbox port.OutboxRepository) *MetricsCollector {
+	return &MetricsCollector{repo: repo, rates: rates, breakers: breakers, outbox: outbox}
 }
 
 func (m *MetricsCollector) RecordSuccess(channel string, latency time.Duration) {}
@@ -21,13 +25,52 @@ func (m *MetricsCollector) RecordFailure(channel string) {}
 
 type MetricsSnapshot struct {
 	Channels map[string]ChannelSnapshot `json:"channels"`
+	Outbox   *OutboxSnapshot            `json:"outbox,omitempty"`
+}
+
+// OutboxSnapshot counts the outbox rows the relay has yet to publish, and
+// those it parked after repeated publish failures; parked rows are never
+// relayed again without an operator.
+type OutboxSnapshot struct {
+	Pending int64 `json:"pending"`
+	Parked  int64 `json:"parked"`
 }
 
 type ChannelSnapshot struct {
-	Sent         int64   `json:"sent"`
-	Failed       int64   `json:"failed"`
-	AvgLatencyMs float64 `json:"avg_latency_ms"`
-	SuccessRate  float64 `json:"success_rate"`
+	Sent         int64                       `json:"sent"`
+	Failed       int64                       `json:"failed"`
+	AvgLatencyMs float64                     `json:"avg_latency_ms"`
+	SuccessRate  float64                     `json:"success_rate"`
+	Providers    map[string]ProviderSnapshot `json:"providers,omitempty"`
+}
+
+// ProviderSnapshot is a provider's send rate on one channel across the
+// workers that reported it recently: summed when each worker has its own
+// limit, the highest reported when the limit is shared. Rate drops below
+// Ceiling while the provider throttles; PausedUntil is the latest
+// Retry-After still holding a worker back. Breaker is the provider's
+// circuit breaker across the same workers.
+type ProviderSnapshot struct {
+	Rate        float64          `json:"rate_per_second"`
+	Ceiling     float64          `json:"ceiling_per_second"`
+	Shared      bool             `json:"shared"`
+	Throttled   bool             `json:"throttled"`
+	PausedUntil *time.Time       `json:"paused_until,omitempty"`
+	Workers     int              `json:"workers"`
+	Breaker     *BreakerSnapshot `json:"breaker,omitempty"`
+}
+
+// BreakerSnapshot is the worst state any worker's breaker is in, so a
+// breaker open on one worker shows as open. Transitions sums how often the
+// breakers entered each state; OpenUntil is the latest a worker will stay
+// open.
+type BreakerSnapshot struct {
+	State       domain.BreakerState           `json:"state"`
+	ForcedOpen  bool                          `json:"forced_open"`
+	OpenUntil   *time.Time                    `json:"open_until,omitempty"`
+	OpenWorkers int                           `json:"open_workers"`
+	Workers     int                           `json:"workers"`
+	Transitions map[domain.BreakerState]int64 `json:"transitions"`
 }
 
 func (m *MetricsCollector) Snapshot(ctx context.Context) MetricsSnapshot {
@@ -40,23 +83,110 @@ func (m *MetricsCollector) Snapshot(ctx context.Context) MetricsSnapshot {
 	}
 
 	stats, err := m.repo.GetChannelMetrics(ctx)
+	if err == nil {
+		for _, s := range stats {
+			total := s.Sent + s.Failed
+			var successRate float64
+			if total > 0 {
+				successRate = float64(s.Sent) / float64(total) * 100
+			}
+			snapshot.Channels[s.Channel] = ChannelSnapshot{
+				Sent:         s.Sent,
+				Failed:       s.Failed,
+				AvgLatencyMs: s.AvgLatencyMs,
+				SuccessRate:  successRate,
+			}
+		}
+	}
+
+	m.addProviderRates(ctx, snapshot)
+	m.addBreakers(ctx, snapshot)
+	if stats, err := m.outbox.Stats(ctx); err == nil {
+		snapshot.Outbox = &OutboxSnapshot{Pending: stats.Pending, Parked: stats.Parked}
+	}
+	return snapshot
+}
+
+func (m *MetricsCollector) addProviderRates(ctx context.Context, snapshot MetricsSnapshot) {
+	now := time.Now()
+	rates, err := m.rates.ListSince(ctx, now.Add(-3*ProviderRateReportInterval))
 	if err != nil {
-		return snapshot
+		return
 	}
 
-	for _, s := range stats {
-		total := s.Sent + s.Failed
-		var successRate float64
-		if total > 0 {
-			successRate = float64(s.Sent) / float64(total) * 100
+	for _, r := range rates {
+		ch := snapshot.Channels[string(r.Channel)]
+		if ch.Providers == nil {
+			ch.Providers = make(map[string]ProviderSnapshot)
 		}
-		snapshot.Channels[s.Channel] = ChannelSnapshot{
-			Sent:         s.Sent,
-			Failed:       s.Failed,
-			AvgLatencyMs: s.AvgLatencyMs,
-			SuccessRate:  successRate,
+
+		p := ch.Providers[r.Provider]
+		if r.Shared {
+			p.Rate = max(p.Rate, r.Rate)
+			p.Ceiling = max(p.Ceiling, r.Ceiling)
+			p.Shared = true
+		} else {
+			p.Rate += r.Rate
+			p.Ceiling += r.Ceiling
+		}
+		p.Workers++
+		if r.Rate < r.Ceiling {
+			p.Throttled = true
 		}
+		if r.PausedUntil != nil && r.PausedUntil.After(now) {
+			p.Throttled = true
+			if p.PausedUntil == nil || r.PausedUntil.After(*p.PausedUntil) {
+				p.PausedUntil = r.PausedUntil
+			}
+		}
+
+		ch.Providers[r.Provider] = p
+		snapshot.Channels[string(r.Channel)] = ch
 	}
+}
 
-	return snapshot
+var breakerSeverity = map[domain.BreakerState]int{
+	domain.BreakerClosed:   0,
+	domain.BreakerHalfOpen: 1,
+	domain.BreakerOpen:     2,
+}
+
+func (m *MetricsCollector) addBreakers(ctx context.Context, snapshot MetricsSnapshot) {
+	breakers, err := m.breakers.ListStatesSince(ctx, time.Now().Add(-3*BreakerSyncInterval))
+	if err != nil {
+		return
+	}
+
+	for _, b := range breakers {
+		ch := snapshot.Channels[string(b.Channel)]
+		if ch.Providers == nil {
+			ch.Providers = make(map[string]ProviderSnapshot)
+		}
+
+		p := ch.Providers[b.Provider]
+		if p.Breaker == nil {
+			p.Breaker = &BreakerSnapshot{
+				State:       domain.BreakerClosed,
+				Transitions: make(map[domain.BreakerState]int64),
+			}
+		}
+		bs := p.Breaker
+		if breakerSeverity[b.State] > breakerSeverity[bs.State] {
+			bs.State = b.State
+		}
+		if b.State == domain.BreakerOpen {
+			bs.OpenWorkers++
+		}
+		bs.ForcedOpen = bs.ForcedOpen || b.ForcedOpen
+		if b.OpenUntil != nil && (bs.OpenUntil == nil || b.OpenUntil.After(*bs.OpenUntil)) {
+			bs.OpenUntil = b.OpenUntil
+		}
+		for state, n := range b.Transitions {
+			bs.Transitions[state] += n
+		}
+		bs.Workers++
+
+		ch.Providers[b.Provider] = p
+		snapshot.Channels[string(b.Channel)] = ch
+	}
 }
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type staticRateSource []domain.ProviderRate
+
+func (s staticRateSource) ProviderRates() []domain.ProviderRate {
+	return append([]domain.ProviderRate(nil), s...)
+}
+
+func TestMetricsCollector_SumsProviderRatesAcrossWorkers(t *testing.T) {
+	rates := newMockProviderRateRepo()
+	paused := time.Now().Add(time.Minute).UTC()
+
+	NewProviderRateReporter(staticRateSource{
+		{Channel: domain.ChannelSMS, Provider: "twilio", Rate: 50, Ceiling: 100, PausedUntil: &paused},
+		{Channel: domain.ChannelEmail, Provider: "smtp", Rate: 20, Ceiling: 20},
+	}, rates, "worker-1", zap.NewNop()).report(context.Background())
+	NewProviderRateReporter(staticRateSource{
+		{Channel: domain.ChannelSMS, Provider: "twilio", Rate: 100, Ceiling: 100},
+	}, rates, "worker-2", zap.NewNop()).report(context.Background())
+
+	_ = rates.Save(context.Background(), []domain.ProviderRate{{
+		InstanceID: "gone", Channel: domain.ChannelSMS, Provider: "twilio", Rate: 100, Ceiling: 100,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}})
+
+	snapshot := NewMetricsCollector(newMockNotificationRepo(), rates, newMockCircuitBreakerRepo(), newMockOutboxRepo()).Snapshot(context.Background())
+
+	twilio := snapshot.Channels["sms"].Providers["twilio"]
+	assert.Equal(t, 150.0, twilio.Rate)
+	assert.Equal(t, 200.0, twilio.Ceiling)
+	assert.Equal(t, 2, twilio.Workers)
+	assert.True(t, twilio.Throttled)
+	require.NotNil(t, twilio.PausedUntil)
+	assert.Equal(t, paused, *twilio.PausedUntil)
+
+	smtp := snapshot.Channels["email"].Providers["smtp"]
+	assert.Equal(t, 20.0, smtp.Rate)
+	assert.False(t, smtp.Throttled)
+	assert.Nil(t, smtp.PausedUntil)
+
+	assert.Nil(t, snapshot.Channels["push"].Providers)
+}
+
+func TestMetricsCollector_SharedLimitIsNotSummed(t *testing.T) {
+	rates := newMockProviderRateRepo()
+	for _, worker := range []string{"worker-1", "worker-2"} {
+		NewProviderRateReporter(staticRateSource{
+			{Channel: domain.ChannelSMS, Provider: "twilio", Rate: 100, Ceiling: 100, Shared: true},
+		}, rates, worker, zap.NewNop()).report(context.Background())
+	}
+
+	snapshot := NewMetricsCollector(newMockNotificationRepo(), rates, newMockCircuitBreakerRepo(), newMockOutboxRepo()).Snapshot(context.Background())
+
+	twilio := snapshot.Channels["sms"].Providers["twilio"]
+	assert.Equal(t, 100.0, twilio.Rate)
+	assert.Equal(t, 100.0, twilio.Ceiling)
+	assert.True(t, twilio.Shared)
+	assert.Equal(t, 2, twilio.Workers)
+}
+
+func TestMetricsCollector_ReportsBreakersAcrossWorkers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockCircuitBreakerRepo()
+	first := newFakeBreakers("sms/twilio")
+	second := newFakeBreakers("sms/twilio")
+	first.trip("sms/twilio")
+	NewBreakerSync(first, repo, "worker-1", false, zap.NewNop()).sync(ctx)
+	NewBreakerSync(second, repo, "worker-2", false, zap.NewNop()).sync(ctx)
+
+	snapshot := NewMetricsCollector(newMockNotificationRepo(), newMockProviderRateRepo(), repo, newMockOutboxRepo()).Snapshot(ctx)
+
+	breaker := snapshot.Channels["sms"].Providers["twilio"].Breaker
+	require.NotNil(t, breaker)
+	assert.Equal(t, domain.BreakerOpen, breaker.State)
+	assert.Equal(t, 1, breaker.OpenWorkers)
+	assert.Equal(t, 2, breaker.Workers)
+	assert.Equal(t, int64(1), breaker.Transitions[domain.BreakerOpen])
+	assert.NotNil(t, breaker.OpenUntil)
+	assert.Nil(t, snapshot.Channels["email"].Providers)
+}
+
+func TestMetricsCollector_ReportsParkedOutboxRows(t *testing.T) {
+	outbox := newMockOutboxRepo()
+	outbox.pending = []*domain.OutboxMessage{{ID: 3}}
+	outbox.parked[1] = "broker unreachable"
+	outbox.parked[2] = "broker unreachable"
+
+	snapshot := NewMetricsCollector(newMockNotificationRepo(), newMockProviderRateRepo(), newMockCircuitBreakerRepo(), outbox).Snapshot(context.Background())
+
+	require.NotNil(t, snapshot.Outbox)
+	assert.Equal(t, int64(1), snapshot.Outbox.Pending)
+	assert.Equal(t, int64(2), snapshot.Outbox.Parked)
+}
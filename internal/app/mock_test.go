@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,6 +15,7 @@ type mockNotificationRepo struct {
 	mu            sync.Mutex
 	notifications map[uuid.UUID]*domain.Notification
 	batches       map[uuid.UUID]*domain.NotificationBatch
+	groups        map[uuid.UUID]*domain.NotificationGroup
 	createErr     error
 	getByIDErr    error
 	updateErr     error
@@ -22,12 +24,17 @@ type mockNotificationRepo struct {
 	listErr       error
 	dueScheduled  []*domain.Notification
 	stuckItems    []*domain.Notification
+	outbox        []*domain.OutboxMessage
+	// missedGroupLookups makes that many idempotency key lookups miss, as if
+	// a concurrent create had not committed yet.
+	missedGroupLookups int
 }
 
 func newMockNotificationRepo() *mockNotificationRepo {
 	return &mockNotificationRepo{
 		notifications: make(map[uuid.UUID]*domain.Notification),
 		batches:       make(map[uuid.UUID]*domain.NotificationBatch),
+		groups:        make(map[uuid.UUID]*domain.NotificationGroup),
 	}
 }
 
@@ -38,6 +45,7 @@ func (m *mockNotificationRepo) Create(_ context.Context, n *domain.Notification)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.notifications[n.ID] = n
+	m.queueCreated(n)
 	return nil
 }
 
@@ -50,6 +58,7 @@ func (m *mockNotificationRepo) CreateBatch(_ context.Context, batch *domain.Noti
 	m.batches[batch.ID] = batch
 	for _, n := range notifications {
 		m.notifications[n.ID] = n
+		m.queueCreated(n)
 	}
 	return nil
 }
@@ -103,33 +112,132 @@ func (m *mockNotificationRepo) Cancel(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// queueCreated writes the outbox row for a new notification, skipping one
+// suppressed at creation as the postgres repository does.
+func (m *mockNotificationRepo) queueCreated(n *domain.Notification) {
+	if n.Status == domain.StatusSuppressed {
+		return
+	}
+	m.outbox = append(m.outbox, &domain.OutboxMessage{ID: int64(len(m.outbox) + 1), NotificationID: n.ID})
+}
+
 func (m *mockNotificationRepo) IncrementBatchCounter(_ context.Context, batchID uuid.UUID, status domain.Status) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.transferBatchCounter(batchID, domain.StatusPending, status)
+	return nil
+}
+
+func (m *mockNotificationRepo) transferBatchCounter(batchID uuid.UUID, from, to domain.Status) {
 	b, ok := m.batches[batchID]
 	if !ok {
-		return nil
-	}
-	switch status {
-	case domain.StatusDelivered:
-		b.DeliveredCount++
-		b.PendingCount--
-	case domain.StatusFailed:
-		b.FailedCount++
-		b.PendingCount--
-	case domain.StatusCancelled:
-		b.CancelledCount++
-		b.PendingCount--
+		return
+	}
+	counters := map[domain.Status]*int{
+		domain.StatusPending:     &b.PendingCount,
+		domain.StatusSent:        &b.SentCount,
+		domain.StatusDelivered:   &b.DeliveredCount,
+		domain.StatusUndelivered: &b.UndeliveredCount,
+		domain.StatusRead:        &b.ReadCount,
+		domain.StatusFailed:      &b.FailedCount,
+		domain.StatusCancelled:   &b.CancelledCount,
+		domain.StatusSuppressed:  &b.SuppressedCount,
+	}
+	fromCount, toCount := counters[from], counters[to]
+	if fromCount == nil || toCount == nil {
+		return
+	}
+	*fromCount--
+	*toCount++
+}
+
+// GetByProviderMessageID returns a copy so TransitionStatus can detect a
+// concurrent change, as the database would.
+func (m *mockNotificationRepo) GetByProviderMessageID(_ context.Context, provider, providerMessageID string) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, n := range m.notifications {
+		if n.Provider != nil && *n.Provider == provider &&
+			n.ProviderMessageID != nil && *n.ProviderMessageID == providerMessageID {
+			cp := *n
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotificationNotFound
+}
+
+func (m *mockNotificationRepo) TransitionStatus(_ context.Context, n *domain.Notification, from domain.Status) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.notifications[n.ID]
+	if !ok || stored.Status != from {
+		return domain.ErrClaimConflict
+	}
+	m.notifications[n.ID] = n
+	if n.BatchID != nil {
+		m.transferBatchCounter(*n.BatchID, from, n.Status)
 	}
 	return nil
 }
 
-func (m *mockNotificationRepo) ListDueScheduled(_ context.Context, _ int) ([]*domain.Notification, error) {
-	return m.dueScheduled, nil
+func (m *mockNotificationRepo) ListDueScheduled(_ context.Context, _ time.Time, _ *domain.ScheduleCursor, _ int) ([]*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]*domain.Notification, 0, len(m.dueScheduled))
+	for _, n := range m.dueScheduled {
+		if n.Status == domain.StatusScheduled {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) ClaimScheduled(_ context.Context, id uuid.UUID) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.notifications[id]
+	if !ok || n.Status != domain.StatusScheduled {
+		return nil, domain.ErrClaimConflict
+	}
+	n.Status = domain.StatusPending
+	n.UpdatedAt = time.Now().UTC()
+	m.outbox = append(m.outbox, &domain.OutboxMessage{ID: int64(len(m.outbox) + 1), NotificationID: n.ID})
+	return n, nil
+}
+
+func (m *mockNotificationRepo) ClaimForDelivery(_ context.Context, id uuid.UUID) (*domain.Notification, error) {
+	if m.getByIDErr != nil {
+		return nil, m.getByIDErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotificationNotFound
+	}
+	if n.Status != domain.StatusPending {
+		return nil, domain.ErrClaimConflict
+	}
+	n.MarkProcessing()
+	claimed := *n
+	return &claimed, nil
 }
 
-func (m *mockNotificationRepo) ListStuckProcessing(_ context.Context, _ time.Duration, _ int) ([]*domain.Notification, error) {
-	return m.stuckItems, nil
+func (m *mockNotificationRepo) ResetStuckProcessing(_ context.Context, _ time.Duration, _ int) ([]*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reset := make([]*domain.Notification, 0, len(m.stuckItems))
+	for _, n := range m.stuckItems {
+		if n.Status == domain.StatusProcessing {
+			n.Status = domain.StatusPending
+			reset = append(reset, n)
+			m.outbox = append(m.outbox, &domain.OutboxMessage{ID: int64(len(m.outbox) + 1), NotificationID: n.ID})
+		}
+	}
+	return reset, nil
 }
 
 func (m *mockNotificationRepo) GetChannelMetrics(_ context.Context) ([]domain.ChannelStats, error) {
@@ -142,9 +250,9 @@ func (m *mockNotificationRepo) GetChannelMetrics(_ context.Context) ([]domain.Ch
 			channels[ch] = &domain.ChannelStats{Channel: ch}
 		}
 		switch n.Status {
-		case domain.StatusDelivered:
+		case domain.StatusSent, domain.StatusDelivered, domain.StatusRead:
 			channels[ch].Sent++
-		case domain.StatusFailed:
+		case domain.StatusFailed, domain.StatusUndelivered:
 			channels[ch].Failed++
 		}
 	}
@@ -155,6 +263,124 @@ func (m *mockNotificationRepo) GetChannelMetrics(_ context.Context) ([]domain.Ch
 	return result, nil
 }
 
+func (m *mockNotificationRepo) CreateFallback(_ context.Context, from, next *domain.Notification) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.notifications[from.ID]; ok {
+		if stored.NextAttemptID != nil {
+			return domain.ErrClaimConflict
+		}
+		stored.NextAttemptID = &next.ID
+	}
+	m.notifications[next.ID] = next
+	m.outbox = append(m.outbox, &domain.OutboxMessage{ID: int64(len(m.outbox) + 1), NotificationID: next.ID})
+	return nil
+}
+
+func (m *mockNotificationRepo) ListChain(_ context.Context, parentID uuid.UUID) ([]*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var chain []*domain.Notification
+	for _, n := range m.notifications {
+		if n.ID == parentID || (n.ParentID != nil && *n.ParentID == parentID) {
+			chain = append(chain, n)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, domain.ErrNotificationNotFound
+	}
+	sort.Slice(chain, func(i, j int) bool { return chain[i].CreatedAt.Before(chain[j].CreatedAt) })
+	return chain, nil
+}
+
+func (m *mockNotificationRepo) ListFallbacksDue(_ context.Context, now time.Time, after *uuid.UUID, limit int) ([]*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*domain.Notification
+	for _, n := range m.notifications {
+		if n.NeedsFallback(now) && (after == nil || n.ID.String() > after.String()) {
+			due = append(due, n)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID.String() < due[j].ID.String() })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *mockNotificationRepo) CreateGroup(_ context.Context, group *domain.NotificationGroup) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if group.IdempotencyKey != nil {
+		for _, g := range m.groups {
+			if g.IdempotencyKey != nil && *g.IdempotencyKey == *group.IdempotencyKey {
+				return domain.ErrDuplicateIdempotencyKey
+			}
+		}
+	}
+	m.groups[group.ID] = group
+	for _, n := range group.Notifications {
+		m.notifications[n.ID] = n
+		m.queueCreated(n)
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) GetGroup(_ context.Context, groupID uuid.UUID) (*domain.NotificationGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.groups[groupID]
+	if !ok {
+		return nil, domain.ErrGroupNotFound
+	}
+	return g, nil
+}
+
+func (m *mockNotificationRepo) GetGroupByIdempotencyKey(_ context.Context, key string) (*domain.NotificationGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.missedGroupLookups > 0 {
+		m.missedGroupLookups--
+		return nil, domain.ErrGroupNotFound
+	}
+	for _, g := range m.groups {
+		if g.IdempotencyKey != nil && *g.IdempotencyKey == key {
+			return g, nil
+		}
+	}
+	return nil, domain.ErrGroupNotFound
+}
+
+func (m *mockNotificationRepo) CancelGroup(_ context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	if m.cancelErr != nil {
+		return nil, m.cancelErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[groupID]; !ok {
+		return nil, domain.ErrGroupNotFound
+	}
+	var cancelled []uuid.UUID
+	for _, n := range m.notifications {
+		if n.GroupID != nil && *n.GroupID == groupID &&
+			(n.Status == domain.StatusPending || n.Status == domain.StatusScheduled) {
+			n.Status = domain.StatusCancelled
+			if n.BatchID != nil {
+				m.transferBatchCounter(*n.BatchID, domain.StatusPending, domain.StatusCancelled)
+			}
+			cancelled = append(cancelled, n.ID)
+		}
+	}
+	return cancelled, nil
+}
+
 type mockQueuePublisher struct {
 	mu             sync.Mutex
 	enqueued       []*domain.Notification
@@ -189,6 +415,128 @@ func (m *mockQueuePublisher) EnqueueScheduled(_ context.Context, _ *domain.Notif
 
 func (m *mockQueuePublisher) Close() error { return nil }
 
+type mockOutboxRepo struct {
+	mu        sync.Mutex
+	pending   []*domain.OutboxMessage
+	published []int64
+	failed    map[int64]string
+	retryAt   map[int64]time.Time
+	parked    map[int64]string
+	claimErr  error
+	// expired is how many published rows are past retention; cutoffs records
+	// each DeletePublished call.
+	expired int
+	cutoffs []time.Time
+}
+
+func newMockOutboxRepo() *mockOutboxRepo {
+	return &mockOutboxRepo{
+		failed:  make(map[int64]string),
+		retryAt: make(map[int64]time.Time),
+		parked:  make(map[int64]string),
+	}
+}
+
+func (m *mockOutboxRepo) ClaimPending(_ context.Context, limit int, _ time.Duration) ([]*domain.OutboxMessage, error) {
+	if m.claimErr != nil {
+		return nil, m.claimErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit > len(m.pending) {
+		limit = len(m.pending)
+	}
+	claimed := m.pending[:limit]
+	m.pending = m.pending[limit:]
+	return claimed, nil
+}
+
+func (m *mockOutboxRepo) MarkPublished(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published = append(m.published, id)
+	return nil
+}
+
+func (m *mockOutboxRepo) MarkFailed(_ context.Context, id int64, errMsg string, retryAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed[id] = errMsg
+	m.retryAt[id] = retryAt
+	return nil
+}
+
+func (m *mockOutboxRepo) Park(_ context.Context, id int64, errMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parked[id] = errMsg
+	return nil
+}
+
+func (m *mockOutboxRepo) DeletePublished(_ context.Context, cutoff time.Time, limit int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cutoffs = append(m.cutoffs, cutoff)
+	deleted := min(limit, m.expired)
+	m.expired -= deleted
+	return deleted, nil
+}
+
+func (m *mockOutboxRepo) Stats(_ context.Context) (domain.OutboxStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return domain.OutboxStats{Pending: int64(len(m.pending)), Parked: int64(len(m.parked))}, nil
+}
+
+type mockLeaseStore struct {
+	mu      sync.Mutex
+	leases  map[string]*domain.Lease
+	acquErr error
+}
+
+func newMockLeaseStore() *mockLeaseStore {
+	return &mockLeaseStore{leases: make(map[string]*domain.Lease)}
+}
+
+func (m *mockLeaseStore) TryAcquire(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if m.acquErr != nil {
+		return false, m.acquErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	l, ok := m.leases[name]
+	if ok && l.Holder != holder && l.Active() {
+		return false, nil
+	}
+	if !ok || l.Holder != holder {
+		l = &domain.Lease{Name: name, Holder: holder, AcquiredAt: now}
+		m.leases[name] = l
+	}
+	l.RenewedAt = now
+	l.ExpiresAt = now.Add(ttl)
+	return true, nil
+}
+
+func (m *mockLeaseStore) Release(_ context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[name]; ok && l.Holder == holder {
+		l.ExpiresAt = time.Now()
+	}
+	return nil
+}
+
+func (m *mockLeaseStore) Get(_ context.Context, name string) (*domain.Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.leases[name]
+	if !ok {
+		return nil, domain.ErrLeaseNotFound
+	}
+	return l, nil
+}
+
 type mockTemplateRepo struct {
 	templates map[uuid.UUID]*domain.Template
 	createErr error
@@ -250,11 +598,16 @@ func (m *mockIdempotencyStore) SetNX(_ context.Context, key, notificationID stri
 }
 
 type mockDeliveryProvider struct {
+	mu       sync.Mutex
+	calls    int
 	response *port.ProviderResponse
 	err      error
 }
 
 func (m *mockDeliveryProvider) Send(_ context.Context, _ *domain.Notification) (*port.ProviderResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
 	return m.response, m.err
 }
 
@@ -278,3 +631,422 @@ func (m *mockBroadcaster) Broadcast(notificationID, status, timestamp string) {
 		Timestamp:      timestamp,
 	})
 }
+
+type mockDeadLetterRepo struct {
+	mu      sync.Mutex
+	entries []*domain.DeadLetter
+}
+
+func newMockDeadLetterRepo() *mockDeadLetterRepo {
+	return &mockDeadLetterRepo{}
+}
+
+func (m *mockDeadLetterRepo) Save(_ context.Context, d *domain.DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.ID == d.ID {
+			return nil
+		}
+	}
+	m.entries = append(m.entries, d)
+	return nil
+}
+
+func (m *mockDeadLetterRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, domain.ErrDeadLetterNotFound
+}
+
+func (m *mockDeadLetterRepo) List(_ context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.DeadLetter
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := m.entries[i]
+		if c := filter.Cursor; c != nil && !e.CreatedAt.Before(c.CreatedAt) &&
+			(!e.CreatedAt.Equal(c.CreatedAt) || e.ID.String() >= c.ID.String()) {
+			continue
+		}
+		if filter.Topic != nil && e.Topic != *filter.Topic {
+			continue
+		}
+		if filter.Redriven != nil && (e.RedrivenAt != nil) != *filter.Redriven {
+			continue
+		}
+		result = append(result, e)
+		if len(result) == filter.PageSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDeadLetterRepo) Redrive(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.ID == id {
+			if !e.CanRedrive() {
+				return domain.ErrDeadLetterNotRedrivable
+			}
+			now := time.Now()
+			e.RedrivenAt = &now
+			return nil
+		}
+	}
+	return domain.ErrDeadLetterNotFound
+}
+
+type mockPushTokenRepo struct {
+	mu      sync.Mutex
+	invalid map[string]*domain.InvalidPushToken
+}
+
+func newMockPushTokenRepo() *mockPushTokenRepo {
+	return &mockPushTokenRepo{invalid: make(map[string]*domain.InvalidPushToken)}
+}
+
+func (m *mockPushTokenRepo) Invalidate(_ context.Context, t *domain.InvalidPushToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalid[t.Token] = t
+	return nil
+}
+
+func (m *mockPushTokenRepo) IsInvalid(_ context.Context, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.invalid[token]
+	return ok, nil
+}
+
+func (m *mockPushTokenRepo) ListInvalid(_ context.Context, since *time.Time, limit int) ([]*domain.InvalidPushToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.InvalidPushToken
+	for _, t := range m.invalid {
+		if since != nil && !t.InvalidatedAt.After(*since) {
+			continue
+		}
+		result = append(result, t)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+type mockSuppressionRepo struct {
+	mu        sync.Mutex
+	entries   map[string]*domain.Suppression
+	lookupErr error
+}
+
+func newMockSuppressionRepo() *mockSuppressionRepo {
+	return &mockSuppressionRepo{entries: make(map[string]*domain.Suppression)}
+}
+
+func suppressionKey(channel domain.Channel, recipient string) string {
+	return string(channel) + ":" + recipient
+}
+
+func (m *mockSuppressionRepo) Suppress(_ context.Context, s *domain.Suppression) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[suppressionKey(s.Channel, s.Recipient)] = s
+	return nil
+}
+
+func (m *mockSuppressionRepo) Remove(_ context.Context, channel domain.Channel, recipient string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := suppressionKey(channel, recipient)
+	if _, ok := m.entries[key]; !ok {
+		return domain.ErrSuppressionNotFound
+	}
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *mockSuppressionRepo) List(_ context.Context, filter domain.SuppressionFilter) ([]*domain.Suppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.Suppression
+	for _, s := range m.entries {
+		if filter.Channel != nil && s.Channel != *filter.Channel {
+			continue
+		}
+		if !filter.IncludeExpired && !s.Active(time.Now()) {
+			continue
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return suppressionKey(result[i].Channel, result[i].Recipient) < suppressionKey(result[j].Channel, result[j].Recipient)
+	})
+	return result, nil
+}
+
+func (m *mockSuppressionRepo) IsSuppressed(_ context.Context, channel domain.Channel, recipient string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookupErr != nil {
+		return false, m.lookupErr
+	}
+	s, ok := m.entries[suppressionKey(channel, recipient)]
+	return ok && s.Active(time.Now()), nil
+}
+
+func (m *mockSuppressionRepo) Suppressed(ctx context.Context, channel domain.Channel, recipients []string) (map[string]bool, error) {
+	suppressed := make(map[string]bool)
+	for _, r := range recipients {
+		if ok, _ := m.IsSuppressed(ctx, channel, r); ok {
+			suppressed[r] = true
+		}
+	}
+	return suppressed, nil
+}
+
+type mockProviderRateRepo struct {
+	mu    sync.Mutex
+	rates []domain.ProviderRate
+}
+
+func newMockProviderRateRepo() *mockProviderRateRepo {
+	return &mockProviderRateRepo{}
+}
+
+func (m *mockProviderRateRepo) Save(_ context.Context, rates []domain.ProviderRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates = append(m.rates, rates...)
+	return nil
+}
+
+func (m *mockProviderRateRepo) ListSince(_ context.Context, since time.Time) ([]domain.ProviderRate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []domain.ProviderRate
+	for _, r := range m.rates {
+		if !r.UpdatedAt.Before(since) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+type mockCircuitBreakerRepo struct {
+	mu       sync.Mutex
+	states   map[string]domain.CircuitBreaker
+	controls map[string]*domain.BreakerControl
+}
+
+func newMockCircuitBreakerRepo() *mockCircuitBreakerRepo {
+	return &mockCircuitBreakerRepo{
+		states:   make(map[string]domain.CircuitBreaker),
+		controls: make(map[string]*domain.BreakerControl),
+	}
+}
+
+func (m *mockCircuitBreakerRepo) SaveStates(_ context.Context, breakers []domain.CircuitBreaker) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range breakers {
+		m.states[b.InstanceID+"/"+breakerKey(b.Channel, b.Provider)] = b
+	}
+	return nil
+}
+
+func (m *mockCircuitBreakerRepo) ListStatesSince(_ context.Context, since time.Time) ([]domain.CircuitBreaker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []domain.CircuitBreaker
+	for _, b := range m.states {
+		if !b.UpdatedAt.Before(since) {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCircuitBreakerRepo) ListControls(_ context.Context) ([]domain.BreakerControl, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []domain.BreakerControl
+	for _, c := range m.controls {
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+func (m *mockCircuitBreakerRepo) control(channel domain.Channel, provider, by string) *domain.BreakerControl {
+	key := breakerKey(channel, provider)
+	c, ok := m.controls[key]
+	if !ok {
+		c = &domain.BreakerControl{Channel: channel, Provider: provider}
+		m.controls[key] = c
+	}
+	c.UpdatedBy = by
+	c.UpdatedAt = time.Now()
+	return c
+}
+
+func (m *mockCircuitBreakerRepo) ForceOpen(_ context.Context, channel domain.Channel, provider, by string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.control(channel, provider, by).ForcedOpen = true
+	return nil
+}
+
+func (m *mockCircuitBreakerRepo) HoldOpen(_ context.Context, channel domain.Channel, provider string, until time.Time, by string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := m.control(channel, provider, by)
+	if c.OpenUntil == nil || c.OpenUntil.Before(until) {
+		c.OpenUntil = &until
+	}
+	return nil
+}
+
+func (m *mockCircuitBreakerRepo) Reset(_ context.Context, channel domain.Channel, provider, by string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := m.control(channel, provider, by)
+	now := time.Now()
+	c.ForcedOpen = false
+	c.OpenUntil = nil
+	c.ResetAt = &now
+	return nil
+}
+
+type mockContactRepo struct {
+	mu    sync.Mutex
+	users map[string]*domain.User
+}
+
+func newMockContactRepo() *mockContactRepo {
+	return &mockContactRepo{users: make(map[string]*domain.User)}
+}
+
+func (m *mockContactRepo) CreateUser(_ context.Context, u *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[u.ID]; ok {
+		return domain.ErrDuplicateUser
+	}
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *mockContactRepo) GetUser(_ context.Context, id string) (*domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (m *mockContactRepo) ListUsers(_ context.Context, cursor *string, limit int) ([]*domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var users []*domain.User
+	for _, u := range m.users {
+		if cursor == nil || u.ID > *cursor {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (m *mockContactRepo) UpdateUser(_ context.Context, u *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[u.ID]; !ok {
+		return domain.ErrUserNotFound
+	}
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *mockContactRepo) DeleteUser(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+func (m *mockContactRepo) AddAddress(_ context.Context, a *domain.ContactAddress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[a.UserID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	for _, existing := range u.Addresses {
+		if existing.Channel == a.Channel && existing.Address == a.Address {
+			return domain.ErrDuplicateAddress
+		}
+	}
+	u.Addresses = append(u.Addresses, a)
+	return nil
+}
+
+func (m *mockContactRepo) UpdateAddress(_ context.Context, a *domain.ContactAddress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.users[a.UserID]; ok {
+		for i, existing := range u.Addresses {
+			if existing.ID == a.ID {
+				u.Addresses[i] = a
+				return nil
+			}
+		}
+	}
+	return domain.ErrAddressNotFound
+}
+
+func (m *mockContactRepo) DeleteAddress(_ context.Context, userID string, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.users[userID]; ok {
+		for i, existing := range u.Addresses {
+			if existing.ID == id {
+				u.Addresses = append(u.Addresses[:i], u.Addresses[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return domain.ErrAddressNotFound
+}
+
+func (m *mockContactRepo) ActiveAddresses(_ context.Context, userID string, channel domain.Channel) ([]*domain.ContactAddress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	var active []*domain.ContactAddress
+	for _, a := range u.Addresses {
+		if a.Channel == channel && a.Active {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
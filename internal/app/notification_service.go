@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,40 +16,70 @@ import (
 )
 
 type NotificationService struct {
-	repo       port.NotificationRepository
-	queue      port.QueuePublisher
-	tmplRepo   port.TemplateRepository
-	idempotent port.IdempotencyStore
-	logger     *zap.Logger
+	repo         port.NotificationRepository
+	tmplRepo     port.TemplateRepository
+	contacts     port.ContactRepository
+	suppressions port.SuppressionRepository
+	idempotent   port.IdempotencyStore
+	broadcaster  port.StatusBroadcaster
+	logger       *zap.Logger
 }
 
 func NewNotificationService(
 	repo port.NotificationRepository,
-	queue port.QueuePublisher,
 	tmplRepo port.TemplateRepository,
+	contacts port.ContactRepository,
+	suppressions port.SuppressionRepository,
 	idempotent port.IdempotencyStore,
+	broadcaster port.StatusBroadcaster,
 	logger *zap.Logger,
 ) *NotificationService {
 	return &NotificationService{
-		repo:       repo,
-		queue:      queue,
-		tmplRepo:   tmplRepo,
-		idempotent: idempotent,
-		logger:     logger,
+		repo:         repo,
+		tmplRepo:     tmplRepo,
+		contacts:     contacts,
+		suppressions: suppressions,
+		idempotent:   idempotent,
+		broadcaster:  broadcaster,
+		logger:       logger,
 	}
 }
 
+// CreateNotificationInput without a Recipient but with a UserID goes to the
+// user's addresses on the channel.
 type CreateNotificationInput struct {
 	Channel           domain.Channel
 	Recipient         string
+	UserID            *string
 	Content           string
 	Priority          domain.Priority
 	ScheduledAt       *time.Time
 	IdempotencyKey    *string
+	OrderingKey       *string
 	TemplateID        *uuid.UUID
 	TemplateVariables map[string]string
+	// Fallbacks are tried in order when the notification fails for good, is
+	// reported undelivered, or isn't delivered within FallbackTimeout.
+	Fallbacks       []FallbackInput
+	FallbackTimeout time.Duration
 }
 
+// FallbackInput is one step of a fallback chain. Without a template or
+// content the step reuses the notification's content, and without variables
+// its template gets the notification's.
+type FallbackInput struct {
+	Channel           domain.Channel
+	Recipient         string
+	Content           string
+	TemplateID        *uuid.UUID
+	TemplateVariables map[string]string
+	Timeout           time.Duration
+}
+
+// Create rejects a recipient on the suppression list with
+// ErrRecipientSuppressed, unless the notification has fallbacks: then it is
+// stored as suppressed and the chain moves on to its next step. Input sent to
+// a user rather than a recipient goes through CreateToUser.
 func (s *NotificationService) Create(ctx context.Context, input CreateNotificationInput) (*domain.Notification, error) {
 	ctx, span := tracing.Tracer().Start(ctx, "notification.create")
 	defer span.End()
@@ -96,13 +128,31 @@ func (s *NotificationService) Create(ctx context.Context, input CreateNotificati
 	span.SetAttributes(attribute.String("notification.id", notification.ID.String()))
 
 	notification.IdempotencyKey = input.IdempotencyKey
+	notification.OrderingKey = input.OrderingKey
+	notification.UserID = input.UserID
 	notification.TemplateID = input.TemplateID
 	notification.TemplateVariables = input.TemplateVariables
 
+	if err := s.setFallbacks(ctx, notification, input); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	suppressed := s.skipSuppressed(ctx, []*domain.Notification{notification}) > 0
+	if suppressed && len(notification.Fallbacks) == 0 {
+		err := fmt.Errorf("%w: %s to %s", domain.ErrRecipientSuppressed, notification.Channel, notification.Recipient)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
 	if err := s.repo.Create(ctx, notification); err != nil {
 		tracing.RecordError(span, err)
 		return nil, err
 	}
+	if suppressed {
+		span.SetAttributes(attribute.Bool("notification.suppressed", true))
+		startFallback(ctx, s.repo, s.logger, notification)
+	}
 
 	if input.IdempotencyKey != nil {
 		if _, err := s.idempotent.SetNX(ctx, *input.IdempotencyKey, notification.ID.String()); err != nil {
@@ -110,32 +160,144 @@ func (s *NotificationService) Create(ctx context.Context, input CreateNotificati
 		}
 	}
 
-	if notification.ScheduledAt != nil {
-		if err := s.queue.EnqueueScheduled(ctx, notification); err != nil {
+	s.logger.Info("notification created",
+		zap.String("id", notification.ID.String()),
+		zap.String("channel", string(notification.Channel)),
+		zap.String("priority", string(notification.Priority)),
+		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+	)
+
+	return notification, nil
+}
+
+// CreateToUser sends input to the user's addresses on the channel: one
+// notification, or one per active device for push. Several are stored as a
+// group, so they are queued together and a retry with the same idempotency
+// key returns all of them. Suppressed addresses are handled as in Create, and
+// the request is rejected only when every address is suppressed.
+func (s *NotificationService) CreateToUser(ctx context.Context, input CreateNotificationInput) ([]*domain.Notification, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "notification.create_to_user")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("notification.channel", string(input.Channel)),
+		attribute.String("notification.priority", string(input.Priority)),
+	)
+
+	if input.UserID == nil {
+		err := fmt.Errorf("%w: no user", domain.ErrInvalidRecipient)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("notification.user_id", *input.UserID))
+
+	if input.IdempotencyKey != nil {
+		span.SetAttributes(attribute.String("notification.idempotency_key", *input.IdempotencyKey))
+		exists, existingID, err := s.idempotent.Check(ctx, *input.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("idempotency check failed", zap.Error(err))
+		}
+		if exists {
+			span.SetAttributes(attribute.Bool("notification.idempotent_hit", true))
+			id, _ := uuid.Parse(existingID)
+			return s.createdWith(ctx, id)
+		}
+	}
+
+	recipients, err := s.resolveRecipients(ctx, *input.UserID, input.Channel)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	notifications := make([]*domain.Notification, 0, len(recipients))
+	for i, recipient := range recipients {
+		in := input
+		in.Recipient = recipient
+		if i > 0 {
+			in.IdempotencyKey = nil
+		}
+		n, err := s.newNotification(ctx, in)
+		if err != nil {
 			tracing.RecordError(span, err)
 			return nil, err
 		}
+		notifications = append(notifications, n)
+	}
+
+	suppressed := s.skipSuppressed(ctx, notifications)
+	if suppressed == len(notifications) && len(input.Fallbacks) == 0 {
+		err := fmt.Errorf("%w: %s to user %s", domain.ErrRecipientSuppressed, input.Channel, *input.UserID)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Int("notification.count", len(notifications)),
+		attribute.Int("notification.suppressed", suppressed),
+	)
+
+	if len(notifications) == 1 {
+		err = s.repo.Create(ctx, notifications[0])
 	} else {
-		if err := s.queue.Enqueue(ctx, notification); err != nil {
-			tracing.RecordError(span, err)
-			return nil, err
+		var group *domain.NotificationGroup
+		group, err = domain.NewNotificationGroup(notifications, nil)
+		if err == nil {
+			span.SetAttributes(attribute.String("group.id", group.ID.String()))
+			err = s.repo.CreateGroup(ctx, group)
+		}
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	for _, n := range notifications {
+		if n.Status == domain.StatusSuppressed {
+			startFallback(ctx, s.repo, s.logger, n)
 		}
 	}
 
-	s.logger.Info("notification created",
-		zap.String("id", notification.ID.String()),
-		zap.String("channel", string(notification.Channel)),
-		zap.String("priority", string(notification.Priority)),
+	if input.IdempotencyKey != nil {
+		if _, err := s.idempotent.SetNX(ctx, *input.IdempotencyKey, notifications[0].ID.String()); err != nil {
+			s.logger.Error("idempotency set failed", zap.Error(err))
+		}
+	}
+
+	s.logger.Info("notification created for user",
+		zap.String("user_id", *input.UserID),
+		zap.String("channel", string(input.Channel)),
+		zap.Int("count", len(notifications)),
+		zap.Int("suppressed", suppressed),
 		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
 	)
 
-	return notification, nil
+	return notifications, nil
+}
+
+// createdWith returns what CreateToUser stored along with the notification:
+// its group's siblings, or the notification alone.
+func (s *NotificationService) createdWith(ctx context.Context, id uuid.UUID) ([]*domain.Notification, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.GroupID == nil {
+		return []*domain.Notification{n}, nil
+	}
+	group, err := s.repo.GetGroup(ctx, *n.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	return group.Notifications, nil
 }
 
 type CreateBatchInput struct {
 	Notifications []CreateNotificationInput
 }
 
+// CreateBatch stores notifications to suppressed recipients as suppressed
+// rather than rejecting the batch; they are never queued. An entry sent to a
+// user becomes one notification per address it resolves to, so push reaches
+// every device; the entry's idempotency key goes on the first of them.
 func (s *NotificationService) CreateBatch(ctx context.Context, input CreateBatchInput) (*domain.NotificationBatch, []*domain.Notification, error) {
 	ctx, span := tracing.Tracer().Start(ctx, "notification.create_batch")
 	defer span.End()
@@ -152,69 +314,178 @@ func (s *NotificationService) CreateBatch(ctx context.Context, input CreateBatch
 	}
 
 	batch := &domain.NotificationBatch{
-		ID:           uuid.Must(uuid.NewV7()),
-		TotalCount:   len(input.Notifications),
-		PendingCount: len(input.Notifications),
-		CreatedAt:    time.Now().UTC(),
+		ID:        uuid.Must(uuid.NewV7()),
+		CreatedAt: time.Now().UTC(),
 	}
 
 	span.SetAttributes(attribute.String("batch.id", batch.ID.String()))
 
 	notifications := make([]*domain.Notification, 0, len(input.Notifications))
 	for _, in := range input.Notifications {
-		content := in.Content
-		if in.TemplateID != nil {
-			tmpl, err := s.tmplRepo.GetByID(ctx, *in.TemplateID)
-			if err != nil {
-				tracing.RecordError(span, err)
-				return nil, nil, err
+		recipients, err := s.recipientsFor(ctx, in)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, nil, err
+		}
+		for i, recipient := range recipients {
+			in := in
+			in.Recipient = recipient
+			if i > 0 {
+				in.IdempotencyKey = nil
 			}
-			rendered, err := tmpl.Render(in.TemplateVariables)
+			n, err := s.newNotification(ctx, in)
 			if err != nil {
 				tracing.RecordError(span, err)
 				return nil, nil, err
 			}
-			content = rendered
+			n.BatchID = &batch.ID
+			notifications = append(notifications, n)
 		}
-
-		n, err := domain.NewNotification(in.Channel, in.Recipient, content, in.Priority, in.ScheduledAt)
-		if err != nil {
-			tracing.RecordError(span, err)
-			return nil, nil, err
-		}
-		n.BatchID = &batch.ID
-		n.IdempotencyKey = in.IdempotencyKey
-		n.TemplateID = in.TemplateID
-		n.TemplateVariables = in.TemplateVariables
-		notifications = append(notifications, n)
 	}
+	if len(notifications) > 1000 {
+		tracing.RecordError(span, domain.ErrBatchTooLarge)
+		return nil, nil, domain.ErrBatchTooLarge
+	}
+	batch.TotalCount = len(notifications)
+	batch.PendingCount = len(notifications)
+
+	batch.SuppressedCount = s.skipSuppressed(ctx, notifications)
+	batch.PendingCount -= batch.SuppressedCount
+	span.SetAttributes(attribute.Int("batch.suppressed", batch.SuppressedCount))
 
 	if err := s.repo.CreateBatch(ctx, batch, notifications); err != nil {
 		tracing.RecordError(span, err)
 		return nil, nil, err
 	}
 
-	for _, n := range notifications {
-		if n.ScheduledAt != nil {
-			_ = s.queue.EnqueueScheduled(ctx, n)
-		} else {
-			_ = s.queue.Enqueue(ctx, n)
-		}
-	}
-
 	s.logger.Info("batch created",
 		zap.String("batch_id", batch.ID.String()),
 		zap.Int("count", batch.TotalCount),
+		zap.Int("suppressed", batch.SuppressedCount),
 		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
 	)
 
 	return batch, notifications, nil
 }
 
+// newNotification renders the input's template, if any, and builds the
+// notification it describes.
+func (s *NotificationService) newNotification(ctx context.Context, in CreateNotificationInput) (*domain.Notification, error) {
+	content := in.Content
+	if in.TemplateID != nil {
+		tmpl, err := s.tmplRepo.GetByID(ctx, *in.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		content, err = tmpl.Render(in.TemplateVariables)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	n, err := domain.NewNotification(in.Channel, in.Recipient, content, in.Priority, in.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	n.IdempotencyKey = in.IdempotencyKey
+	n.OrderingKey = in.OrderingKey
+	n.UserID = in.UserID
+	n.TemplateID = in.TemplateID
+	n.TemplateVariables = in.TemplateVariables
+	if err := s.setFallbacks(ctx, n, in); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// skipSuppressed marks the notifications whose recipient is suppressed, so
+// they are stored but never queued, and returns how many it marked. A failed
+// lookup leaves them to the check at delivery.
+func (s *NotificationService) skipSuppressed(ctx context.Context, notifications []*domain.Notification) int {
+	recipients := make(map[domain.Channel][]string)
+	for _, n := range notifications {
+		recipients[n.Channel] = append(recipients[n.Channel], n.Recipient)
+	}
+
+	suppressed := make(map[domain.Channel]map[string]bool, len(recipients))
+	for channel, list := range recipients {
+		found, err := s.suppressions.Suppressed(ctx, channel, list)
+		if err != nil {
+			s.logger.Warn("suppression lookup failed", zap.String("channel", string(channel)), zap.Error(err))
+			continue
+		}
+		suppressed[channel] = found
+	}
+
+	skipped := 0
+	for _, n := range notifications {
+		if suppressed[n.Channel][n.Recipient] {
+			n.MarkSuppressed()
+			skipped++
+		}
+	}
+	return skipped
+}
+
+// setFallbacks renders and validates every fallback step up front, so a bad
+// step is rejected with the request rather than when the chain reaches it.
+func (s *NotificationService) setFallbacks(ctx context.Context, n *domain.Notification, input CreateNotificationInput) error {
+	if len(input.Fallbacks) == 0 {
+		return nil
+	}
+
+	steps := make([]domain.FallbackStep, 0, len(input.Fallbacks))
+	for _, in := range input.Fallbacks {
+		vars := in.TemplateVariables
+		if vars == nil {
+			vars = input.TemplateVariables
+		}
+
+		content := in.Content
+		if in.TemplateID != nil {
+			tmpl, err := s.tmplRepo.GetByID(ctx, *in.TemplateID)
+			if err != nil {
+				return err
+			}
+			content, err = tmpl.Render(vars)
+			if err != nil {
+				return err
+			}
+		} else if content == "" {
+			content = n.Content
+		}
+
+		step, err := domain.NewFallbackStep(in.Channel, in.Recipient, content, in.Timeout)
+		if err != nil {
+			return err
+		}
+		step.TemplateID = in.TemplateID
+		if in.TemplateID != nil {
+			step.TemplateVariables = vars
+		}
+		steps = append(steps, step)
+	}
+	return n.SetFallbacks(steps, input.FallbackTimeout)
+}
+
 func (s *NotificationService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// GetChain returns the fallback chain the notification belongs to, whichever
+// of its attempts id names.
+func (s *NotificationService) GetChain(ctx context.Context, id uuid.UUID) (*domain.FallbackChain, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.ListChain(ctx, n.ChainID())
+	if err != nil {
+		return nil, err
+	}
+	return &domain.FallbackChain{ParentID: n.ChainID(), Attempts: attempts}, nil
+}
+
 func (s *NotificationService) GetBatch(ctx context.Context, batchID uuid.UUID) (*domain.NotificationBatch, error) {
 	return s.repo.GetBatchByID(ctx, batchID)
 }
@@ -248,6 +519,7 @@ func (s *NotificationService) Cancel(ctx context.Context, id uuid.UUID) error {
 	if n.BatchID != nil {
 		_ = s.repo.IncrementBatchCounter(ctx, *n.BatchID, domain.StatusCancelled)
 	}
+	s.broadcastCancelled(id)
 
 	s.logger.Info("notification cancelled",
 		zap.String("id", id.String()),
@@ -255,3 +527,175 @@ func (s *NotificationService) Cancel(ctx context.Context, id uuid.UUID) error {
 	)
 	return nil
 }
+
+// CreateGroupInput sends one event on several channels. Priority, schedule
+// and idempotency key apply to every sibling; TemplateVariables are the
+// defaults for siblings that don't set their own. With a UserID, channels
+// without a recipient go to the user's addresses on them.
+type CreateGroupInput struct {
+	UserID            *string
+	Priority          domain.Priority
+	ScheduledAt       *time.Time
+	IdempotencyKey    *string
+	TemplateVariables map[string]string
+	Channels          []GroupChannelInput
+}
+
+// GroupChannelInput is one channel of a group: its recipient, and its
+// content or template.
+type GroupChannelInput struct {
+	Channel           domain.Channel
+	Recipient         string
+	Content           string
+	TemplateID        *uuid.UUID
+	TemplateVariables map[string]string
+}
+
+// CreateGroup creates a notification per channel under one group ID, in one
+// transaction. A repeated idempotency key returns the group it created.
+// Siblings to suppressed recipients are stored as suppressed, as in a batch.
+func (s *NotificationService) CreateGroup(ctx context.Context, input CreateGroupInput) (*domain.NotificationGroup, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "notification.create_group")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("group.size", len(input.Channels)))
+
+	if input.IdempotencyKey != nil {
+		span.SetAttributes(attribute.String("notification.idempotency_key", *input.IdempotencyKey))
+		existing, err := s.repo.GetGroupByIdempotencyKey(ctx, *input.IdempotencyKey)
+		if err == nil {
+			span.SetAttributes(attribute.Bool("notification.idempotent_hit", true))
+			return existing, nil
+		}
+		if !errors.Is(err, domain.ErrGroupNotFound) {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+
+	notifications := make([]*domain.Notification, 0, len(input.Channels))
+	seen := make(map[domain.Channel]bool, len(input.Channels))
+	for _, in := range input.Channels {
+		if seen[in.Channel] {
+			err := fmt.Errorf("%w: %s listed twice", domain.ErrInvalidGroup, in.Channel)
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		seen[in.Channel] = true
+
+		recipients := []string{in.Recipient}
+		if in.Recipient == "" && input.UserID != nil {
+			var err error
+			recipients, err = s.resolveRecipients(ctx, *input.UserID, in.Channel)
+			if err != nil {
+				tracing.RecordError(span, err)
+				return nil, err
+			}
+		}
+
+		vars := in.TemplateVariables
+		if vars == nil {
+			vars = input.TemplateVariables
+		}
+		for _, recipient := range recipients {
+			n, err := s.newNotification(ctx, CreateNotificationInput{
+				Channel:           in.Channel,
+				Recipient:         recipient,
+				Content:           in.Content,
+				Priority:          input.Priority,
+				ScheduledAt:       input.ScheduledAt,
+				TemplateID:        in.TemplateID,
+				TemplateVariables: vars,
+			})
+			if err != nil {
+				tracing.RecordError(span, err)
+				return nil, err
+			}
+			n.UserID = input.UserID
+			notifications = append(notifications, n)
+		}
+	}
+
+	group, err := domain.NewNotificationGroup(notifications, input.IdempotencyKey)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	s.skipSuppressed(ctx, group.Notifications)
+	span.SetAttributes(attribute.String("group.id", group.ID.String()))
+
+	if err := s.repo.CreateGroup(ctx, group); err != nil {
+		// A concurrent request with the same key got there first.
+		if errors.Is(err, domain.ErrDuplicateIdempotencyKey) {
+			existing, getErr := s.repo.GetGroupByIdempotencyKey(ctx, *input.IdempotencyKey)
+			if getErr == nil {
+				span.SetAttributes(attribute.Bool("notification.idempotent_hit", true))
+				return existing, nil
+			}
+			err = getErr
+		}
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	s.logger.Info("notification group created",
+		zap.String("group_id", group.ID.String()),
+		zap.Int("count", len(group.Notifications)),
+		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+	)
+
+	return group, nil
+}
+
+// recipientsFor returns the input's recipient, or the addresses of the user
+// it names when it has none.
+func (s *NotificationService) recipientsFor(ctx context.Context, in CreateNotificationInput) ([]string, error) {
+	if in.Recipient != "" || in.UserID == nil {
+		return []string{in.Recipient}, nil
+	}
+	return s.resolveRecipients(ctx, *in.UserID, in.Channel)
+}
+
+// resolveRecipients looks up where a notification to the user on channel
+// goes: every active device for push, one address otherwise.
+func (s *NotificationService) resolveRecipients(ctx context.Context, userID string, channel domain.Channel) ([]string, error) {
+	addresses, err := s.contacts.ActiveAddresses(ctx, userID, channel)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ResolveRecipients(channel, addresses)
+}
+
+func (s *NotificationService) GetGroup(ctx context.Context, groupID uuid.UUID) (*domain.NotificationGroup, error) {
+	return s.repo.GetGroup(ctx, groupID)
+}
+
+// CancelGroup cancels every sibling that is still pending or scheduled, the
+// way Cancel does each one, and returns how many it cancelled. Siblings
+// already on their way are left to finish.
+func (s *NotificationService) CancelGroup(ctx context.Context, groupID uuid.UUID) (int, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "notification.cancel_group")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("group.id", groupID.String()))
+
+	cancelled, err := s.repo.CancelGroup(ctx, groupID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+	for _, id := range cancelled {
+		s.broadcastCancelled(id)
+	}
+
+	s.logger.Info("notification group cancelled",
+		zap.String("group_id", groupID.String()),
+		zap.Int("cancelled", len(cancelled)),
+		zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+	)
+	return len(cancelled), nil
+}
+
+func (s *NotificationService) broadcastCancelled(id uuid.UUID) {
+	s.broadcaster.Broadcast(id.String(), string(domain.StatusCancelled), time.Now().UTC().Format(time.RFC3339))
+}
@@ -13,18 +13,29 @@ import (
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 )
 
-func newTestNotificationService() (*NotificationService, *mockNotificationRepo, *mockQueuePublisher, *mockTemplateRepo, *mockIdempotencyStore) {
+func newTestNotificationService() (*NotificationService, *mockNotificationRepo, *mockTemplateRepo, *mockIdempotencyStore) {
+	svc, repo, tmplRepo, idempotent, _ := newTestNotificationServiceWithContacts()
+	return svc, repo, tmplRepo, idempotent
+}
+
+func newTestNotificationServiceWithContacts() (*NotificationService, *mockNotificationRepo, *mockTemplateRepo, *mockIdempotencyStore, *mockContactRepo) {
+	svc, repo, tmplRepo, idempotent, contacts, _ := newTestNotificationServiceWithStores()
+	return svc, repo, tmplRepo, idempotent, contacts
+}
+
+func newTestNotificationServiceWithStores() (*NotificationService, *mockNotificationRepo, *mockTemplateRepo, *mockIdempotencyStore, *mockContactRepo, *mockSuppressionRepo) {
 	repo := newMockNotificationRepo()
-	queue := newMockQueuePublisher()
 	tmplRepo := newMockTemplateRepo()
+	contacts := newMockContactRepo()
+	suppressions := newMockSuppressionRepo()
 	idempotent := newMockIdempotencyStore()
 	logger := zap.NewNop()
-	svc := NewNotificationService(repo, queue, tmplRepo, idempotent, logger)
-	return svc, repo, queue, tmplRepo, idempotent
+	svc := NewNotificationService(repo, tmplRepo, contacts, suppressions, idempotent, &mockBroadcaster{}, logger)
+	return svc, repo, tmplRepo, idempotent, contacts, suppressions
 }
 
 func TestNotificationService_Create_Success(t *testing.T) {
-	svc, repo, queue, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	n, err := svc.Create(context.Background(), CreateNotificationInput{
 		Channel:   domain.ChannelSMS,
@@ -37,8 +48,8 @@ func TestNotificationService_Create_Success(t *testing.T) {
 	assert.NotNil(t, n)
 	assert.Equal(t, domain.ChannelSMS, n.Channel)
 	assert.Equal(t, domain.StatusPending, n.Status)
-	assert.Len(t, queue.enqueued, 1)
-	assert.Equal(t, n.ID, queue.enqueued[0].ID)
+	require.Len(t, repo.outbox, 1)
+	assert.Equal(t, n.ID, repo.outbox[0].NotificationID)
 
 	stored, err := repo.GetByID(context.Background(), n.ID)
 	require.NoError(t, err)
@@ -46,7 +57,7 @@ func TestNotificationService_Create_Success(t *testing.T) {
 }
 
 func TestNotificationService_Create_Scheduled(t *testing.T) {
-	svc, _, queue, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	scheduledAt := time.Now().Add(1 * time.Hour).UTC()
 	n, err := svc.Create(context.Background(), CreateNotificationInput{
@@ -59,12 +70,11 @@ func TestNotificationService_Create_Scheduled(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, domain.StatusScheduled, n.Status)
-	assert.Len(t, queue.enqueued, 0)
-	assert.Equal(t, 1, queue.scheduledCount)
+	assert.Len(t, repo.outbox, 1)
 }
 
 func TestNotificationService_Create_IdempotencyHit(t *testing.T) {
-	svc, repo, queue, _, idempotent := newTestNotificationService()
+	svc, repo, _, idempotent := newTestNotificationService()
 
 	existing, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "first", domain.PriorityNormal, nil)
 	_ = repo.Create(context.Background(), existing)
@@ -81,11 +91,11 @@ func TestNotificationService_Create_IdempotencyHit(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, existing.ID, n.ID)
-	assert.Len(t, queue.enqueued, 0)
+	assert.Len(t, repo.outbox, 1)
 }
 
 func TestNotificationService_Create_IdempotencyMiss(t *testing.T) {
-	svc, _, queue, _, idempotent := newTestNotificationService()
+	svc, repo, _, idempotent := newTestNotificationService()
 
 	key := "new-idem-key"
 	n, err := svc.Create(context.Background(), CreateNotificationInput{
@@ -98,7 +108,7 @@ func TestNotificationService_Create_IdempotencyMiss(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.NotNil(t, n)
-	assert.Len(t, queue.enqueued, 1)
+	assert.Len(t, repo.outbox, 1)
 
 	storedID, ok := idempotent.keys[key]
 	assert.True(t, ok)
@@ -106,7 +116,7 @@ func TestNotificationService_Create_IdempotencyMiss(t *testing.T) {
 }
 
 func TestNotificationService_Create_WithTemplate(t *testing.T) {
-	svc, _, queue, tmplRepo, _ := newTestNotificationService()
+	svc, repo, tmplRepo, _ := newTestNotificationService()
 
 	tmpl, _ := domain.NewTemplate("welcome", domain.ChannelSMS, "Hello {{.name}}")
 	_ = tmplRepo.Create(context.Background(), tmpl)
@@ -123,11 +133,11 @@ func TestNotificationService_Create_WithTemplate(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, "Hello John", n.Content)
-	assert.Len(t, queue.enqueued, 1)
+	assert.Len(t, repo.outbox, 1)
 }
 
 func TestNotificationService_Create_TemplateNotFound(t *testing.T) {
-	svc, _, _, _, _ := newTestNotificationService()
+	svc, _, _, _ := newTestNotificationService()
 
 	missingID := uuid.Must(uuid.NewV7())
 	_, err := svc.Create(context.Background(), CreateNotificationInput{
@@ -143,7 +153,7 @@ func TestNotificationService_Create_TemplateNotFound(t *testing.T) {
 }
 
 func TestNotificationService_Create_ValidationError(t *testing.T) {
-	svc, _, _, _, _ := newTestNotificationService()
+	svc, _, _, _ := newTestNotificationService()
 
 	_, err := svc.Create(context.Background(), CreateNotificationInput{
 		Channel:   domain.ChannelSMS,
@@ -157,7 +167,7 @@ func TestNotificationService_Create_ValidationError(t *testing.T) {
 }
 
 func TestNotificationService_CreateBatch_Success(t *testing.T) {
-	svc, repo, queue, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	batch, notifications, err := svc.CreateBatch(context.Background(), CreateBatchInput{
 		Notifications: []CreateNotificationInput{
@@ -172,7 +182,7 @@ func TestNotificationService_CreateBatch_Success(t *testing.T) {
 	assert.Equal(t, 3, batch.TotalCount)
 	assert.Equal(t, 3, batch.PendingCount)
 	assert.Len(t, notifications, 3)
-	assert.Len(t, queue.enqueued, 3)
+	assert.Len(t, repo.outbox, 3)
 
 	for _, n := range notifications {
 		assert.NotNil(t, n.BatchID)
@@ -184,7 +194,7 @@ func TestNotificationService_CreateBatch_Success(t *testing.T) {
 }
 
 func TestNotificationService_CreateBatch_Empty(t *testing.T) {
-	svc, _, _, _, _ := newTestNotificationService()
+	svc, _, _, _ := newTestNotificationService()
 
 	_, _, err := svc.CreateBatch(context.Background(), CreateBatchInput{
 		Notifications: []CreateNotificationInput{},
@@ -195,7 +205,7 @@ func TestNotificationService_CreateBatch_Empty(t *testing.T) {
 }
 
 func TestNotificationService_CreateBatch_TooLarge(t *testing.T) {
-	svc, _, _, _, _ := newTestNotificationService()
+	svc, _, _, _ := newTestNotificationService()
 
 	inputs := make([]CreateNotificationInput, 1001)
 	for i := range inputs {
@@ -214,7 +224,7 @@ func TestNotificationService_CreateBatch_TooLarge(t *testing.T) {
 }
 
 func TestNotificationService_Cancel_Success(t *testing.T) {
-	svc, repo, _, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
 	_ = repo.Create(context.Background(), n)
@@ -228,10 +238,10 @@ func TestNotificationService_Cancel_Success(t *testing.T) {
 }
 
 func TestNotificationService_Cancel_AlreadyDelivered(t *testing.T) {
-	svc, repo, _, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
-	n.MarkDelivered("msg-123")
+	n.MarkSent("msg-123")
 	_ = repo.Create(context.Background(), n)
 
 	err := svc.Cancel(context.Background(), n.ID)
@@ -241,7 +251,7 @@ func TestNotificationService_Cancel_AlreadyDelivered(t *testing.T) {
 }
 
 func TestNotificationService_Cancel_NotFound(t *testing.T) {
-	svc, _, _, _, _ := newTestNotificationService()
+	svc, _, _, _ := newTestNotificationService()
 
 	err := svc.Cancel(context.Background(), uuid.Must(uuid.NewV7()))
 
@@ -250,7 +260,7 @@ func TestNotificationService_Cancel_NotFound(t *testing.T) {
 }
 
 func TestNotificationService_GetByID(t *testing.T) {
-	svc, repo, _, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	n, _ := domain.NewNotification(domain.ChannelEmail, "test@example.com", "hello", domain.PriorityHigh, nil)
 	_ = repo.Create(context.Background(), n)
@@ -263,7 +273,7 @@ func TestNotificationService_GetByID(t *testing.T) {
 }
 
 func TestNotificationService_GetBatch(t *testing.T) {
-	svc, repo, _, _, _ := newTestNotificationService()
+	svc, repo, _, _ := newTestNotificationService()
 
 	batch := &domain.NotificationBatch{
 		ID:         uuid.Must(uuid.NewV7()),
@@ -279,10 +289,234 @@ func TestNotificationService_GetBatch(t *testing.T) {
 	assert.Equal(t, 5, result.TotalCount)
 }
 
-func TestNotificationService_Create_EnqueueError(t *testing.T) {
-	svc, _, queue, _, _ := newTestNotificationService()
+func TestNotificationService_Create_WithFallbacks(t *testing.T) {
+	svc, repo, tmplRepo, _ := newTestNotificationService()
+
+	tmpl, _ := domain.NewTemplate("otp-email", domain.ChannelEmail, "Your code is {{.code}}")
+	_ = tmplRepo.Create(context.Background(), tmpl)
 
-	queue.enqueueErr = assert.AnError
+	n, err := svc.Create(context.Background(), CreateNotificationInput{
+		Channel:           domain.ChannelPush,
+		Recipient:         "device-token",
+		Content:           "Code: 1234",
+		Priority:          domain.PriorityHigh,
+		TemplateVariables: map[string]string{"code": "1234"},
+		FallbackTimeout:   5 * time.Minute,
+		Fallbacks: []FallbackInput{
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Timeout: 10 * time.Minute},
+			{Channel: domain.ChannelEmail, Recipient: "user@example.com", TemplateID: &tmpl.ID},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, n.Fallbacks, 2)
+	assert.Equal(t, "Code: 1234", n.Fallbacks[0].Content, "reuses the notification's content")
+	assert.Equal(t, 10*time.Minute, n.Fallbacks[0].Timeout)
+	assert.Equal(t, "Your code is 1234", n.Fallbacks[1].Content, "renders with the notification's variables")
+	require.NotNil(t, n.FallbackAt)
+	assert.Equal(t, n.CreatedAt.Add(5*time.Minute), *n.FallbackAt)
+	assert.Len(t, repo.outbox, 1, "fallbacks are only queued when needed")
+}
+
+func TestNotificationService_Create_InvalidFallback(t *testing.T) {
+	svc, repo, _, _ := newTestNotificationService()
+
+	_, err := svc.Create(context.Background(), CreateNotificationInput{
+		Channel:   domain.ChannelPush,
+		Recipient: "device-token",
+		Content:   "hello",
+		Priority:  domain.PriorityNormal,
+		Fallbacks: []FallbackInput{{Channel: domain.ChannelEmail, Recipient: "not-an-email"}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidRecipient)
+	assert.Empty(t, repo.outbox)
+}
+
+func TestNotificationService_GetChain(t *testing.T) {
+	svc, repo, _, _ := newTestNotificationService()
+
+	n, err := svc.Create(context.Background(), CreateNotificationInput{
+		Channel:   domain.ChannelPush,
+		Recipient: "device-token",
+		Content:   "hello",
+		Priority:  domain.PriorityNormal,
+		Fallbacks: []FallbackInput{{Channel: domain.ChannelSMS, Recipient: "+90500000000"}},
+	})
+	require.NoError(t, err)
+
+	n.MarkFailed("unregistered")
+	next, _ := n.NextAttempt()
+	next.CreatedAt = n.CreatedAt.Add(time.Second)
+	require.NoError(t, repo.CreateFallback(context.Background(), n, next))
+
+	chain, err := svc.GetChain(context.Background(), next.ID)
+	require.NoError(t, err)
+	assert.Equal(t, n.ID, chain.ParentID)
+	require.Len(t, chain.Attempts, 2)
+	assert.Equal(t, n.ID, chain.Attempts[0].ID)
+	assert.Equal(t, domain.StatusPending, chain.Status())
+	assert.Equal(t, 0, chain.RemainingFallbacks())
+}
+
+func TestNotificationService_CreateGroup(t *testing.T) {
+	svc, repo, tmplRepo, _ := newTestNotificationService()
+
+	tmpl, _ := domain.NewTemplate("shipped-email", domain.ChannelEmail, "Order {{.order}} has shipped")
+	_ = tmplRepo.Create(context.Background(), tmpl)
+
+	key := "order-42-shipped"
+	input := CreateGroupInput{
+		Priority:          domain.PriorityNormal,
+		IdempotencyKey:    &key,
+		TemplateVariables: map[string]string{"order": "42"},
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelPush, Recipient: "device-token", Content: "Order 42 shipped"},
+			{Channel: domain.ChannelEmail, Recipient: "user@example.com", TemplateID: &tmpl.ID},
+		},
+	}
+	group, err := svc.CreateGroup(context.Background(), input)
+	require.NoError(t, err)
+
+	require.Len(t, group.Notifications, 2)
+	assert.Equal(t, "Order 42 has shipped", group.Notifications[1].Content)
+	for _, n := range group.Notifications {
+		assert.Equal(t, group.ID, *n.GroupID)
+		assert.Equal(t, domain.PriorityNormal, n.Priority)
+	}
+	assert.Len(t, repo.outbox, 2)
+	assert.Equal(t, domain.GroupInProgress, group.Status())
+
+	again, err := svc.CreateGroup(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, group.ID, again.ID)
+	assert.Len(t, repo.outbox, 2)
+}
+
+func TestNotificationService_CreateGroup_ConcurrentIdempotencyKey(t *testing.T) {
+	svc, repo, _, _ := newTestNotificationService()
+
+	key := "order-42-shipped"
+	input := CreateGroupInput{
+		Priority:       domain.PriorityNormal,
+		IdempotencyKey: &key,
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Content: "Order 42 shipped"},
+		},
+	}
+	group, err := svc.CreateGroup(context.Background(), input)
+	require.NoError(t, err)
+
+	repo.missedGroupLookups = 1
+	again, err := svc.CreateGroup(context.Background(), input)
+	require.NoError(t, err, "losing the insert race returns the winner's group")
+	assert.Equal(t, group.ID, again.ID)
+	assert.Len(t, repo.outbox, 1)
+}
+
+func TestNotificationService_CreateGroup_InvalidSibling(t *testing.T) {
+	svc, repo, _, _ := newTestNotificationService()
+
+	_, err := svc.CreateGroup(context.Background(), CreateGroupInput{
+		Priority: domain.PriorityNormal,
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelPush, Recipient: "device-token", Content: "hello"},
+			{Channel: domain.ChannelSMS, Recipient: "not-a-number", Content: "hello"},
+		},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidRecipient)
+	assert.Empty(t, repo.outbox)
+}
+
+func TestNotificationService_CancelGroup(t *testing.T) {
+	svc, _, _, _ := newTestNotificationService()
+
+	group, err := svc.CreateGroup(context.Background(), CreateGroupInput{
+		Priority: domain.PriorityNormal,
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelPush, Recipient: "device-token", Content: "hello"},
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Content: "hello"},
+		},
+	})
+	require.NoError(t, err)
+	group.Notifications[0].MarkSent("push-1")
+
+	cancelled, err := svc.CancelGroup(context.Background(), group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+
+	broadcasts := svc.broadcaster.(*mockBroadcaster).broadcasts
+	require.Len(t, broadcasts, 1)
+	assert.Equal(t, group.Notifications[1].ID.String(), broadcasts[0].NotificationID)
+	assert.Equal(t, string(domain.StatusCancelled), broadcasts[0].Status)
+
+	stored, _ := svc.GetGroup(context.Background(), group.ID)
+	assert.Equal(t, domain.StatusSent, stored.Notifications[0].Status)
+	assert.Equal(t, domain.StatusCancelled, stored.Notifications[1].Status)
+	assert.Equal(t, domain.GroupCompleted, stored.Status())
+
+	_, err = svc.CancelGroup(context.Background(), uuid.Must(uuid.NewV7()))
+	assert.ErrorIs(t, err, domain.ErrGroupNotFound)
+}
+
+func TestNotificationService_CreateGroup_ToUser(t *testing.T) {
+	svc, repo, _, _, contacts := newTestNotificationServiceWithContacts()
+
+	user, _ := domain.NewUser("user-42", "", "")
+	for _, a := range []struct {
+		channel  domain.Channel
+		address  string
+		verified bool
+	}{
+		{domain.ChannelPush, "phone-token", false},
+		{domain.ChannelPush, "tablet-token", false},
+		{domain.ChannelEmail, "old@example.com", false},
+		{domain.ChannelEmail, "user@example.com", true},
+	} {
+		addr, _ := domain.NewContactAddress(user.ID, a.channel, a.address, a.verified)
+		user.Addresses = append(user.Addresses, addr)
+	}
+	require.NoError(t, contacts.CreateUser(context.Background(), user))
+
+	userID := user.ID
+	group, err := svc.CreateGroup(context.Background(), CreateGroupInput{
+		UserID:   &userID,
+		Priority: domain.PriorityHigh,
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelPush, Content: "New login"},
+			{Channel: domain.ChannelEmail, Content: "New login to your account"},
+		},
+	})
+	require.NoError(t, err)
+
+	var recipients []string
+	for _, n := range group.Notifications {
+		recipients = append(recipients, n.Recipient)
+		assert.Equal(t, "user-42", *n.UserID)
+	}
+	assert.Equal(t, []string{"phone-token", "tablet-token", "user@example.com"}, recipients)
+	assert.Len(t, repo.outbox, 3)
+
+	_, err = svc.CreateGroup(context.Background(), CreateGroupInput{
+		UserID:   &userID,
+		Priority: domain.PriorityHigh,
+		Channels: []GroupChannelInput{{Channel: domain.ChannelSMS, Content: "New login"}},
+	})
+	assert.ErrorIs(t, err, domain.ErrNoActiveAddress)
+
+	unknown := "nobody"
+	_, err = svc.CreateGroup(context.Background(), CreateGroupInput{
+		UserID:   &unknown,
+		Priority: domain.PriorityHigh,
+		Channels: []GroupChannelInput{{Channel: domain.ChannelPush, Content: "New login"}},
+	})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestNotificationService_Create_RejectsSuppressedRecipient(t *testing.T) {
+	svc, repo, _, _, _, suppressions := newTestNotificationServiceWithStores()
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelSMS, Recipient: "+90500000000", Source: "api"})
 
 	_, err := svc.Create(context.Background(), CreateNotificationInput{
 		Channel:   domain.ChannelSMS,
@@ -291,6 +525,186 @@ func TestNotificationService_Create_EnqueueError(t *testing.T) {
 		Priority:  domain.PriorityNormal,
 	})
 
-	require.Error(t, err)
-	assert.ErrorIs(t, err, assert.AnError)
+	require.ErrorIs(t, err, domain.ErrRecipientSuppressed)
+	assert.Empty(t, repo.notifications)
+	assert.Empty(t, repo.outbox)
+
+	// The same recipient on another channel isn't suppressed.
+	_, err = svc.Create(context.Background(), CreateNotificationInput{
+		Channel:   domain.ChannelPush,
+		Recipient: "+90500000000",
+		Content:   "hello",
+		Priority:  domain.PriorityNormal,
+	})
+	require.NoError(t, err)
+}
+
+func TestNotificationService_Create_SuppressedRecipientFallsBack(t *testing.T) {
+	svc, repo, _, _, _, suppressions := newTestNotificationServiceWithStores()
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelSMS, Recipient: "+90500000000", Source: "api"})
+
+	n, err := svc.Create(context.Background(), CreateNotificationInput{
+		Channel:   domain.ChannelSMS,
+		Recipient: "+90500000000",
+		Content:   "hello",
+		Priority:  domain.PriorityNormal,
+		Fallbacks: []FallbackInput{{Channel: domain.ChannelEmail, Recipient: "user@example.com"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusSuppressed, n.Status)
+	require.NotNil(t, n.NextAttemptID, "the chain moves straight on")
+
+	next, err := repo.GetByID(context.Background(), *n.NextAttemptID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChannelEmail, next.Channel)
+	assert.Equal(t, domain.StatusPending, next.Status)
+}
+
+func TestNotificationService_CreateBatch_SkipsSuppressedRecipients(t *testing.T) {
+	svc, repo, _, _, _, suppressions := newTestNotificationServiceWithStores()
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelEmail, Recipient: "gone@example.com", Source: "api"})
+
+	batch, notifications, err := svc.CreateBatch(context.Background(), CreateBatchInput{
+		Notifications: []CreateNotificationInput{
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Content: "msg1", Priority: domain.PriorityNormal},
+			{Channel: domain.ChannelEmail, Recipient: "gone@example.com", Content: "msg2", Priority: domain.PriorityNormal},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.TotalCount)
+	assert.Equal(t, 1, batch.PendingCount)
+	assert.Equal(t, 1, batch.SuppressedCount)
+	assert.Equal(t, domain.StatusPending, notifications[0].Status)
+	assert.Equal(t, domain.StatusSuppressed, notifications[1].Status)
+	assert.Len(t, repo.notifications, 2)
+	require.Len(t, repo.outbox, 1, "the suppressed notification is never queued")
+	assert.Equal(t, notifications[0].ID, repo.outbox[0].NotificationID)
+}
+
+func TestNotificationService_CreateGroup_SkipsSuppressedSibling(t *testing.T) {
+	svc, _, _, _, _, suppressions := newTestNotificationServiceWithStores()
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelSMS, Recipient: "+90500000000", Source: "api"})
+
+	group, err := svc.CreateGroup(context.Background(), CreateGroupInput{
+		Priority: domain.PriorityNormal,
+		Channels: []GroupChannelInput{
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Content: "Order 42 shipped"},
+			{Channel: domain.ChannelEmail, Recipient: "user@example.com", Content: "Order 42 shipped"},
+		},
+	})
+
+	require.NoError(t, err)
+	counts := group.Counts()
+	assert.Equal(t, 1, counts[domain.StatusSuppressed])
+	assert.Equal(t, 1, counts[domain.StatusPending])
+}
+
+func TestNotificationService_CreateToUser(t *testing.T) {
+	svc, repo, _, _, contacts := newTestNotificationServiceWithContacts()
+
+	user, _ := domain.NewUser("user-42", "", "")
+	for _, a := range []struct {
+		channel domain.Channel
+		address string
+	}{
+		{domain.ChannelPush, "phone-token"},
+		{domain.ChannelPush, "tablet-token"},
+		{domain.ChannelEmail, "user@example.com"},
+	} {
+		addr, _ := domain.NewContactAddress(user.ID, a.channel, a.address, true)
+		user.Addresses = append(user.Addresses, addr)
+	}
+	require.NoError(t, contacts.CreateUser(context.Background(), user))
+	userID := user.ID
+
+	sent, err := svc.CreateToUser(context.Background(), CreateNotificationInput{
+		Channel:  domain.ChannelEmail,
+		UserID:   &userID,
+		Content:  "New login to your account",
+		Priority: domain.PriorityHigh,
+	})
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, "user@example.com", sent[0].Recipient)
+	assert.Equal(t, "user-42", *sent[0].UserID)
+	assert.Nil(t, sent[0].GroupID)
+
+	pushKey := "push-login-1"
+	sent, err = svc.CreateToUser(context.Background(), CreateNotificationInput{
+		Channel:        domain.ChannelPush,
+		UserID:         &userID,
+		Content:        "New login",
+		Priority:       domain.PriorityHigh,
+		IdempotencyKey: &pushKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, sent, 2, "push reaches every device")
+	assert.Equal(t, "phone-token", sent[0].Recipient)
+	assert.Equal(t, "tablet-token", sent[1].Recipient)
+	require.NotNil(t, sent[0].GroupID)
+	assert.Equal(t, sent[0].GroupID, sent[1].GroupID)
+	assert.Len(t, repo.outbox, 3)
+
+	again, err := svc.CreateToUser(context.Background(), CreateNotificationInput{
+		Channel:        domain.ChannelPush,
+		UserID:         &userID,
+		Content:        "New login",
+		Priority:       domain.PriorityHigh,
+		IdempotencyKey: &pushKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, again, 2, "a retry returns every device's notification")
+	assert.ElementsMatch(t, []uuid.UUID{sent[0].ID, sent[1].ID}, []uuid.UUID{again[0].ID, again[1].ID})
+	assert.Len(t, repo.outbox, 3)
+
+	key := "login-1"
+	batch, notifications, err := svc.CreateBatch(context.Background(), CreateBatchInput{
+		Notifications: []CreateNotificationInput{
+			{Channel: domain.ChannelPush, UserID: &userID, Content: "New login", Priority: domain.PriorityHigh, IdempotencyKey: &key},
+			{Channel: domain.ChannelSMS, Recipient: "+90500000000", Content: "New login", Priority: domain.PriorityHigh},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, batch.TotalCount)
+	assert.Equal(t, 3, batch.PendingCount)
+	require.Len(t, notifications, 3)
+	assert.Equal(t, "phone-token", notifications[0].Recipient)
+	assert.Equal(t, &key, notifications[0].IdempotencyKey)
+	assert.Equal(t, "tablet-token", notifications[1].Recipient)
+	assert.Nil(t, notifications[1].IdempotencyKey)
+	assert.Equal(t, "user-42", *notifications[1].UserID)
+	assert.Len(t, repo.outbox, 6)
+}
+
+func TestNotificationService_CreateToUser_SkipsSuppressedDevice(t *testing.T) {
+	svc, repo, _, _, contacts, suppressions := newTestNotificationServiceWithStores()
+
+	user, _ := domain.NewUser("user-7", "", "")
+	for _, token := range []string{"phone-token", "tablet-token"} {
+		addr, _ := domain.NewContactAddress(user.ID, domain.ChannelPush, token, true)
+		user.Addresses = append(user.Addresses, addr)
+	}
+	require.NoError(t, contacts.CreateUser(context.Background(), user))
+	userID := user.ID
+	input := CreateNotificationInput{
+		Channel:  domain.ChannelPush,
+		UserID:   &userID,
+		Content:  "New login",
+		Priority: domain.PriorityHigh,
+	}
+
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelPush, Recipient: "tablet-token", Source: "api"})
+
+	sent, err := svc.CreateToUser(context.Background(), input)
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	assert.Equal(t, domain.StatusPending, sent[0].Status)
+	assert.Equal(t, domain.StatusSuppressed, sent[1].Status)
+	assert.Len(t, repo.outbox, 1)
+
+	_ = suppressions.Suppress(context.Background(), &domain.Suppression{Channel: domain.ChannelPush, Recipient: "phone-token", Source: "api"})
+
+	_, err = svc.CreateToUser(context.Background(), input)
+	assert.ErrorIs(t, err, domain.ErrRecipientSuppressed)
 }
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// Outbox rows that fail to publish are retried with exponential backoff,
+// from outboxRetryBase up to outboxRetryMax, and parked after
+// outboxMaxAttempts failures. Published rows are kept for outboxRetention,
+// then deleted every outboxSweepInterval, outboxSweepBatch rows at a time.
+const (
+	outboxRetryBase     = time.Second
+	outboxRetryMax      = 5 * time.Minute
+	outboxMaxAttempts   = 10
+	outboxRetention     = 24 * time.Hour
+	outboxSweepInterval = time.Minute
+	outboxSweepBatch    = 1000
+)
+
+type OutboxRelay struct {
+	outbox    port.OutboxRepository
+	repo      port.NotificationRepository
+	publisher port.QueuePublisher
+	logger    *zap.Logger
+	interval  time.Duration
+	batchSize int
+	lease     time.Duration
+	sweep     time.Duration
+	now       func() time.Time
+}
+
+func NewOutboxRelay(outbox port.OutboxRepository, repo port.NotificationRepository, publisher port.QueuePublisher, logger *zap.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:    outbox,
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		interval:  500 * time.Millisecond,
+		batchSize: 100,
+		lease:     30 * time.Second,
+		sweep:     outboxSweepInterval,
+		now:       time.Now,
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	sweep := time.NewTicker(r.sweep)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sweep.C:
+			r.deletePublished(ctx)
+		case <-ticker.C:
+			for r.relay(ctx) == r.batchSize {
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(ctx context.Context) int {
+	messages, err := r.outbox.ClaimPending(ctx, r.batchSize, r.lease)
+	if err != nil {
+		r.logger.Error("failed to claim outbox messages", zap.Error(err))
+		return 0
+	}
+
+	published := 0
+	for _, m := range messages {
+		if err := r.publish(ctx, m); err != nil {
+			r.logger.Error("failed to publish outbox message",
+				zap.Int64("outbox_id", m.ID),
+				zap.String("notification_id", m.NotificationID.String()),
+				zap.Error(err),
+			)
+			r.fail(ctx, m, err)
+			continue
+		}
+
+		if err := r.outbox.MarkPublished(ctx, m.ID); err != nil {
+			r.logger.Error("failed to mark outbox message published", zap.Int64("outbox_id", m.ID), zap.Error(err))
+			continue
+		}
+		published++
+	}
+
+	if published > 0 {
+		r.logger.Debug("relayed outbox messages", zap.Int("count", published))
+	}
+
+	return published
+}
+
+// deletePublished removes rows published longer than outboxRetention ago,
+// in batches so no single delete holds locks for long.
+func (r *OutboxRelay) deletePublished(ctx context.Context) {
+	cutoff := r.now().Add(-outboxRetention)
+	total := 0
+	for ctx.Err() == nil {
+		deleted, err := r.outbox.DeletePublished(ctx, cutoff, outboxSweepBatch)
+		if err != nil {
+			r.logger.Error("failed to delete published outbox messages", zap.Error(err))
+			break
+		}
+		total += deleted
+		if deleted < outboxSweepBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		r.logger.Debug("deleted published outbox messages", zap.Int("count", total))
+	}
+}
+
+// fail holds the row back for its backoff, or parks it once it has used up
+// its attempts.
+func (r *OutboxRelay) fail(ctx context.Context, m *domain.OutboxMessage, cause error) {
+	attempts := m.Attempts + 1
+	if attempts >= outboxMaxAttempts {
+		r.logger.Error("outbox message parked after repeated publish failures",
+			zap.Int64("outbox_id", m.ID),
+			zap.String("notification_id", m.NotificationID.String()),
+			zap.Int("attempts", attempts),
+		)
+		if err := r.outbox.Park(ctx, m.ID, cause.Error()); err != nil {
+			r.logger.Error("failed to park outbox message", zap.Int64("outbox_id", m.ID), zap.Error(err))
+		}
+		return
+	}
+
+	retryAt := r.now().Add(outboxBackoff(attempts))
+	if err := r.outbox.MarkFailed(ctx, m.ID, cause.Error(), retryAt); err != nil {
+		r.logger.Error("failed to record outbox failure", zap.Int64("outbox_id", m.ID), zap.Error(err))
+	}
+}
+
+// outboxBackoff is the wait before a row's next publish after its attempt'th
+// failure.
+func outboxBackoff(attempt int) time.Duration {
+	if attempt > 20 {
+		return outboxRetryMax
+	}
+	return min(outboxRetryBase<<(attempt-1), outboxRetryMax)
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, m *domain.OutboxMessage) error {
+	if len(m.Carrier) > 0 {
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(m.Carrier))
+	}
+
+	n, err := r.repo.GetByID(ctx, m.NotificationID)
+	if err != nil {
+		return err
+	}
+
+	switch n.Status {
+	case domain.StatusCancelled, domain.StatusSuppressed:
+		return nil
+	case domain.StatusScheduled:
+		return r.publisher.EnqueueScheduled(ctx, n)
+	default:
+		return r.publisher.Enqueue(ctx, n)
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newTestOutboxRelay() (*OutboxRelay, *mockOutboxRepo, *mockNotificationRepo, *mockQueuePublisher) {
+	outbox := newMockOutboxRepo()
+	repo := newMockNotificationRepo()
+	publisher := newMockQueuePublisher()
+	r := NewOutboxRelay(outbox, repo, publisher, zap.NewNop())
+	r.interval = 50 * time.Millisecond
+	return r, outbox, repo, publisher
+}
+
+func TestOutboxRelay_PublishesPending(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityHigh, nil)
+	_ = repo.Create(context.Background(), n)
+	outbox.pending = repo.outbox
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	r.Run(ctx)
+
+	require.Len(t, publisher.enqueued, 1)
+	assert.Equal(t, n.ID, publisher.enqueued[0].ID)
+	assert.Equal(t, []int64{1}, outbox.published)
+}
+
+func TestOutboxRelay_ScheduledUsesEnqueueScheduled(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	future := time.Now().Add(time.Hour)
+	n, _ := domain.NewNotification(domain.ChannelEmail, "test@example.com", "later", domain.PriorityNormal, &future)
+	_ = repo.Create(context.Background(), n)
+	outbox.pending = repo.outbox
+
+	r.relay(context.Background())
+
+	assert.Empty(t, publisher.enqueued)
+	assert.Equal(t, 1, publisher.scheduledCount)
+	assert.Len(t, outbox.published, 1)
+}
+
+func TestOutboxRelay_SkipsCancelled(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "hello", domain.PriorityLow, nil)
+	_ = repo.Create(context.Background(), n)
+	_ = n.Cancel()
+	outbox.pending = repo.outbox
+
+	r.relay(context.Background())
+
+	assert.Empty(t, publisher.enqueued)
+	assert.Len(t, outbox.published, 1)
+}
+
+func TestOutboxRelay_PublishErrorLeavesMessagePending(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	publisher.enqueueErr = assert.AnError
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+	outbox.pending = repo.outbox
+
+	published := r.relay(context.Background())
+
+	assert.Equal(t, 0, published)
+	assert.Empty(t, outbox.published)
+	assert.Contains(t, outbox.failed, int64(1))
+}
+
+func TestOutboxRelay_PublishErrorBacksOff(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	publisher.enqueueErr = assert.AnError
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+	repo.outbox[0].Attempts = 3
+	outbox.pending = repo.outbox
+
+	r.relay(context.Background())
+
+	assert.Equal(t, now.Add(8*time.Second), outbox.retryAt[1])
+	assert.Empty(t, outbox.parked)
+}
+
+func TestOutboxRelay_ParksAfterMaxAttempts(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	publisher.enqueueErr = assert.AnError
+
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+	repo.outbox[0].Attempts = outboxMaxAttempts - 1
+	outbox.pending = repo.outbox
+
+	r.relay(context.Background())
+
+	assert.Contains(t, outbox.parked, int64(1))
+	assert.NotContains(t, outbox.failed, int64(1))
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, outboxBackoff(1))
+	assert.Equal(t, 4*time.Second, outboxBackoff(3))
+	assert.Equal(t, outboxRetryMax, outboxBackoff(12))
+	assert.Equal(t, outboxRetryMax, outboxBackoff(100))
+}
+
+func TestOutboxRelay_SkipsSuppressed(t *testing.T) {
+	r, outbox, repo, publisher := newTestOutboxRelay()
+
+	n, _ := domain.NewNotification(domain.ChannelEmail, "gone@example.com", "hello", domain.PriorityNormal, nil)
+	_ = repo.Create(context.Background(), n)
+	outbox.pending = repo.outbox
+	// Suppressed after its outbox row was written.
+	n.MarkSuppressed()
+
+	r.relay(context.Background())
+
+	assert.Empty(t, publisher.enqueued)
+	assert.Len(t, outbox.published, 1)
+}
+
+func TestOutboxRelay_DeletesPublishedPastRetention(t *testing.T) {
+	r, outbox, _, _ := newTestOutboxRelay()
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	outbox.expired = 2*outboxSweepBatch + 5
+
+	r.deletePublished(context.Background())
+
+	assert.Zero(t, outbox.expired, "one sweep deletes every expired row, a batch at a time")
+	require.Len(t, outbox.cutoffs, 3)
+	assert.Equal(t, now.Add(-outboxRetention), outbox.cutoffs[0])
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// ProviderRateReportInterval is how often a worker saves its provider rates.
+// The metrics API ignores reports older than three intervals.
+const ProviderRateReportInterval = 10 * time.Second
+
+// ProviderRateReporter periodically saves this worker's provider rates so the
+// API, which runs elsewhere, can show them.
+type ProviderRateReporter struct {
+	source   port.ProviderRateSource
+	repo     port.ProviderRateRepository
+	instance string
+	logger   *zap.Logger
+}
+
+func NewProviderRateReporter(source port.ProviderRateSource, repo port.ProviderRateRepository, instance string, logger *zap.Logger) *ProviderRateReporter {
+	return &ProviderRateReporter{
+		source:   source,
+		repo:     repo,
+		instance: instance,
+		logger:   logger,
+	}
+}
+
+func (r *ProviderRateReporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(ProviderRateReportInterval)
+	defer ticker.Stop()
+
+	r.report(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.report(ctx)
+		}
+	}
+}
+
+func (r *ProviderRateReporter) report(ctx context.Context) {
+	rates := r.source.ProviderRates()
+	now := time.Now().UTC()
+	for i := range rates {
+		rates[i].InstanceID = r.instance
+		rates[i].UpdatedAt = now
+	}
+	if err := r.repo.Save(ctx, rates); err != nil {
+		r.logger.Warn("failed to save provider rates", zap.Error(err))
+	}
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// PushTokenService exposes the tokens push providers reported as
+// unregistered, newest first.
+type PushTokenService struct {
+	repo port.PushTokenRepository
+}
+
+func NewPushTokenService(repo port.PushTokenRepository) *PushTokenService {
+	return &PushTokenService{repo: repo}
+}
+
+func (s *PushTokenService) ListInvalid(ctx context.Context, since *time.Time, limit int) ([]*domain.InvalidPushToken, error) {
+	return s.repo.ListInvalid(ctx, since, limit)
+}
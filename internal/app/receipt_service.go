@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/tracing"
+)
+
+// maxReceiptAttempts bounds how often a receipt is re-applied when another
+// receipt for the same message changes the status underneath it.
+const maxReceiptAttempts = 3
+
+// ReceiptService applies provider delivery receipts to the notifications
+// they report on.
+type ReceiptService struct {
+	repo        port.NotificationRepository
+	broadcaster port.StatusBroadcaster
+	logger      *zap.Logger
+}
+
+func NewReceiptService(repo port.NotificationRepository, broadcaster port.StatusBroadcaster, logger *zap.Logger) *ReceiptService {
+	return &ReceiptService{repo: repo, broadcaster: broadcaster, logger: logger}
+}
+
+// Apply advances the matching notification to the receipt's status and
+// reports whether it changed. Stale and duplicate receipts leave it as is.
+func (s *ReceiptService) Apply(ctx context.Context, r *domain.DeliveryReceipt) (*domain.Notification, bool, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "receipt.apply")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("provider.name", r.Provider),
+		attribute.String("receipt.status", string(r.Status)),
+	)
+
+	for attempt := 1; ; attempt++ {
+		n, err := s.repo.GetByProviderMessageID(ctx, r.Provider, r.ProviderMessageID)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, false, err
+		}
+		span.SetAttributes(attribute.String("notification.id", n.ID.String()))
+
+		from := n.Status
+		if !n.ApplyReceipt(r) {
+			span.SetAttributes(attribute.Bool("receipt.applied", false))
+			return n, false, nil
+		}
+
+		err = s.repo.TransitionStatus(ctx, n, from)
+		if errors.Is(err, domain.ErrClaimConflict) && attempt < maxReceiptAttempts {
+			continue
+		}
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, false, err
+		}
+
+		s.broadcaster.Broadcast(n.ID.String(), string(n.Status), time.Now().UTC().Format(time.RFC3339))
+		span.SetAttributes(attribute.Bool("receipt.applied", true))
+
+		s.logger.Info("delivery receipt applied",
+			zap.String("id", n.ID.String()),
+			zap.String("provider", r.Provider),
+			zap.String("from", string(from)),
+			zap.String("status", string(n.Status)),
+			zap.String("trace_id", tracing.TraceIDFromContext(ctx)),
+		)
+		startFallback(ctx, s.repo, s.logger, n)
+		return n, true, nil
+	}
+}
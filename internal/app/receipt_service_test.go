@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newTestReceiptService() (*ReceiptService, *mockNotificationRepo, *mockBroadcaster) {
+	repo := newMockNotificationRepo()
+	broadcaster := &mockBroadcaster{}
+	return NewReceiptService(repo, broadcaster, zap.NewNop()), repo, broadcaster
+}
+
+// sentInBatch stores a notification the provider has accepted, in a batch
+// whose counters reflect that.
+func sentInBatch(t *testing.T, repo *mockNotificationRepo) *domain.Notification {
+	batch := &domain.NotificationBatch{ID: uuid.Must(uuid.NewV7()), TotalCount: 1, PendingCount: 1, CreatedAt: time.Now()}
+	n, err := domain.NewNotification(domain.ChannelSMS, "+90500000000", "hello", domain.PriorityNormal, nil)
+	require.NoError(t, err)
+	n.BatchID = &batch.ID
+	require.NoError(t, repo.CreateBatch(context.Background(), batch, []*domain.Notification{n}))
+
+	n.RecordProvider("twilio")
+	n.MarkSent("SM123")
+	require.NoError(t, repo.IncrementBatchCounter(context.Background(), batch.ID, domain.StatusSent))
+	return n
+}
+
+func TestReceiptService_Apply_AdvancesAndMovesBatchCounters(t *testing.T) {
+	svc, repo, broadcaster := newTestReceiptService()
+	n := sentInBatch(t, repo)
+
+	delivered, _ := domain.NewDeliveryReceipt("twilio", "SM123", domain.StatusDelivered, "")
+	updated, applied, err := svc.Apply(context.Background(), delivered)
+
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, domain.StatusDelivered, updated.Status)
+
+	stored, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusDelivered, stored.Status)
+
+	batch, _ := repo.GetBatchByID(context.Background(), *n.BatchID)
+	assert.Equal(t, 0, batch.PendingCount)
+	assert.Equal(t, 0, batch.SentCount)
+	assert.Equal(t, 1, batch.DeliveredCount)
+
+	require.Len(t, broadcaster.broadcasts, 1)
+	assert.Equal(t, n.ID.String(), broadcaster.broadcasts[0].NotificationID)
+	assert.Equal(t, string(domain.StatusDelivered), broadcaster.broadcasts[0].Status)
+}
+
+func TestReceiptService_Apply_StaleReceiptIsIgnored(t *testing.T) {
+	svc, repo, broadcaster := newTestReceiptService()
+	n := sentInBatch(t, repo)
+
+	read, _ := domain.NewDeliveryReceipt("twilio", "SM123", domain.StatusRead, "")
+	_, applied, err := svc.Apply(context.Background(), read)
+	require.NoError(t, err)
+	require.True(t, applied)
+
+	delivered, _ := domain.NewDeliveryReceipt("twilio", "SM123", domain.StatusDelivered, "")
+	updated, applied, err := svc.Apply(context.Background(), delivered)
+
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Equal(t, domain.StatusRead, updated.Status)
+
+	batch, _ := repo.GetBatchByID(context.Background(), *n.BatchID)
+	assert.Equal(t, 1, batch.ReadCount)
+	assert.Equal(t, 0, batch.DeliveredCount)
+	assert.Len(t, broadcaster.broadcasts, 1)
+}
+
+func TestReceiptService_Apply_Undelivered(t *testing.T) {
+	svc, repo, _ := newTestReceiptService()
+	n := sentInBatch(t, repo)
+
+	undelivered, _ := domain.NewDeliveryReceipt("twilio", "SM123", domain.StatusUndelivered, "twilio error 30005")
+	_, applied, err := svc.Apply(context.Background(), undelivered)
+
+	require.NoError(t, err)
+	assert.True(t, applied)
+
+	stored, _ := repo.GetByID(context.Background(), n.ID)
+	assert.Equal(t, domain.StatusUndelivered, stored.Status)
+	assert.Equal(t, "twilio error 30005", *stored.ErrorMessage)
+
+	batch, _ := repo.GetBatchByID(context.Background(), *n.BatchID)
+	assert.Equal(t, 1, batch.UndeliveredCount)
+	assert.Equal(t, 0, batch.SentCount)
+}
+
+func TestReceiptService_Apply_UnknownMessage(t *testing.T) {
+	svc, repo, _ := newTestReceiptService()
+	sentInBatch(t, repo)
+
+	receipt, _ := domain.NewDeliveryReceipt("other-provider", "SM123", domain.StatusDelivered, "")
+	_, _, err := svc.Apply(context.Background(), receipt)
+
+	assert.ErrorIs(t, err, domain.ErrNotificationNotFound)
+}
+
+func TestReceiptService_Apply_UndeliveredStartsFallback(t *testing.T) {
+	svc, repo, _ := newTestReceiptService()
+	n := sentInBatch(t, repo)
+	step, _ := domain.NewFallbackStep(domain.ChannelEmail, "user@example.com", "hello", 0)
+	require.NoError(t, n.SetFallbacks([]domain.FallbackStep{step}, 0))
+
+	undelivered, _ := domain.NewDeliveryReceipt("twilio", "SM123", domain.StatusUndelivered, "twilio error 30003")
+	_, _, err := svc.Apply(context.Background(), undelivered)
+	require.NoError(t, err)
+
+	stored, _ := repo.GetByID(context.Background(), n.ID)
+	require.NotNil(t, stored.NextAttemptID)
+	next, err := repo.GetByID(context.Background(), *stored.NextAttemptID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChannelEmail, next.Channel)
+	assert.Equal(t, n.ID, *next.ParentID)
+}
@@ -2,27 +2,56 @@ package app
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 	"github.com/mehmetymw/event-driven-ns/internal/port"
+	"github.com/mehmetymw/event-driven-ns/pkg/timingwheel"
 )
 
+// Scheduler holds notifications that are due within the lookahead window in a
+// timing wheel and releases each one the moment it becomes due. Postgres stays
+// the source of truth: rows are only moved out of 'scheduled' when they fire,
+// so anything lost from memory is picked up again by the next poll, and the
+// claim writes the outbox row the relay queues them from.
+//
+// Every replica fires what it holds, but a row is only queued by the replica
+// that wins the atomic claim. Polling Postgres and recovering stuck rows is
+// done by the replica holding the scheduler lease.
+//
+// The wheel never waits on a claim: due IDs go to a pool of claim workers,
+// and when a burst outruns them the overflow stays scheduled in Postgres for
+// the next poll instead of stalling the wheel's ticks.
 type Scheduler struct {
 	repo      port.NotificationRepository
-	publisher port.QueuePublisher
+	elector   *LeaderElector
 	logger    *zap.Logger
+	wheel     *timingwheel.Wheel
+	due       chan uuid.UUID
+	workers   int
+	deferred  atomic.Int64
 	interval  time.Duration
+	lookahead time.Duration
+	pageSize  int
 }
 
-func NewScheduler(repo port.NotificationRepository, publisher port.QueuePublisher, logger *zap.Logger) *Scheduler {
+func NewScheduler(repo port.NotificationRepository, elector *LeaderElector, logger *zap.Logger) *Scheduler {
 	return &Scheduler{
 		repo:      repo,
-		publisher: publisher,
+		elector:   elector,
 		logger:    logger,
+		wheel:     timingwheel.New(50*time.Millisecond, 1200),
+		due:       make(chan uuid.UUID, 8192),
+		workers:   16,
 		interval:  5 * time.Second,
+		lookahead: 30 * time.Second,
+		pageSize:  500,
 	}
 }
 
@@ -30,74 +59,168 @@ func (s *Scheduler) Run(ctx context.Context) {
 	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
 
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(1 + s.workers)
+	go func() {
+		defer wg.Done()
+		s.wheel.Run(ctx, s.release)
+	}()
+	for range s.workers {
+		go func() {
+			defer wg.Done()
+			s.dispatch(ctx)
+		}()
+	}
+
+	s.poll(ctx)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.processScheduled(ctx)
-			s.recoverStuck(ctx)
+			s.poll(ctx)
 		}
 	}
 }
 
-func (s *Scheduler) processScheduled(ctx context.Context) {
-	notifications, err := s.repo.ListDueScheduled(ctx, 100)
+// HandleScheduled receives notifications from the delay topic. Anything due
+// beyond the lookahead window is left to the Postgres poll.
+func (s *Scheduler) HandleScheduled(_ context.Context, notificationID string, dueAt time.Time) error {
+	if time.Until(dueAt) > s.lookahead {
+		return nil
+	}
+
+	id, err := uuid.Parse(notificationID)
+	if err != nil {
+		return err
+	}
+
+	s.wheel.Add(id.String(), dueAt)
+	return nil
+}
+
+func (s *Scheduler) Pending() int {
+	return s.wheel.Len()
+}
+
+// release hands a due ID to the claim workers without blocking the wheel.
+// When they are behind, the row is left scheduled; the next poll loads it
+// again, already due.
+func (s *Scheduler) release(key string) {
+	id, err := uuid.Parse(key)
 	if err != nil {
-		s.logger.Error("failed to list due scheduled notifications", zap.Error(err))
 		return
 	}
+	select {
+	case s.due <- id:
+	default:
+		s.deferred.Add(1)
+	}
+}
+
+func (s *Scheduler) poll(ctx context.Context) {
+	if n := s.deferred.Swap(0); n > 0 {
+		s.logger.Warn("scheduler claim workers fell behind, left due notifications to the next poll",
+			zap.Int64("count", n))
+	}
+	if !s.elector.IsLeader() {
+		return
+	}
+	s.processScheduled(ctx)
+	s.recoverStuck(ctx)
+	s.processFallbacks(ctx)
+}
+
+func (s *Scheduler) processScheduled(ctx context.Context) {
+	until := time.Now().UTC().Add(s.lookahead)
 
-	for _, n := range notifications {
-		n.Status = domain.StatusPending
-		n.UpdatedAt = time.Now().UTC()
+	var cursor *domain.ScheduleCursor
+	held := 0
+	for {
+		notifications, err := s.repo.ListDueScheduled(ctx, until, cursor, s.pageSize)
+		if err != nil {
+			s.logger.Error("failed to list due scheduled notifications", zap.Error(err))
+			return
+		}
 
-		if err := s.repo.UpdateStatus(ctx, n); err != nil {
-			s.logger.Error("failed to update scheduled notification status",
-				zap.String("id", n.ID.String()),
-				zap.Error(err),
-			)
-			continue
+		for _, n := range notifications {
+			if s.wheel.Add(n.ID.String(), *n.ScheduledAt) {
+				held++
+			}
 		}
 
-		if err := s.publisher.Enqueue(ctx, n); err != nil {
-			s.logger.Error("failed to enqueue scheduled notification",
-				zap.String("id", n.ID.String()),
-				zap.Error(err),
-			)
+		if len(notifications) < s.pageSize {
+			break
 		}
+		last := notifications[len(notifications)-1]
+		cursor = &domain.ScheduleCursor{ScheduledAt: *last.ScheduledAt, ID: last.ID}
 	}
 
-	if len(notifications) > 0 {
-		s.logger.Info("processed scheduled notifications", zap.Int("count", len(notifications)))
+	if held > 0 {
+		s.logger.Info("loaded scheduled notifications", zap.Int("count", held))
 	}
 }
 
-func (s *Scheduler) recoverStuck(ctx context.Context) {
-	notifications, err := s.repo.ListStuckProcessing(ctx, 5*time.Minute, 50)
-	if err != nil {
-		s.logger.Error("failed to list stuck notifications", zap.Error(err))
+func (s *Scheduler) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.due:
+			s.fire(ctx, id)
+		}
+	}
+}
+
+// fire claims a due notification. The claim writes its outbox row, so the
+// relay queues it; a failed claim leaves the row scheduled for the next poll.
+func (s *Scheduler) fire(ctx context.Context, id uuid.UUID) {
+	_, err := s.repo.ClaimScheduled(ctx, id)
+	if errors.Is(err, domain.ErrClaimConflict) {
 		return
 	}
+	if err != nil {
+		s.logger.Error("failed to claim scheduled notification", zap.String("id", id.String()), zap.Error(err))
+	}
+}
+
+// processFallbacks starts the next step of chains whose attempt timed out,
+// and of failed attempts whose fallback wasn't started when they failed. It
+// pages through every due attempt, so ones whose fallback keeps failing to
+// start cannot hold back the rest.
+func (s *Scheduler) processFallbacks(ctx context.Context) {
+	now := time.Now().UTC()
 
-	for _, n := range notifications {
-		n.Status = domain.StatusPending
-		n.UpdatedAt = time.Now().UTC()
+	var cursor *uuid.UUID
+	for {
+		notifications, err := s.repo.ListFallbacksDue(ctx, now, cursor, s.pageSize)
+		if err != nil {
+			s.logger.Error("failed to list due fallbacks", zap.Error(err))
+			return
+		}
 
-		if err := s.repo.UpdateStatus(ctx, n); err != nil {
-			s.logger.Error("failed to reset stuck notification",
-				zap.String("id", n.ID.String()),
-				zap.Error(err),
-			)
-			continue
+		for _, n := range notifications {
+			startFallback(ctx, s.repo, s.logger, n)
 		}
 
-		if err := s.publisher.Enqueue(ctx, n); err != nil {
-			s.logger.Error("failed to re-enqueue stuck notification",
-				zap.String("id", n.ID.String()),
-				zap.Error(err),
-			)
+		if len(notifications) < s.pageSize {
+			return
 		}
+		cursor = &notifications[len(notifications)-1].ID
+	}
+}
+
+// recoverStuck requeues notifications a worker claimed but never finished.
+// The repository writes their outbox rows with the reset, so the relay
+// publishes them.
+func (s *Scheduler) recoverStuck(ctx context.Context) {
+	notifications, err := s.repo.ResetStuckProcessing(ctx, 5*time.Minute, 50)
+	if err != nil {
+		s.logger.Error("failed to reset stuck notifications", zap.Error(err))
+		return
 	}
 
 	if len(notifications) > 0 {
@@ -5,24 +5,35 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/pkg/timingwheel"
 )
 
-func newTestScheduler() (*Scheduler, *mockNotificationRepo, *mockQueuePublisher) {
+func newTestScheduler() (*Scheduler, *mockNotificationRepo) {
 	repo := newMockNotificationRepo()
-	publisher := newMockQueuePublisher()
 	logger := zap.NewNop()
-	s := NewScheduler(repo, publisher, logger)
+	elector := NewLeaderElector(newMockLeaseStore(), SchedulerLeaseName, "worker-1", logger)
+	elector.campaign(context.Background())
+	s := NewScheduler(repo, elector, logger)
 	s.interval = 100 * time.Millisecond
-	return s, repo, publisher
+	s.wheel = timingwheel.New(10*time.Millisecond, 100)
+	return s, repo
+}
+
+// queued returns the notifications the scheduler handed to the outbox.
+func queued(repo *mockNotificationRepo) []*domain.OutboxMessage {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return append([]*domain.OutboxMessage(nil), repo.outbox...)
 }
 
 func TestScheduler_ProcessScheduled(t *testing.T) {
-	s, repo, publisher := newTestScheduler()
+	s, repo := newTestScheduler()
 
 	past := time.Now().Add(-1 * time.Minute)
 	n1, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "scheduled1", domain.PriorityNormal, &past)
@@ -31,19 +42,21 @@ func TestScheduler_ProcessScheduled(t *testing.T) {
 	_ = repo.Create(context.Background(), n2)
 
 	repo.dueScheduled = []*domain.Notification{n1, n2}
+	repo.outbox = nil
 
 	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
 	defer cancel()
 
 	s.Run(ctx)
 
-	assert.GreaterOrEqual(t, len(publisher.enqueued), 2)
-	assert.Equal(t, domain.StatusPending, publisher.enqueued[0].Status)
-	assert.Equal(t, domain.StatusPending, publisher.enqueued[1].Status)
+	// Firing queues them through the outbox, not straight to the publisher.
+	assert.Len(t, queued(repo), 2)
+	assert.Equal(t, domain.StatusPending, n1.Status)
+	assert.Equal(t, domain.StatusPending, n2.Status)
 }
 
 func TestScheduler_ProcessScheduled_Empty(t *testing.T) {
-	s, repo, publisher := newTestScheduler()
+	s, repo := newTestScheduler()
 
 	repo.dueScheduled = []*domain.Notification{}
 
@@ -52,11 +65,11 @@ func TestScheduler_ProcessScheduled_Empty(t *testing.T) {
 
 	s.Run(ctx)
 
-	assert.Empty(t, publisher.enqueued)
+	assert.Empty(t, queued(repo))
 }
 
 func TestScheduler_RecoverStuck(t *testing.T) {
-	s, repo, publisher := newTestScheduler()
+	s, repo := newTestScheduler()
 
 	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "stuck", domain.PriorityNormal, nil)
 	n.MarkProcessing()
@@ -64,49 +77,208 @@ func TestScheduler_RecoverStuck(t *testing.T) {
 	_ = repo.Create(context.Background(), n)
 
 	repo.stuckItems = []*domain.Notification{n}
+	repo.outbox = nil
 
 	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
 	defer cancel()
 
 	s.Run(ctx)
 
-	assert.GreaterOrEqual(t, len(publisher.enqueued), 1)
-	assert.Equal(t, domain.StatusPending, publisher.enqueued[0].Status)
+	assert.Equal(t, domain.StatusPending, n.Status)
+	outbox := queued(repo)
+	require.Len(t, outbox, 1)
+	assert.Equal(t, n.ID, outbox[0].NotificationID)
 }
 
-func TestScheduler_EnqueueError(t *testing.T) {
-	s, repo, publisher := newTestScheduler()
+func TestScheduler_ContextCancellation(t *testing.T) {
+	s, _ := newTestScheduler()
 
-	publisher.enqueueErr = assert.AnError
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
 
-	past := time.Now().Add(-1 * time.Minute)
-	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "will fail", domain.PriorityLow, &past)
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not exit on context cancellation")
+	}
+}
+
+func TestScheduler_HoldsUntilDue(t *testing.T) {
+	s, repo := newTestScheduler()
+
+	dueAt := time.Now().Add(80 * time.Millisecond)
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "reminder", domain.PriorityNormal, &dueAt)
+	_ = repo.Create(context.Background(), n)
+	repo.dueScheduled = []*domain.Notification{n}
+	repo.outbox = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	s.Run(ctx)
+	cancel()
+
+	assert.Empty(t, queued(repo))
+	assert.Equal(t, 1, s.Pending())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	outbox := queued(repo)
+	require.Len(t, outbox, 1)
+	assert.Equal(t, n.ID, outbox[0].NotificationID)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func TestScheduler_HandleScheduled(t *testing.T) {
+	s, repo := newTestScheduler()
+
+	dueAt := time.Now().Add(20 * time.Millisecond)
+	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "soon", domain.PriorityHigh, &dueAt)
+	_ = repo.Create(context.Background(), n)
+	repo.outbox = nil
+
+	require.NoError(t, s.HandleScheduled(context.Background(), n.ID.String(), dueAt))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	require.Len(t, queued(repo), 1)
+	assert.Equal(t, domain.StatusPending, n.Status)
+}
+
+func TestScheduler_HandleScheduled_BeyondLookahead(t *testing.T) {
+	s, _ := newTestScheduler()
+
+	err := s.HandleScheduled(context.Background(), "019476cb-f13a-7000-8000-000000000001", time.Now().Add(time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func TestScheduler_SkipsCancelledOnFire(t *testing.T) {
+	s, repo := newTestScheduler()
+
+	past := time.Now().Add(-time.Second)
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "cancelled", domain.PriorityNormal, &past)
+	_ = repo.Create(context.Background(), n)
+	repo.outbox = nil
+	require.NoError(t, s.HandleScheduled(context.Background(), n.ID.String(), past))
+	_ = n.Cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	assert.Empty(t, queued(repo))
+}
+
+func TestScheduler_FollowerDoesNotPoll(t *testing.T) {
+	s, repo := newTestScheduler()
+
+	leases := newMockLeaseStore()
+	leader := NewLeaderElector(leases, SchedulerLeaseName, "worker-1", zap.NewNop())
+	leader.campaign(context.Background())
+	s.elector = NewLeaderElector(leases, SchedulerLeaseName, "worker-2", zap.NewNop())
+	s.elector.campaign(context.Background())
+	require.False(t, s.elector.IsLeader())
+
+	past := time.Now().Add(-time.Minute)
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "due", domain.PriorityNormal, &past)
 	_ = repo.Create(context.Background(), n)
 	repo.dueScheduled = []*domain.Notification{n}
+	repo.outbox = nil
 
 	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
 	defer cancel()
+	s.Run(ctx)
 
-	require.NotPanics(t, func() {
-		s.Run(ctx)
-	})
+	assert.Empty(t, queued(repo))
 }
 
-func TestScheduler_ContextCancellation(t *testing.T) {
-	s, _, _ := newTestScheduler()
+func TestScheduler_ClaimPreventsDoubleEnqueue(t *testing.T) {
+	repo := newMockNotificationRepo()
+	leases := newMockLeaseStore()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	cancel()
+	past := time.Now().Add(-time.Second)
+	n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "once", domain.PriorityHigh, &past)
+	_ = repo.Create(context.Background(), n)
+	repo.outbox = nil
 
-	done := make(chan struct{})
+	first := NewScheduler(repo, NewLeaderElector(leases, SchedulerLeaseName, "worker-1", zap.NewNop()), zap.NewNop())
+	second := NewScheduler(repo, NewLeaderElector(leases, SchedulerLeaseName, "worker-2", zap.NewNop()), zap.NewNop())
+
+	first.fire(context.Background(), n.ID)
+	second.fire(context.Background(), n.ID)
+
+	assert.Len(t, queued(repo), 1)
+}
+
+func TestScheduler_StartsTimedOutFallbacks(t *testing.T) {
+	s, repo := newTestScheduler()
+
+	n, _ := domain.NewNotification(domain.ChannelPush, "device-token", "hello", domain.PriorityNormal, nil)
+	n.CreatedAt = time.Now().UTC().Add(-time.Hour)
+	step, _ := domain.NewFallbackStep(domain.ChannelSMS, "+90500000000", "hello", 0)
+	require.NoError(t, n.SetFallbacks([]domain.FallbackStep{step}, 30*time.Minute))
+	n.MarkSent("push-1")
+	_ = repo.Create(context.Background(), n)
+
+	onTime, _ := domain.NewNotification(domain.ChannelPush, "other-token", "hello", domain.PriorityNormal, nil)
+	require.NoError(t, onTime.SetFallbacks([]domain.FallbackStep{step}, 30*time.Minute))
+	_ = repo.Create(context.Background(), onTime)
+
+	s.poll(context.Background())
+	s.poll(context.Background())
+
+	chain, err := repo.ListChain(context.Background(), n.ID)
+	require.NoError(t, err)
+	require.Len(t, chain, 2, "one fallback however often the sweep runs")
+	assert.Equal(t, domain.ChannelSMS, chain[1].Channel)
+
+	chain, _ = repo.ListChain(context.Background(), onTime.ID)
+	assert.Len(t, chain, 1)
+}
+
+func TestScheduler_BurstNeverBlocksWheel(t *testing.T) {
+	s, repo := newTestScheduler()
+	s.due = make(chan uuid.UUID, 1)
+
+	past := time.Now().Add(-time.Second)
+	var burst []*domain.Notification
+	for range 3 {
+		n, _ := domain.NewNotification(domain.ChannelSMS, "+90500000000", "reminder", domain.PriorityNormal, &past)
+		_ = repo.Create(context.Background(), n)
+		burst = append(burst, n)
+	}
+	repo.outbox = nil
+
+	released := make(chan struct{})
 	go func() {
-		s.Run(ctx)
-		close(done)
+		for _, n := range burst {
+			s.release(n.ID.String())
+		}
+		close(released)
 	}()
-
 	select {
-	case <-done:
+	case <-released:
 	case <-time.After(time.Second):
-		t.Fatal("scheduler did not exit on context cancellation")
+		t.Fatal("release blocked with no claim worker running")
 	}
+	assert.Equal(t, int64(2), s.deferred.Load())
+
+	// The overflow is still scheduled, so the poll loads it again.
+	repo.dueScheduled = burst
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	assert.Len(t, queued(repo), 3)
+	assert.Zero(t, s.deferred.Load())
 }
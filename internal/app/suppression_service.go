@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+	"github.com/mehmetymw/event-driven-ns/internal/port"
+)
+
+// suppressionAdmin is the source of entries added through the API without
+// one.
+const suppressionAdmin = "api"
+
+// SuppressionService manages the suppression list: opt-outs, bounces and
+// other recipients nothing may be sent to.
+type SuppressionService struct {
+	repo   port.SuppressionRepository
+	logger *zap.Logger
+}
+
+func NewSuppressionService(repo port.SuppressionRepository, logger *zap.Logger) *SuppressionService {
+	return &SuppressionService{repo: repo, logger: logger}
+}
+
+// AddSuppressionInput suppresses a recipient on a channel. Without ExpiresAt
+// the entry lasts until it is removed.
+type AddSuppressionInput struct {
+	Channel   domain.Channel
+	Recipient string
+	Reason    string
+	Source    string
+	ExpiresAt *time.Time
+}
+
+// Add suppresses the recipient, replacing any entry it already has.
+func (s *SuppressionService) Add(ctx context.Context, input AddSuppressionInput) (*domain.Suppression, error) {
+	source := input.Source
+	if source == "" {
+		source = suppressionAdmin
+	}
+	entry, err := domain.NewSuppression(input.Channel, input.Recipient, input.Reason, source, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Suppress(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("recipient suppressed",
+		zap.String("channel", string(entry.Channel)),
+		zap.String("source", entry.Source),
+		zap.String("reason", entry.Reason),
+	)
+	return entry, nil
+}
+
+func (s *SuppressionService) Remove(ctx context.Context, channel domain.Channel, recipient string) error {
+	if err := s.repo.Remove(ctx, channel, recipient); err != nil {
+		return err
+	}
+	s.logger.Info("suppression removed", zap.String("channel", string(channel)))
+	return nil
+}
+
+func (s *SuppressionService) List(ctx context.Context, filter domain.SuppressionFilter) ([]*domain.Suppression, error) {
+	return s.repo.List(ctx, filter)
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+func newTestSuppressionService() (*SuppressionService, *mockSuppressionRepo) {
+	repo := newMockSuppressionRepo()
+	return NewSuppressionService(repo, zap.NewNop()), repo
+}
+
+func TestSuppressionService_AddListRemove(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestSuppressionService()
+
+	entry, err := svc.Add(ctx, AddSuppressionInput{
+		Channel:   domain.ChannelSMS,
+		Recipient: "+90500000000",
+		Reason:    "replied STOP",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "api", entry.Source)
+
+	suppressed, _ := repo.IsSuppressed(ctx, domain.ChannelSMS, "+90500000000")
+	assert.True(t, suppressed)
+
+	sms := domain.ChannelSMS
+	entries, err := svc.List(ctx, domain.SuppressionFilter{Channel: &sms})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "replied STOP", entries[0].Reason)
+
+	require.NoError(t, svc.Remove(ctx, domain.ChannelSMS, "+90500000000"))
+	suppressed, _ = repo.IsSuppressed(ctx, domain.ChannelSMS, "+90500000000")
+	assert.False(t, suppressed)
+
+	err = svc.Remove(ctx, domain.ChannelSMS, "+90500000000")
+	assert.ErrorIs(t, err, domain.ErrSuppressionNotFound)
+}
+
+func TestSuppressionService_Add_Invalid(t *testing.T) {
+	svc, _ := newTestSuppressionService()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		input AddSuppressionInput
+		err   error
+	}{
+		{"bad channel", AddSuppressionInput{Channel: "fax", Recipient: "+90500000000", Reason: "bounce"}, domain.ErrInvalidChannel},
+		{"bad recipient", AddSuppressionInput{Channel: domain.ChannelEmail, Recipient: "nope", Reason: "bounce"}, domain.ErrInvalidRecipient},
+		{"no reason", AddSuppressionInput{Channel: domain.ChannelEmail, Recipient: "a@b.com"}, domain.ErrInvalidSuppression},
+		{"expired", AddSuppressionInput{Channel: domain.ChannelEmail, Recipient: "a@b.com", Reason: "bounce", ExpiresAt: &past}, domain.ErrInvalidSuppression},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Add(context.Background(), tt.input)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
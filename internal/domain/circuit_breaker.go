@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// CircuitBreaker is one worker's breaker for a channel's provider.
+// Transitions counts how often it entered each state since the worker
+// started. OpenUntil is when an open breaker lets half-open probes through;
+// it is unset while ForcedOpen, which lasts until an admin resets it.
+type CircuitBreaker struct {
+	InstanceID  string
+	Channel     Channel
+	Provider    string
+	State       BreakerState
+	ForcedOpen  bool
+	OpenUntil   *time.Time
+	Transitions map[BreakerState]int64
+	ChangedAt   *time.Time
+	UpdatedAt   time.Time
+}
+
+// BreakerControl is what every worker should do with one breaker: hold it
+// open while ForcedOpen, hold it open until OpenUntil, and reset it if it
+// hasn't been reset since ResetAt. Admins force and reset breakers; workers
+// set OpenUntil when their breaker trips and breaker state is shared.
+type BreakerControl struct {
+	Channel    Channel
+	Provider   string
+	ForcedOpen bool
+	OpenUntil  *time.Time
+	ResetAt    *time.Time
+	UpdatedBy  string
+	UpdatedAt  time.Time
+}
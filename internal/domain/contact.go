@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	userIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:@\-]{1,255}$`)
+	localeRegex = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+)
+
+// User is a recipient profile. Callers send to a user by ID and channel and
+// the service picks the addresses, so only the profile has to know them.
+// ID is the caller's own user ID.
+type User struct {
+	ID        string            `db:"id"`
+	Locale    string            `db:"locale"`
+	TimeZone  string            `db:"time_zone"`
+	CreatedAt time.Time         `db:"created_at"`
+	UpdatedAt time.Time         `db:"updated_at"`
+	Addresses []*ContactAddress `db:"-"`
+}
+
+func NewUser(id, locale, timeZone string) (*User, error) {
+	if !userIDRegex.MatchString(id) {
+		return nil, fmt.Errorf("%w: id must be 1-255 letters, digits or ._:@-", ErrInvalidUser)
+	}
+	u := &User{ID: id}
+	if err := u.Update(locale, timeZone); err != nil {
+		return nil, err
+	}
+	u.CreatedAt = u.UpdatedAt
+	return u, nil
+}
+
+// Update sets the user's locale (a BCP 47 tag such as "tr-TR") and IANA
+// time zone. Either may be empty.
+func (u *User) Update(locale, timeZone string) error {
+	if locale != "" && !localeRegex.MatchString(locale) {
+		return fmt.Errorf("%w: locale %q", ErrInvalidUser, locale)
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return fmt.Errorf("%w: time zone %q", ErrInvalidUser, timeZone)
+		}
+	}
+	u.Locale = locale
+	u.TimeZone = timeZone
+	u.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// ContactAddress is one of a user's addresses on a channel: a phone number,
+// an email address or a device token. Inactive addresses are kept but never
+// sent to.
+type ContactAddress struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    string    `db:"user_id"`
+	Channel   Channel   `db:"channel"`
+	Address   string    `db:"address"`
+	Verified  bool      `db:"verified"`
+	Active    bool      `db:"active"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewContactAddress(userID string, channel Channel, address string, verified bool) (*ContactAddress, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	if err := validateRecipient(channel, address); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &ContactAddress{
+		ID:        uuid.Must(uuid.NewV7()),
+		UserID:    userID,
+		Channel:   channel,
+		Address:   address,
+		Verified:  verified,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ResolveRecipients picks the addresses a notification to the user on
+// channel goes to, from the user's addresses on it, oldest first. Push goes
+// to every active device token. SMS and email go to one address: the oldest
+// verified one, or the oldest active one if none is verified.
+func ResolveRecipients(channel Channel, addresses []*ContactAddress) ([]string, error) {
+	var active []*ContactAddress
+	for _, a := range addresses {
+		if a.Channel == channel && a.Active {
+			active = append(active, a)
+		}
+	}
+	if len(active) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoActiveAddress, channel)
+	}
+
+	if channel == ChannelPush {
+		recipients := make([]string, len(active))
+		for i, a := range active {
+			recipients[i] = a.Address
+		}
+		return recipients, nil
+	}
+
+	for _, a := range active {
+		if a.Verified {
+			return []string{a.Address}, nil
+		}
+	}
+	return []string{active[0].Address}, nil
+}
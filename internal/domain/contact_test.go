@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUser_Validation(t *testing.T) {
+	u, err := NewUser("user-42", "tr-TR", "Europe/Istanbul")
+	require.NoError(t, err)
+	assert.Equal(t, "tr-TR", u.Locale)
+	assert.Equal(t, u.CreatedAt, u.UpdatedAt)
+
+	_, err = NewUser("", "", "")
+	assert.ErrorIs(t, err, ErrInvalidUser)
+
+	_, err = NewUser("user 42", "", "")
+	assert.ErrorIs(t, err, ErrInvalidUser)
+
+	_, err = NewUser("user-42", "not a locale", "")
+	assert.ErrorIs(t, err, ErrInvalidUser)
+
+	_, err = NewUser("user-42", "", "Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidUser)
+}
+
+func TestNewContactAddress_Validation(t *testing.T) {
+	_, err := NewContactAddress("user-42", ChannelEmail, "not-an-email", false)
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+
+	a, err := NewContactAddress("user-42", ChannelSMS, "+90500000000", true)
+	require.NoError(t, err)
+	assert.True(t, a.Active)
+	assert.True(t, a.Verified)
+}
+
+func TestResolveRecipients(t *testing.T) {
+	address := func(channel Channel, addr string, verified, active bool) *ContactAddress {
+		a, err := NewContactAddress("user-42", channel, addr, verified)
+		require.NoError(t, err)
+		a.Active = active
+		return a
+	}
+	addresses := []*ContactAddress{
+		address(ChannelEmail, "old@example.com", false, true),
+		address(ChannelEmail, "verified@example.com", true, true),
+		address(ChannelPush, "token-a", false, true),
+		address(ChannelPush, "token-b", true, true),
+		address(ChannelPush, "token-old", true, false),
+		address(ChannelSMS, "+90500000000", true, false),
+	}
+
+	push, err := ResolveRecipients(ChannelPush, addresses)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token-a", "token-b"}, push)
+
+	email, err := ResolveRecipients(ChannelEmail, addresses)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"verified@example.com"}, email)
+
+	_, err = ResolveRecipients(ChannelSMS, addresses)
+	assert.ErrorIs(t, err, ErrNoActiveAddress)
+
+	unverified, err := ResolveRecipients(ChannelEmail, addresses[:1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old@example.com"}, unverified)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeadLetterAttempt struct {
+	Attempt  int       `json:"attempt"`
+	Topic    string    `json:"topic"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// DeadLetter is a message that left the delivery pipeline for good. Payload is
+// the raw Kafka value, kept verbatim so entries that never decoded can still
+// be inspected. NotificationID is nil for those entries.
+type DeadLetter struct {
+	ID             uuid.UUID
+	NotificationID *uuid.UUID
+	Topic          string
+	Partition      int
+	Offset         int64
+	Payload        []byte
+	Error          string
+	Attempts       []DeadLetterAttempt
+	CreatedAt      time.Time
+	RedrivenAt     *time.Time
+}
+
+// CanRedrive reports whether the entry can go back to its priority topic.
+// Payloads that never decoded have no notification to requeue.
+func (d *DeadLetter) CanRedrive() bool {
+	return d.NotificationID != nil && d.RedrivenAt == nil
+}
+
+type DeadLetterFilter struct {
+	Topic          *string
+	NotificationID *uuid.UUID
+	Redriven       *bool
+	DateFrom       *time.Time
+	DateTo         *time.Time
+	Cursor         *DeadLetterCursor
+	PageSize       int
+}
+
+// DeadLetterCursor is the last entry of the previous page. Entries are
+// listed newest first, by creation time and then ID.
+type DeadLetterCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Cursor returns the cursor for the page after d.
+func (d *DeadLetter) Cursor() *DeadLetterCursor {
+	return &DeadLetterCursor{CreatedAt: d.CreatedAt, ID: d.ID}
+}
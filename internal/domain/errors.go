@@ -23,4 +23,24 @@ var (
 	ErrTemplateRenderFailed    = errors.New("template render failed")
 	ErrProviderUnavailable     = errors.New("delivery provider unavailable")
 	ErrCircuitOpen             = errors.New("circuit breaker is open")
+	ErrClaimConflict           = errors.New("notification already claimed")
+	ErrLeaseNotFound           = errors.New("lease not found")
+	ErrDeliveryFailed          = errors.New("delivery permanently failed")
+	ErrDeadLetterNotFound      = errors.New("dead letter not found")
+	ErrDeadLetterNotRedrivable = errors.New("dead letter cannot be redriven")
+	ErrUnregisteredToken       = errors.New("push token is not registered")
+	ErrInvalidReceipt          = errors.New("invalid delivery receipt")
+	ErrRecipientSuppressed     = errors.New("recipient is suppressed")
+	ErrBreakerNotFound         = errors.New("circuit breaker not found")
+	ErrInvalidFallback         = errors.New("invalid fallback")
+	ErrGroupNotFound           = errors.New("notification group not found")
+	ErrInvalidGroup            = errors.New("invalid notification group")
+	ErrInvalidUser             = errors.New("invalid user")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrDuplicateUser           = errors.New("user already exists")
+	ErrAddressNotFound         = errors.New("contact address not found")
+	ErrDuplicateAddress        = errors.New("contact address already exists")
+	ErrNoActiveAddress         = errors.New("user has no active address for channel")
+	ErrInvalidSuppression      = errors.New("invalid suppression")
+	ErrSuppressionNotFound     = errors.New("suppression not found")
 )
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxFallbacks caps the fallback steps of one notification.
+const MaxFallbacks = 5
+
+// FallbackStep is the attempt to make when the one before it fails for good,
+// is reported undelivered, or isn't delivered within its timeout. Content is
+// rendered when the chain is created, so every step is validated up front.
+// Timeout is how long this step's own attempt gets before the next step; zero
+// waits for a permanent failure.
+type FallbackStep struct {
+	Channel           Channel           `json:"channel"`
+	Recipient         string            `json:"recipient"`
+	Content           string            `json:"content"`
+	TemplateID        *uuid.UUID        `json:"template_id,omitempty"`
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+	Timeout           time.Duration     `json:"timeout,omitempty"`
+}
+
+func NewFallbackStep(channel Channel, recipient, content string, timeout time.Duration) (FallbackStep, error) {
+	if err := validateChannel(channel); err != nil {
+		return FallbackStep{}, fmt.Errorf("fallback: %w", err)
+	}
+	if err := validateRecipient(channel, recipient); err != nil {
+		return FallbackStep{}, fmt.Errorf("fallback to %s: %w", channel, err)
+	}
+	if err := validateContent(channel, content); err != nil {
+		return FallbackStep{}, fmt.Errorf("fallback to %s: %w", channel, err)
+	}
+	if timeout < 0 {
+		return FallbackStep{}, fmt.Errorf("%w: negative timeout", ErrInvalidFallback)
+	}
+	return FallbackStep{Channel: channel, Recipient: recipient, Content: content, Timeout: timeout}, nil
+}
+
+// SetFallbacks gives the notification the steps to try after it. With a
+// timeout the next step also starts if this attempt hasn't been delivered
+// that long after it was due.
+func (n *Notification) SetFallbacks(steps []FallbackStep, timeout time.Duration) error {
+	if len(steps) > MaxFallbacks {
+		return fmt.Errorf("%w: at most %d steps", ErrInvalidFallback, MaxFallbacks)
+	}
+	n.Fallbacks = steps
+	n.FallbackAt = nil
+	if len(steps) > 0 && timeout > 0 {
+		start := n.CreatedAt
+		if n.ScheduledAt != nil {
+			start = *n.ScheduledAt
+		}
+		at := start.Add(timeout)
+		n.FallbackAt = &at
+	}
+	return nil
+}
+
+// ChainID is the ID every attempt of a fallback chain shares: the first
+// notification's.
+func (n *Notification) ChainID() uuid.UUID {
+	if n.ParentID != nil {
+		return *n.ParentID
+	}
+	return n.ID
+}
+
+// ClearFallbackTimeout drops the attempt's delivery timeout. An attempt whose
+// provider sends no receipts never gets past sent, so the timeout would start
+// the next step for a message that most likely arrived.
+func (n *Notification) ClearFallbackTimeout() {
+	n.FallbackAt = nil
+}
+
+// NeedsFallback reports whether the next step should start: this attempt
+// failed, went undelivered or was suppressed, or its timeout passed before
+// it was delivered, and no next attempt exists yet.
+func (n *Notification) NeedsFallback(now time.Time) bool {
+	if len(n.Fallbacks) == 0 || n.NextAttemptID != nil {
+		return false
+	}
+	switch n.Status {
+	case StatusFailed, StatusUndelivered, StatusSuppressed:
+		return true
+	case StatusDelivered, StatusRead, StatusCancelled:
+		return false
+	default:
+		return n.FallbackAt != nil && !now.Before(*n.FallbackAt)
+	}
+}
+
+// NextAttempt builds the notification for the first fallback step, carrying
+// the remaining steps along. It doesn't link n to it; the repository does
+// that when it saves the attempt.
+func (n *Notification) NextAttempt() (*Notification, error) {
+	if len(n.Fallbacks) == 0 {
+		return nil, fmt.Errorf("%w: no fallback left", ErrInvalidFallback)
+	}
+	step := n.Fallbacks[0]
+	next, err := NewNotification(step.Channel, step.Recipient, step.Content, n.Priority, nil)
+	if err != nil {
+		return nil, err
+	}
+	parentID := n.ChainID()
+	next.ParentID = &parentID
+	next.TemplateID = step.TemplateID
+	next.TemplateVariables = step.TemplateVariables
+	if err := next.SetFallbacks(n.Fallbacks[1:], step.Timeout); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// FallbackChain is a notification and the fallback attempts spawned from
+// it, oldest first.
+type FallbackChain struct {
+	ParentID uuid.UUID
+	Attempts []*Notification
+}
+
+// Status sums the chain up. Once any attempt is delivered the chain is
+// delivered, or read if one was read, even if a later attempt is still
+// going. Otherwise it is the latest attempt's status, except that a failed,
+// undelivered or suppressed attempt whose next step hasn't started yet
+// leaves the chain pending.
+func (c *FallbackChain) Status() Status {
+	if len(c.Attempts) == 0 {
+		return StatusPending
+	}
+
+	best := Status("")
+	for _, a := range c.Attempts {
+		switch a.Status {
+		case StatusRead:
+			return StatusRead
+		case StatusDelivered:
+			best = StatusDelivered
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	last := c.Attempts[len(c.Attempts)-1]
+	switch last.Status {
+	case StatusFailed, StatusUndelivered, StatusSuppressed:
+		if len(last.Fallbacks) > 0 {
+			return StatusPending
+		}
+	}
+	return last.Status
+}
+
+// RemainingFallbacks is how many steps are left after the latest attempt.
+func (c *FallbackChain) RemainingFallbacks() int {
+	if len(c.Attempts) == 0 {
+		return 0
+	}
+	return len(c.Attempts[len(c.Attempts)-1].Fallbacks)
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func notificationWithFallbacks(t *testing.T, timeout time.Duration) *Notification {
+	n, err := NewNotification(ChannelPush, "device-token", "Your code is 1234", PriorityHigh, nil)
+	require.NoError(t, err)
+	sms, err := NewFallbackStep(ChannelSMS, "+90500000000", "Your code is 1234", time.Minute)
+	require.NoError(t, err)
+	email, err := NewFallbackStep(ChannelEmail, "user@example.com", "Your code is 1234", 0)
+	require.NoError(t, err)
+	require.NoError(t, n.SetFallbacks([]FallbackStep{sms, email}, timeout))
+	return n
+}
+
+func TestNewFallbackStep_Validation(t *testing.T) {
+	_, err := NewFallbackStep("fax", "+90500000000", "hi", 0)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+
+	_, err = NewFallbackStep(ChannelEmail, "not-an-email", "hi", 0)
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+
+	_, err = NewFallbackStep(ChannelSMS, "+90500000000", "hi", -time.Second)
+	assert.ErrorIs(t, err, ErrInvalidFallback)
+}
+
+func TestNotification_SetFallbacks(t *testing.T) {
+	n := notificationWithFallbacks(t, 10*time.Minute)
+	require.NotNil(t, n.FallbackAt)
+	assert.Equal(t, n.CreatedAt.Add(10*time.Minute), *n.FallbackAt)
+
+	steps := make([]FallbackStep, MaxFallbacks+1)
+	assert.ErrorIs(t, n.SetFallbacks(steps, 0), ErrInvalidFallback)
+}
+
+func TestNotification_NeedsFallback(t *testing.T) {
+	n := notificationWithFallbacks(t, 10*time.Minute)
+	now := n.CreatedAt
+
+	assert.False(t, n.NeedsFallback(now))
+	assert.True(t, n.NeedsFallback(now.Add(10*time.Minute)), "timed out")
+
+	n.MarkFailed("invalid token")
+	assert.True(t, n.NeedsFallback(now))
+
+	next, err := n.NextAttempt()
+	require.NoError(t, err)
+	n.NextAttemptID = &next.ID
+	assert.False(t, n.NeedsFallback(now), "next attempt already started")
+
+	delivered := notificationWithFallbacks(t, 10*time.Minute)
+	delivered.Status = StatusDelivered
+	assert.False(t, delivered.NeedsFallback(now.Add(time.Hour)))
+
+	suppressed := notificationWithFallbacks(t, 0)
+	suppressed.MarkSuppressed()
+	assert.True(t, suppressed.NeedsFallback(now))
+}
+
+func TestNotification_NextAttempt(t *testing.T) {
+	n := notificationWithFallbacks(t, 0)
+
+	sms, err := n.NextAttempt()
+	require.NoError(t, err)
+	assert.Equal(t, ChannelSMS, sms.Channel)
+	assert.Equal(t, "+90500000000", sms.Recipient)
+	assert.Equal(t, PriorityHigh, sms.Priority)
+	assert.Equal(t, n.ID, *sms.ParentID)
+	require.Len(t, sms.Fallbacks, 1)
+	require.NotNil(t, sms.FallbackAt)
+	assert.Equal(t, sms.CreatedAt.Add(time.Minute), *sms.FallbackAt)
+
+	email, err := sms.NextAttempt()
+	require.NoError(t, err)
+	assert.Equal(t, n.ID, *email.ParentID, "attempts share the first notification's ID")
+	assert.Empty(t, email.Fallbacks)
+	assert.Nil(t, email.FallbackAt)
+
+	_, err = email.NextAttempt()
+	assert.ErrorIs(t, err, ErrInvalidFallback)
+}
+
+func TestFallbackChain_Status(t *testing.T) {
+	push := notificationWithFallbacks(t, 0)
+	push.MarkFailed("unregistered")
+	chain := &FallbackChain{ParentID: push.ID, Attempts: []*Notification{push}}
+	assert.Equal(t, StatusPending, chain.Status(), "next step not started yet")
+	assert.Equal(t, 2, chain.RemainingFallbacks())
+
+	sms, _ := push.NextAttempt()
+	chain.Attempts = append(chain.Attempts, sms)
+	assert.Equal(t, StatusPending, chain.Status())
+	assert.Equal(t, 1, chain.RemainingFallbacks())
+
+	sms.MarkSent("SM123")
+	sms.Status = StatusDelivered
+	assert.Equal(t, StatusDelivered, chain.Status())
+
+	email, _ := sms.NextAttempt()
+	email.MarkFailed("bounced")
+	chain.Attempts = append(chain.Attempts, email)
+	assert.Equal(t, StatusDelivered, chain.Status(), "a delivered attempt wins")
+
+	sms.Status = StatusFailed
+	assert.Equal(t, StatusFailed, chain.Status(), "no steps left")
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupStatus sums up the siblings of a NotificationGroup.
+type GroupStatus string
+
+const (
+	// GroupInProgress: at least one sibling hasn't been sent, failed or
+	// been cancelled yet.
+	GroupInProgress GroupStatus = "in_progress"
+	// GroupCompleted: every sibling that wasn't cancelled was sent.
+	GroupCompleted GroupStatus = "completed"
+	// GroupPartiallyFailed: some siblings were sent and some failed.
+	GroupPartiallyFailed GroupStatus = "partially_failed"
+	// GroupFailed: every sibling that wasn't cancelled failed.
+	GroupFailed    GroupStatus = "failed"
+	GroupCancelled GroupStatus = "cancelled"
+)
+
+// NotificationGroup is one event sent on several channels at once: sibling
+// notifications created together, each with its own recipient and content.
+// An idempotency key on the group covers all of them.
+type NotificationGroup struct {
+	ID             uuid.UUID
+	IdempotencyKey *string
+	CreatedAt      time.Time
+	Notifications  []*Notification
+}
+
+// NewNotificationGroup groups the siblings. A channel can appear more than
+// once, as when a push goes to each of a user's devices, but never for the
+// same recipient twice.
+func NewNotificationGroup(notifications []*Notification, idempotencyKey *string) (*NotificationGroup, error) {
+	if len(notifications) == 0 {
+		return nil, fmt.Errorf("%w: no channels", ErrInvalidGroup)
+	}
+	type target struct {
+		channel   Channel
+		recipient string
+	}
+	seen := make(map[target]bool, len(notifications))
+	for _, n := range notifications {
+		t := target{n.Channel, n.Recipient}
+		if seen[t] {
+			return nil, fmt.Errorf("%w: %s to %s listed twice", ErrInvalidGroup, n.Channel, n.Recipient)
+		}
+		seen[t] = true
+	}
+
+	g := &NotificationGroup{
+		ID:             uuid.Must(uuid.NewV7()),
+		IdempotencyKey: idempotencyKey,
+		CreatedAt:      time.Now().UTC(),
+		Notifications:  notifications,
+	}
+	for _, n := range notifications {
+		n.GroupID = &g.ID
+	}
+	return g, nil
+}
+
+// Counts tallies the siblings by status.
+func (g *NotificationGroup) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, n := range g.Notifications {
+		counts[n.Status]++
+	}
+	return counts
+}
+
+// Status counts sent, delivered and read siblings as sent, and failed,
+// undelivered and suppressed ones as failed. Cancelled siblings only decide the status when
+// every sibling was cancelled.
+func (g *NotificationGroup) Status() GroupStatus {
+	var sent, failed, cancelled int
+	for _, n := range g.Notifications {
+		switch n.Status {
+		case StatusSent, StatusDelivered, StatusRead:
+			sent++
+		case StatusFailed, StatusUndelivered, StatusSuppressed:
+			failed++
+		case StatusCancelled:
+			cancelled++
+		default:
+			return GroupInProgress
+		}
+	}
+
+	switch {
+	case cancelled == len(g.Notifications):
+		return GroupCancelled
+	case failed == 0:
+		return GroupCompleted
+	case sent == 0:
+		return GroupFailed
+	default:
+		return GroupPartiallyFailed
+	}
+}
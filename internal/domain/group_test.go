@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGroup(t *testing.T) *NotificationGroup {
+	push, err := NewNotification(ChannelPush, "device-token", "Order shipped", PriorityNormal, nil)
+	require.NoError(t, err)
+	email, err := NewNotification(ChannelEmail, "user@example.com", "Your order has shipped", PriorityNormal, nil)
+	require.NoError(t, err)
+	g, err := NewNotificationGroup([]*Notification{push, email}, nil)
+	require.NoError(t, err)
+	return g
+}
+
+func TestNewNotificationGroup(t *testing.T) {
+	g := newTestGroup(t)
+	for _, n := range g.Notifications {
+		assert.Equal(t, g.ID, *n.GroupID)
+	}
+
+	_, err := NewNotificationGroup(nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	a, _ := NewNotification(ChannelPush, "token-a", "a", PriorityNormal, nil)
+	b, _ := NewNotification(ChannelPush, "token-b", "b", PriorityNormal, nil)
+	_, err = NewNotificationGroup([]*Notification{a, b}, nil)
+	assert.NoError(t, err, "one push per device")
+
+	again, _ := NewNotification(ChannelPush, "token-a", "a", PriorityNormal, nil)
+	_, err = NewNotificationGroup([]*Notification{a, again}, nil)
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+}
+
+func TestNotificationGroup_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses [2]Status
+		want     GroupStatus
+	}{
+		{"one still pending", [2]Status{StatusSent, StatusPending}, GroupInProgress},
+		{"all sent", [2]Status{StatusDelivered, StatusRead}, GroupCompleted},
+		{"sent and cancelled", [2]Status{StatusSent, StatusCancelled}, GroupCompleted},
+		{"some failed", [2]Status{StatusDelivered, StatusUndelivered}, GroupPartiallyFailed},
+		{"all failed", [2]Status{StatusFailed, StatusCancelled}, GroupFailed},
+		{"all cancelled", [2]Status{StatusCancelled, StatusCancelled}, GroupCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGroup(t)
+			g.Notifications[0].Status = tt.statuses[0]
+			g.Notifications[1].Status = tt.statuses[1]
+			assert.Equal(t, tt.want, g.Status())
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type Lease struct {
+	Name       string    `db:"name"`
+	Holder     string    `db:"holder"`
+	AcquiredAt time.Time `db:"acquired_at"`
+	RenewedAt  time.Time `db:"renewed_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+func (l *Lease) Active() bool {
+	return time.Now().Before(l.ExpiresAt)
+}
@@ -26,13 +26,21 @@ const (
 
 type Status string
 
+// A notification is sent once a provider accepts it. Providers that report
+// delivery receipts move it on to delivered or undelivered, and then read.
+// One whose recipient is on the suppression list is suppressed instead and
+// never reaches a provider.
 const (
-	StatusPending    Status = "pending"
-	StatusScheduled  Status = "scheduled"
-	StatusProcessing Status = "processing"
-	StatusDelivered  Status = "delivered"
-	StatusFailed     Status = "failed"
-	StatusCancelled  Status = "cancelled"
+	StatusPending     Status = "pending"
+	StatusScheduled   Status = "scheduled"
+	StatusProcessing  Status = "processing"
+	StatusSent        Status = "sent"
+	StatusDelivered   Status = "delivered"
+	StatusUndelivered Status = "undelivered"
+	StatusRead        Status = "read"
+	StatusFailed      Status = "failed"
+	StatusCancelled   Status = "cancelled"
+	StatusSuppressed  Status = "suppressed"
 )
 
 var (
@@ -55,7 +63,10 @@ var priorityMaxRetries = map[Priority]int{
 type Notification struct {
 	ID                uuid.UUID
 	BatchID           *uuid.UUID
+	GroupID           *uuid.UUID
+	UserID            *string
 	IdempotencyKey    *string
+	OrderingKey       *string
 	Channel           Channel
 	Recipient         string
 	Content           string
@@ -68,20 +79,33 @@ type Notification struct {
 	RetryCount        int
 	MaxRetries        int
 	ProviderMessageID *string
+	Provider          *string
 	TemplateID        *uuid.UUID
 	TemplateVariables map[string]string
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	// ParentID links a fallback attempt to the first notification of its
+	// chain. Fallbacks are the steps still to try after this attempt,
+	// FallbackAt when the next one starts if this one isn't delivered by
+	// then, and NextAttemptID the attempt that was started.
+	ParentID      *uuid.UUID
+	Fallbacks     []FallbackStep
+	FallbackAt    *time.Time
+	NextAttemptID *uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 type NotificationBatch struct {
-	ID             uuid.UUID `db:"id"`
-	TotalCount     int       `db:"total_count"`
-	PendingCount   int       `db:"pending_count"`
-	DeliveredCount int       `db:"delivered_count"`
-	FailedCount    int       `db:"failed_count"`
-	CancelledCount int       `db:"cancelled_count"`
-	CreatedAt      time.Time `db:"created_at"`
+	ID               uuid.UUID `db:"id"`
+	TotalCount       int       `db:"total_count"`
+	PendingCount     int       `db:"pending_count"`
+	SentCount        int       `db:"sent_count"`
+	DeliveredCount   int       `db:"delivered_count"`
+	UndeliveredCount int       `db:"undelivered_count"`
+	ReadCount        int       `db:"read_count"`
+	FailedCount      int       `db:"failed_count"`
+	CancelledCount   int       `db:"cancelled_count"`
+	SuppressedCount  int       `db:"suppressed_count"`
+	CreatedAt        time.Time `db:"created_at"`
 }
 
 type ChannelStats struct {
@@ -101,6 +125,11 @@ type NotificationFilter struct {
 	PageSize int
 }
 
+type ScheduleCursor struct {
+	ScheduledAt time.Time
+	ID          uuid.UUID
+}
+
 func NewNotification(channel Channel, recipient, content string, priority Priority, scheduledAt *time.Time) (*Notification, error) {
 	if err := validateChannel(channel); err != nil {
 		return nil, err
@@ -135,6 +164,15 @@ func NewNotification(channel Channel, recipient, content string, priority Priori
 	}, nil
 }
 
+// PartitionKey is the key that orders delivery when ordered delivery is
+// enabled: the client-supplied ordering key, or else the recipient.
+func (n *Notification) PartitionKey() string {
+	if n.OrderingKey != nil && *n.OrderingKey != "" {
+		return *n.OrderingKey
+	}
+	return n.Recipient
+}
+
 func (n *Notification) CanCancel() bool {
 	return n.Status == StatusPending || n.Status == StatusScheduled
 }
@@ -153,14 +191,24 @@ func (n *Notification) MarkProcessing() {
 	n.UpdatedAt = time.Now().UTC()
 }
 
-func (n *Notification) MarkDelivered(providerMessageID string) {
+// MarkSent records that the provider accepted the message. Whether it reached
+// the recipient is only known once a receipt arrives.
+func (n *Notification) MarkSent(providerMessageID string) {
 	now := time.Now().UTC()
-	n.Status = StatusDelivered
+	n.Status = StatusSent
 	n.ProviderMessageID = &providerMessageID
 	n.SentAt = &now
 	n.UpdatedAt = now
 }
 
+// RecordProvider notes the provider the latest delivery attempt went to.
+func (n *Notification) RecordProvider(name string) {
+	if name == "" {
+		return
+	}
+	n.Provider = &name
+}
+
 func (n *Notification) MarkFailed(errMsg string) {
 	now := time.Now().UTC()
 	n.Status = StatusFailed
@@ -169,6 +217,20 @@ func (n *Notification) MarkFailed(errMsg string) {
 	n.UpdatedAt = now
 }
 
+// MarkSuppressed skips the notification because its recipient is
+// suppressed.
+func (n *Notification) MarkSuppressed() {
+	msg := ErrRecipientSuppressed.Error()
+	n.Status = StatusSuppressed
+	n.ErrorMessage = &msg
+	n.UpdatedAt = time.Now().UTC()
+}
+
+func (n *Notification) MarkRetrying() {
+	n.Status = StatusPending
+	n.UpdatedAt = time.Now().UTC()
+}
+
 func (n *Notification) IncrementRetry() {
 	n.RetryCount++
 	n.UpdatedAt = time.Now().UTC()
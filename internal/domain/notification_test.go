@@ -116,11 +116,11 @@ func TestNotification_CanCancel(t *testing.T) {
 	assert.False(t, n.CanCancel())
 }
 
-func TestNotification_MarkDelivered(t *testing.T) {
+func TestNotification_MarkSent(t *testing.T) {
 	n, _ := NewNotification(ChannelSMS, "+90500000000", "Hello", PriorityNormal, nil)
-	n.MarkDelivered("provider-msg-123")
+	n.MarkSent("provider-msg-123")
 
-	assert.Equal(t, StatusDelivered, n.Status)
+	assert.Equal(t, StatusSent, n.Status)
 	assert.NotNil(t, n.SentAt)
 	assert.Equal(t, "provider-msg-123", *n.ProviderMessageID)
 }
@@ -146,3 +146,23 @@ func TestNotification_RetryLogic(t *testing.T) {
 	assert.False(t, n.HasRetriesLeft())
 	assert.Equal(t, 3, n.RetryCount)
 }
+
+func TestNotification_MarkRetrying(t *testing.T) {
+	n, _ := NewNotification(ChannelSMS, "+90500000000", "Hello", PriorityNormal, nil)
+	n.MarkProcessing()
+
+	n.MarkRetrying()
+
+	assert.Equal(t, StatusPending, n.Status)
+	assert.True(t, n.CanCancel())
+}
+
+func TestNotification_PartitionKey(t *testing.T) {
+	n, err := NewNotification(ChannelSMS, "+905551234567", "Hello", PriorityNormal, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "+905551234567", n.PartitionKey())
+
+	key := "order-42"
+	n.OrderingKey = &key
+	assert.Equal(t, "order-42", n.PartitionKey())
+}
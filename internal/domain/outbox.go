@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxMessage struct {
+	ID             int64
+	NotificationID uuid.UUID
+	Carrier        map[string]string
+	Attempts       int
+	LastError      *string
+	CreatedAt      time.Time
+	PublishedAt    *time.Time
+}
+
+// OutboxStats counts the outbox rows still waiting to be published and those
+// parked after repeated publish failures.
+type OutboxStats struct {
+	Pending int64 `db:"pending"`
+	Parked  int64 `db:"parked"`
+}
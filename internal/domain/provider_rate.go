@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// ProviderRate is one worker's send rate for a channel's provider. Rate
+// falls below Ceiling, the configured limit, while the provider is
+// throttling, and PausedUntil is set while a Retry-After holds sends back.
+// Both are zero for an unlimited provider. Shared means the ceiling is one
+// limit for all workers rather than each worker's own.
+type ProviderRate struct {
+	InstanceID  string
+	Channel     Channel
+	Provider    string
+	Rate        float64
+	Ceiling     float64
+	Shared      bool
+	PausedUntil *time.Time
+	UpdatedAt   time.Time
+}
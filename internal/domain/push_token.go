@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// InvalidPushToken is a device token a push provider reported as no longer
+// registered. Deliveries to it fail without calling the provider, and clients
+// read the list to prune their own device records.
+type InvalidPushToken struct {
+	Token         string
+	Provider      string
+	Reason        string
+	InvalidatedAt time.Time
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeliveryReceipt is a provider's report on a message it accepted earlier,
+// matched to the notification by provider and provider message ID.
+type DeliveryReceipt struct {
+	Provider          string
+	ProviderMessageID string
+	Status            Status
+	ErrorMessage      string
+	ReceivedAt        time.Time
+}
+
+// receiptRank orders the statuses a receipt can move a notification through.
+// Undelivered and delivered share a rank: neither replaces the other.
+var receiptRank = map[Status]int{
+	StatusSent:        1,
+	StatusDelivered:   2,
+	StatusUndelivered: 2,
+	StatusRead:        3,
+}
+
+func NewDeliveryReceipt(provider, providerMessageID string, status Status, errMsg string) (*DeliveryReceipt, error) {
+	if providerMessageID == "" {
+		return nil, fmt.Errorf("%w: provider message id is required", ErrInvalidReceipt)
+	}
+	if _, ok := receiptRank[status]; !ok {
+		return nil, fmt.Errorf("%w: unsupported status %s", ErrInvalidReceipt, status)
+	}
+	return &DeliveryReceipt{
+		Provider:          provider,
+		ProviderMessageID: providerMessageID,
+		Status:            status,
+		ErrorMessage:      errMsg,
+		ReceivedAt:        time.Now().UTC(),
+	}, nil
+}
+
+// ApplyReceipt moves the notification forward to the receipt's status.
+// Receipts may arrive late, twice or out of order, so one that would not
+// move it forward, or that follows an undelivered receipt, is ignored and
+// ApplyReceipt returns false.
+func (n *Notification) ApplyReceipt(r *DeliveryReceipt) bool {
+	current, ok := receiptRank[n.Status]
+	if !ok || n.Status == StatusUndelivered || receiptRank[r.Status] <= current {
+		return false
+	}
+
+	n.Status = r.Status
+	if r.Status == StatusUndelivered {
+		errMsg := r.ErrorMessage
+		if errMsg == "" {
+			errMsg = "provider reported the message undelivered"
+		}
+		n.ErrorMessage = &errMsg
+		n.FailedAt = &r.ReceivedAt
+	}
+	n.UpdatedAt = r.ReceivedAt
+	return true
+}
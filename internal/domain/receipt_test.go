@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sentNotification(t *testing.T) *Notification {
+	n, err := NewNotification(ChannelSMS, "+90500000000", "Hello", PriorityNormal, nil)
+	require.NoError(t, err)
+	n.MarkSent("SM123")
+	return n
+}
+
+func TestNewDeliveryReceipt_Validation(t *testing.T) {
+	_, err := NewDeliveryReceipt("twilio", "", StatusDelivered, "")
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+
+	_, err = NewDeliveryReceipt("twilio", "SM123", StatusPending, "")
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+
+	r, err := NewDeliveryReceipt("twilio", "SM123", StatusRead, "")
+	require.NoError(t, err)
+	assert.Equal(t, StatusRead, r.Status)
+}
+
+func TestNotification_ApplyReceipt_Advances(t *testing.T) {
+	n := sentNotification(t)
+
+	delivered, _ := NewDeliveryReceipt("twilio", "SM123", StatusDelivered, "")
+	assert.True(t, n.ApplyReceipt(delivered))
+	assert.Equal(t, StatusDelivered, n.Status)
+
+	read, _ := NewDeliveryReceipt("twilio", "SM123", StatusRead, "")
+	assert.True(t, n.ApplyReceipt(read))
+	assert.Equal(t, StatusRead, n.Status)
+}
+
+func TestNotification_ApplyReceipt_IgnoresStaleAndDuplicate(t *testing.T) {
+	n := sentNotification(t)
+	read, _ := NewDeliveryReceipt("twilio", "SM123", StatusRead, "")
+	require.True(t, n.ApplyReceipt(read))
+
+	delivered, _ := NewDeliveryReceipt("twilio", "SM123", StatusDelivered, "")
+	assert.False(t, n.ApplyReceipt(delivered))
+	assert.False(t, n.ApplyReceipt(read))
+	assert.Equal(t, StatusRead, n.Status)
+}
+
+func TestNotification_ApplyReceipt_Undelivered(t *testing.T) {
+	n := sentNotification(t)
+
+	undelivered, _ := NewDeliveryReceipt("twilio", "SM123", StatusUndelivered, "twilio error 30003")
+	require.True(t, n.ApplyReceipt(undelivered))
+	assert.Equal(t, StatusUndelivered, n.Status)
+	assert.Equal(t, "twilio error 30003", *n.ErrorMessage)
+	assert.NotNil(t, n.FailedAt)
+
+	read, _ := NewDeliveryReceipt("twilio", "SM123", StatusRead, "")
+	assert.False(t, n.ApplyReceipt(read))
+	assert.Equal(t, StatusUndelivered, n.Status)
+}
+
+func TestNotification_ApplyReceipt_RequiresSent(t *testing.T) {
+	n, _ := NewNotification(ChannelSMS, "+90500000000", "Hello", PriorityNormal, nil)
+	n.MarkProcessing()
+
+	delivered, _ := NewDeliveryReceipt("twilio", "SM123", StatusDelivered, "")
+	assert.False(t, n.ApplyReceipt(delivered))
+	assert.Equal(t, StatusProcessing, n.Status)
+}
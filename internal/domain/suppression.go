@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Suppression stops deliveries to a recipient on one channel until it
+// expires, or for good when ExpiresAt is nil. Providers add recipients they
+// reject as invalid and operators add opt-outs and bounces through the API;
+// Source names who added the entry.
+type Suppression struct {
+	Channel   Channel    `db:"channel"`
+	Recipient string     `db:"recipient"`
+	Reason    string     `db:"reason"`
+	Source    string     `db:"source"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func NewSuppression(channel Channel, recipient, reason, source string, expiresAt *time.Time) (*Suppression, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	if err := validateRecipient(channel, recipient); err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidSuppression)
+	}
+	if source == "" {
+		return nil, fmt.Errorf("%w: source is required", ErrInvalidSuppression)
+	}
+
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidSuppression)
+	}
+	return &Suppression{
+		Channel:   channel,
+		Recipient: recipient,
+		Reason:    reason,
+		Source:    source,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, nil
+}
+
+// Active reports whether the entry still suppresses its recipient at now.
+func (s *Suppression) Active(now time.Time) bool {
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// SuppressionFilter selects suppression entries in channel and recipient
+// order, starting after Cursor. Expired entries are left out unless
+// IncludeExpired is set.
+type SuppressionFilter struct {
+	Channel        *Channel
+	Cursor         *SuppressionCursor
+	IncludeExpired bool
+	PageSize       int
+}
+
+// SuppressionCursor is the last entry of the previous page.
+type SuppressionCursor struct {
+	Channel   Channel
+	Recipient string
+}
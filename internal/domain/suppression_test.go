@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSuppression(t *testing.T) {
+	expires := time.Now().Add(24 * time.Hour)
+	s, err := NewSuppression(ChannelSMS, "+90500000000", "replied STOP", "api", &expires)
+	require.NoError(t, err)
+	assert.Equal(t, ChannelSMS, s.Channel)
+	assert.False(t, s.CreatedAt.IsZero())
+
+	_, err = NewSuppression(ChannelSMS, "+90500000000", "", "api", nil)
+	assert.ErrorIs(t, err, ErrInvalidSuppression)
+
+	_, err = NewSuppression(ChannelEmail, "a@b.com", "bounce", "", nil)
+	assert.ErrorIs(t, err, ErrInvalidSuppression)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = NewSuppression(ChannelEmail, "a@b.com", "bounce", "api", &past)
+	assert.ErrorIs(t, err, ErrInvalidSuppression)
+}
+
+func TestSuppression_Active(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Hour)
+
+	assert.True(t, (&Suppression{}).Active(now), "no expiry")
+	s := &Suppression{ExpiresAt: &expires}
+	assert.True(t, s.Active(now))
+	assert.False(t, s.Active(expires))
+}
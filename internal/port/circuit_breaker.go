@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// BreakerController is a worker's set of provider circuit breakers.
+// ForceOpen, HoldOpen and Reset report false when there is no breaker for
+// that channel and provider.
+type BreakerController interface {
+	CircuitBreakers() []domain.CircuitBreaker
+	ForceOpen(channel domain.Channel, provider string) bool
+	HoldOpen(channel domain.Channel, provider string, until time.Time) bool
+	Reset(channel domain.Channel, provider string) bool
+}
+
+// CircuitBreakerRepository holds the breaker states every worker reported
+// and the controls that apply to all of them.
+type CircuitBreakerRepository interface {
+	SaveStates(ctx context.Context, breakers []domain.CircuitBreaker) error
+	ListStatesSince(ctx context.Context, since time.Time) ([]domain.CircuitBreaker, error)
+
+	ListControls(ctx context.Context) ([]domain.BreakerControl, error)
+	ForceOpen(ctx context.Context, channel domain.Channel, provider, by string) error
+	// HoldOpen moves OpenUntil later, never earlier.
+	HoldOpen(ctx context.Context, channel domain.Channel, provider string, until time.Time, by string) error
+	// Reset clears ForcedOpen and OpenUntil and sets ResetAt to now.
+	Reset(ctx context.Context, channel domain.Channel, provider, by string) error
+}
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// ContactRepository stores recipient profiles and their addresses.
+type ContactRepository interface {
+	CreateUser(ctx context.Context, user *domain.User) error
+	// GetUser returns the user with all their addresses, oldest first.
+	GetUser(ctx context.Context, id string) (*domain.User, error)
+	ListUsers(ctx context.Context, cursor *string, limit int) ([]*domain.User, error)
+	UpdateUser(ctx context.Context, user *domain.User) error
+	// DeleteUser deletes the user and their addresses.
+	DeleteUser(ctx context.Context, id string) error
+	AddAddress(ctx context.Context, address *domain.ContactAddress) error
+	UpdateAddress(ctx context.Context, address *domain.ContactAddress) error
+	DeleteAddress(ctx context.Context, userID string, id uuid.UUID) error
+	// ActiveAddresses returns the user's active addresses on channel, oldest
+	// first, leaving out push tokens a provider has reported unregistered.
+	ActiveAddresses(ctx context.Context, userID string, channel domain.Channel) ([]*domain.ContactAddress, error)
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type DeadLetterRepository interface {
+	Save(ctx context.Context, deadLetter *domain.DeadLetter) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.DeadLetter, error)
+	List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error)
+	Redrive(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type LeaseStore interface {
+	TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, holder string) error
+	Get(ctx context.Context, name string) (*domain.Lease, error)
+}
@@ -17,7 +17,33 @@ type NotificationRepository interface {
 	UpdateStatus(ctx context.Context, notification *domain.Notification) error
 	Cancel(ctx context.Context, id uuid.UUID) error
 	IncrementBatchCounter(ctx context.Context, batchID uuid.UUID, status domain.Status) error
-	ListDueScheduled(ctx context.Context, limit int) ([]*domain.Notification, error)
-	ListStuckProcessing(ctx context.Context, olderThan time.Duration, limit int) ([]*domain.Notification, error)
+	GetByProviderMessageID(ctx context.Context, provider, providerMessageID string) (*domain.Notification, error)
+	// TransitionStatus saves the notification if its stored status is still
+	// from, moving its batch's counters from one status to the other in the
+	// same transaction. It returns domain.ErrClaimConflict when the status
+	// has changed underneath.
+	TransitionStatus(ctx context.Context, notification *domain.Notification, from domain.Status) error
+	ListDueScheduled(ctx context.Context, until time.Time, after *domain.ScheduleCursor, limit int) ([]*domain.Notification, error)
+	// ClaimScheduled moves a due notification from scheduled to pending and
+	// writes its outbox row in the same transaction.
+	ClaimScheduled(ctx context.Context, id uuid.UUID) (*domain.Notification, error)
+	ClaimForDelivery(ctx context.Context, id uuid.UUID) (*domain.Notification, error)
+	// ResetStuckProcessing returns notifications stuck in processing to
+	// pending and queues them again through the outbox.
+	ResetStuckProcessing(ctx context.Context, olderThan time.Duration, limit int) ([]*domain.Notification, error)
 	GetChannelMetrics(ctx context.Context) ([]domain.ChannelStats, error)
+	// CreateFallback saves and queues next as the attempt after from. It
+	// returns domain.ErrClaimConflict if from already has one.
+	CreateFallback(ctx context.Context, from, next *domain.Notification) error
+	// ListChain returns a fallback chain's attempts, oldest first.
+	ListChain(ctx context.Context, parentID uuid.UUID) ([]*domain.Notification, error)
+	ListFallbacksDue(ctx context.Context, now time.Time, after *uuid.UUID, limit int) ([]*domain.Notification, error)
+	// CreateGroup saves and queues a group's siblings in one transaction.
+	CreateGroup(ctx context.Context, group *domain.NotificationGroup) error
+	GetGroup(ctx context.Context, groupID uuid.UUID) (*domain.NotificationGroup, error)
+	GetGroupByIdempotencyKey(ctx context.Context, key string) (*domain.NotificationGroup, error)
+	// CancelGroup cancels the group's pending and scheduled siblings, moving
+	// their batch counters along, and returns their IDs. It returns
+	// ErrGroupNotFound for an unknown group.
+	CancelGroup(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
 }
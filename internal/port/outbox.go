@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type OutboxRepository interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64) error
+	// MarkFailed records a failed publish and holds the row back until
+	// retryAt.
+	MarkFailed(ctx context.Context, id int64, errMsg string, retryAt time.Time) error
+	// Park stops relaying a row that kept failing. It stays in the table,
+	// with its last error, for an operator to look into.
+	Park(ctx context.Context, id int64, errMsg string) error
+	// DeletePublished removes up to limit rows published before cutoff and
+	// returns how many it removed.
+	DeletePublished(ctx context.Context, cutoff time.Time, limit int) (int, error)
+	Stats(ctx context.Context) (domain.OutboxStats, error)
+}
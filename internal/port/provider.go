@@ -2,16 +2,113 @@ package port
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 )
 
+// ProviderResponse is an accepted send. AwaitsReceipt is set when the
+// provider will post a delivery receipt for the message; without one, sent is
+// the last status the notification reaches.
 type ProviderResponse struct {
-	MessageID string
-	Status    string
-	Timestamp string
+	Provider      string
+	MessageID     string
+	Status        string
+	Timestamp     string
+	AwaitsReceipt bool
 }
 
 type DeliveryProvider interface {
 	Send(ctx context.Context, notification *domain.Notification) (*ProviderResponse, error)
 }
+
+// ErrorCategory says why a provider refused a message, which decides whether
+// and when it is retried.
+type ErrorCategory string
+
+const (
+	CategoryRateLimited      ErrorCategory = "rate_limited"
+	CategoryTransient        ErrorCategory = "transient"
+	CategoryInvalidRecipient ErrorCategory = "invalid_recipient"
+	CategoryContentRejected  ErrorCategory = "content_rejected"
+	CategoryAuthFailure      ErrorCategory = "auth_failure"
+	CategoryQuotaExceeded    ErrorCategory = "quota_exceeded"
+	// CategoryPermanent is any other rejection the provider won't change its
+	// mind about.
+	CategoryPermanent ErrorCategory = "permanent"
+)
+
+// RetryPolicy is how a category is retried. MaxRetries caps the retries
+// below the notification's own limit when set; MinDelay is the shortest
+// backoff before the next attempt.
+type RetryPolicy struct {
+	Retry      bool
+	MaxRetries int
+	MinDelay   time.Duration
+}
+
+var retryPolicies = map[ErrorCategory]RetryPolicy{
+	CategoryTransient:   {Retry: true},
+	CategoryRateLimited: {Retry: true, MinDelay: 5 * time.Second},
+	// Quotas reset on the provider's schedule, not ours.
+	CategoryQuotaExceeded: {Retry: true, MaxRetries: 2, MinDelay: 10 * time.Minute},
+	// Worth one retry for a token that was refreshed or a key mid-rotation.
+	CategoryAuthFailure: {Retry: true, MaxRetries: 1, MinDelay: time.Minute},
+}
+
+// Policy returns the category's retry policy. Categories without one are
+// not retried.
+func (c ErrorCategory) Policy() RetryPolicy {
+	return retryPolicies[c]
+}
+
+// ProviderError is a failed send, classified. Provider names the provider it
+// was last attempted on; Code is the provider's own error code, if any.
+// RetryAfter is the provider's requested wait, when it gave one.
+type ProviderError struct {
+	Provider   string
+	Category   ErrorCategory
+	Code       string
+	RetryAfter time.Duration
+	Err        error
+}
+
+func NewProviderError(category ErrorCategory, code string, err error) *ProviderError {
+	return &ProviderError{Category: category, Code: code, Err: err}
+}
+
+func (e *ProviderError) Error() string {
+	msg := e.Err.Error()
+	if e.Category != "" {
+		msg = string(e.Category) + ": " + msg
+	}
+	if e.Provider != "" {
+		msg = e.Provider + ": " + msg
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// MinRetryDelay is the shortest wait before retrying: the category's floor,
+// or the provider's Retry-After when that is longer.
+func (e *ProviderError) MinRetryDelay() time.Duration {
+	return max(e.Category.Policy().MinDelay, e.RetryAfter)
+}
+
+// Classify returns the category of a send error. Errors that aren't a
+// ProviderError are transient if they wrap domain.ErrProviderUnavailable or
+// domain.ErrCircuitOpen, and permanent otherwise.
+func Classify(err error) ErrorCategory {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.Category != "" {
+		return providerErr.Category
+	}
+	if errors.Is(err, domain.ErrProviderUnavailable) || errors.Is(err, domain.ErrCircuitOpen) {
+		return CategoryTransient
+	}
+	return CategoryPermanent
+}
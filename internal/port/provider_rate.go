@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// ProviderRateSource reports the current send rate of each provider route.
+type ProviderRateSource interface {
+	ProviderRates() []domain.ProviderRate
+}
+
+// ProviderRateRepository holds the rates every worker reported, so the API
+// can show them.
+type ProviderRateRepository interface {
+	Save(ctx context.Context, rates []domain.ProviderRate) error
+	ListSince(ctx context.Context, since time.Time) ([]domain.ProviderRate, error)
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+type PushTokenRepository interface {
+	Invalidate(ctx context.Context, token *domain.InvalidPushToken) error
+	IsInvalid(ctx context.Context, token string) (bool, error)
+	ListInvalid(ctx context.Context, since *time.Time, limit int) ([]*domain.InvalidPushToken, error)
+}
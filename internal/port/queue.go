@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/mehmetymw/event-driven-ns/internal/domain"
 )
@@ -18,3 +19,17 @@ type QueueConsumer interface {
 	Start(ctx context.Context, handler MessageHandler) error
 	Stop(ctx context.Context) error
 }
+
+type ScheduledMessageHandler func(ctx context.Context, notificationID string, dueAt time.Time) error
+
+type ScheduledQueueConsumer interface {
+	Start(ctx context.Context, handler ScheduledMessageHandler) error
+	Stop(ctx context.Context) error
+}
+
+type DeadLetterHandler func(ctx context.Context, deadLetter *domain.DeadLetter) error
+
+type DeadLetterConsumer interface {
+	Start(ctx context.Context, handler DeadLetterHandler) error
+	Stop(ctx context.Context) error
+}
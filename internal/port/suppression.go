@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+
+	"github.com/mehmetymw/event-driven-ns/internal/domain"
+)
+
+// SuppressionRepository stores the suppression list. IsSuppressed and
+// Suppressed only count entries that haven't expired.
+type SuppressionRepository interface {
+	Suppress(ctx context.Context, s *domain.Suppression) error
+	Remove(ctx context.Context, channel domain.Channel, recipient string) error
+	List(ctx context.Context, filter domain.SuppressionFilter) ([]*domain.Suppression, error)
+	IsSuppressed(ctx context.Context, channel domain.Channel, recipient string) (bool, error)
+	// Suppressed returns which of the recipients are suppressed on channel.
+	Suppressed(ctx context.Context, channel domain.Channel, recipients []string) (map[string]bool, error)
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications(id),
+    trace_carrier JSONB,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_outbox_unpublished ON notification_outbox(id) WHERE published_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY,
+    notification_id UUID,
+    topic VARCHAR(255) NOT NULL,
+    kafka_partition INT NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    payload BYTEA NOT NULL,
+    error TEXT NOT NULL,
+    attempts JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    redriven_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_dead_letters_created_at ON dead_letters(created_at DESC, id DESC);
+CREATE INDEX idx_dead_letters_notification_id ON dead_letters(notification_id);
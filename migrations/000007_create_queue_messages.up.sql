@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id UUID NOT NULL,
+    priority VARCHAR(10) NOT NULL,
+    priority_rank SMALLINT NOT NULL,
+    trace_carrier JSONB,
+    attempts INT NOT NULL DEFAULT 0,
+    attempt_history JSONB NOT NULL DEFAULT '[]',
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queue_messages_ready ON queue_messages(priority_rank, available_at, id);
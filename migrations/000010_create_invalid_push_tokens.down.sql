@@ -0,0 +1 @@
+DROP TABLE IF EXISTS invalid_push_tokens;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS invalid_push_tokens (
+    token TEXT PRIMARY KEY,
+    provider VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL,
+    invalidated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_invalid_push_tokens_invalidated_at ON invalid_push_tokens(invalidated_at DESC);
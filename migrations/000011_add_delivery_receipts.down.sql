@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notifications_provider_message_id;
+
+ALTER TABLE notification_batches
+    DROP COLUMN IF EXISTS sent_count,
+    DROP COLUMN IF EXISTS undelivered_count,
+    DROP COLUMN IF EXISTS read_count;
@@ -0,0 +1,7 @@
+ALTER TABLE notification_batches
+    ADD COLUMN IF NOT EXISTS sent_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS undelivered_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS read_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_provider_message_id
+    ON notifications(provider_message_id) WHERE provider_message_id IS NOT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS suppressions (
+    channel VARCHAR(10) NOT NULL,
+    recipient TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    source VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (channel, recipient)
+);
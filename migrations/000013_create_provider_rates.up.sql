@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS provider_rates (
+    instance_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    ceiling DOUBLE PRECISION NOT NULL,
+    paused_until TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (instance_id, channel, provider)
+);
+
+CREATE INDEX idx_provider_rates_updated_at ON provider_rates(updated_at);
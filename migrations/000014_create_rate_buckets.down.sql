@@ -0,0 +1,3 @@
+ALTER TABLE provider_rates DROP COLUMN IF EXISTS shared;
+
+DROP TABLE IF EXISTS rate_buckets;
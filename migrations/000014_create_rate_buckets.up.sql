@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS rate_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE provider_rates ADD COLUMN shared BOOLEAN NOT NULL DEFAULT FALSE;
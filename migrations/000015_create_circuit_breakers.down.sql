@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS circuit_breaker_controls;
+DROP TABLE IF EXISTS circuit_breakers;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS circuit_breakers (
+    instance_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    forced_open BOOLEAN NOT NULL DEFAULT FALSE,
+    open_until TIMESTAMPTZ,
+    transitions JSONB NOT NULL DEFAULT '{}',
+    changed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (instance_id, channel, provider)
+);
+
+CREATE INDEX idx_circuit_breakers_updated_at ON circuit_breakers(updated_at);
+
+CREATE TABLE IF NOT EXISTS circuit_breaker_controls (
+    channel VARCHAR(10) NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    forced_open BOOLEAN NOT NULL DEFAULT FALSE,
+    open_until TIMESTAMPTZ,
+    reset_at TIMESTAMPTZ,
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (channel, provider)
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_notifications_fallback_due;
+DROP INDEX IF EXISTS idx_notifications_parent_id;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS next_attempt_id,
+    DROP COLUMN IF EXISTS fallback_at,
+    DROP COLUMN IF EXISTS fallbacks,
+    DROP COLUMN IF EXISTS parent_id;
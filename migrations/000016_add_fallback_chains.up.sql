@@ -0,0 +1,9 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES notifications(id),
+    ADD COLUMN IF NOT EXISTS fallbacks JSONB,
+    ADD COLUMN IF NOT EXISTS fallback_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS next_attempt_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_parent_id ON notifications(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_fallback_due
+    ON notifications(fallback_at) WHERE fallbacks IS NOT NULL AND next_attempt_id IS NULL;
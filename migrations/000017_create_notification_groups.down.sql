@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_notifications_group_id;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS group_id;
+
+DROP TABLE IF EXISTS notification_groups;
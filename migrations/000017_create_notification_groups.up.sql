@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_groups (
+    id UUID PRIMARY KEY,
+    idempotency_key VARCHAR(255) UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES notification_groups(id);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_group_id ON notifications(group_id) WHERE group_id IS NOT NULL;
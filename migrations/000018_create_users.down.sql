@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notifications_user_id;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS user_addresses;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,3 @@
+ALTER TABLE notification_batches DROP COLUMN IF EXISTS suppressed_count;
+
+ALTER TABLE suppressions DROP COLUMN IF EXISTS expires_at;
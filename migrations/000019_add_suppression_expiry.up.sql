@@ -0,0 +1,3 @@
+ALTER TABLE suppressions ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+ALTER TABLE notification_batches ADD COLUMN IF NOT EXISTS suppressed_count INT NOT NULL DEFAULT 0;
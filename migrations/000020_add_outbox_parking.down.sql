@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notification_outbox_unpublished;
+CREATE INDEX idx_notification_outbox_unpublished ON notification_outbox(id) WHERE published_at IS NULL;
+
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS parked_at;
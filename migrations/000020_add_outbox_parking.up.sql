@@ -0,0 +1,4 @@
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS parked_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_notification_outbox_unpublished;
+CREATE INDEX idx_notification_outbox_unpublished ON notification_outbox(id) WHERE published_at IS NULL AND parked_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE queue_messages ADD COLUMN IF NOT EXISTS channel VARCHAR(10) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE rate_buckets DROP COLUMN IF EXISTS rate;
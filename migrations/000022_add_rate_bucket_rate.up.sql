@@ -0,0 +1 @@
+ALTER TABLE rate_buckets ADD COLUMN IF NOT EXISTS rate DOUBLE PRECISION;
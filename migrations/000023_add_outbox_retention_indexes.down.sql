@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notification_outbox_parked;
+DROP INDEX IF EXISTS idx_notification_outbox_published;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_published ON notification_outbox(published_at) WHERE published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_parked ON notification_outbox(id) WHERE parked_at IS NOT NULL;
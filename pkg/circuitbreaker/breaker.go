@@ -1,35 +1,237 @@
 package circuitbreaker
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/sony/gobreaker/v2"
 )
 
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// Settings tune a Breaker. Zero fields take the defaults: trip after 5
+// consecutive failures, stay open 30 seconds, then let 3 requests through
+// half-open to decide whether to close again.
+type Settings struct {
+	Failures         int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+}
+
+func DefaultSettings() Settings {
+	return Settings{Failures: 5, OpenTimeout: 30 * time.Second, HalfOpenRequests: 3}
+}
+
+func (s Settings) withDefaults() Settings {
+	d := DefaultSettings()
+	if s.Failures < 1 {
+		s.Failures = d.Failures
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = d.OpenTimeout
+	}
+	if s.HalfOpenRequests < 1 {
+		s.HalfOpenRequests = d.HalfOpenRequests
+	}
+	return s
+}
+
+// Breaker wraps a gobreaker circuit breaker with the controls an operator
+// needs during an incident: ForceOpen holds it open until Reset, and
+// HoldOpen keeps it open until a given time, which is how a trip on another
+// worker is applied here. It counts how often it entered each state.
 type Breaker struct {
-	cb *gobreaker.CircuitBreaker[any]
+	name     string
+	settings Settings
+
+	mu          sync.Mutex
+	cb          *gobreaker.CircuitBreaker[any]
+	forced      bool
+	holdUntil   time.Time
+	openUntil   time.Time
+	transitions map[string]int64
+	changedAt   time.Time
+	now         func() time.Time
+}
+
+// Snapshot is a Breaker's state at one moment. OpenUntil is when an open
+// breaker lets its half-open probes through, zero while it is forced open.
+type Snapshot struct {
+	State       string
+	Forced      bool
+	OpenUntil   time.Time
+	Transitions map[string]int64
+	ChangedAt   time.Time
+}
+
+func New(name string, settings Settings) *Breaker {
+	b := &Breaker{
+		name:        name,
+		settings:    settings.withDefaults(),
+		transitions: make(map[string]int64),
+		now:         time.Now,
+	}
+	b.cb = b.newCircuitBreaker()
+	return b
 }
 
-func New(name string) *Breaker {
-	settings := gobreaker.Settings{
-		Name:        name,
-		MaxRequests: 3,
+func (b *Breaker) newCircuitBreaker() *gobreaker.CircuitBreaker[any] {
+	failures := uint32(b.settings.Failures)
+	return gobreaker.NewCircuitBreaker[any](gobreaker.Settings{
+		Name:        b.name,
+		MaxRequests: uint32(b.settings.HalfOpenRequests),
 		Interval:    60 * time.Second,
-		Timeout:     30 * time.Second,
+		Timeout:     b.settings.OpenTimeout,
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			return counts.ConsecutiveFailures >= 5
+			return counts.ConsecutiveFailures >= failures
 		},
-	}
+		// Called with the gobreaker's own lock held, so it must not call
+		// back into it.
+		OnStateChange: func(_ string, _, to gobreaker.State) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			now := b.now()
+			b.record(to.String(), now)
+			if to == gobreaker.StateOpen {
+				b.openUntil = now.Add(b.settings.OpenTimeout)
+			}
+		},
+	})
+}
 
-	return &Breaker{
-		cb: gobreaker.NewCircuitBreaker[any](settings),
-	}
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) Settings() Settings {
+	return b.settings
 }
 
 func (b *Breaker) Execute(fn func() (any, error)) (any, error) {
-	return b.cb.Execute(fn)
+	if b.held() {
+		return nil, gobreaker.ErrOpenState
+	}
+	b.mu.Lock()
+	cb := b.cb
+	b.mu.Unlock()
+	return cb.Execute(fn)
 }
 
 func (b *Breaker) State() string {
-	return b.cb.State().String()
+	if b.held() {
+		return StateOpen
+	}
+	b.mu.Lock()
+	cb := b.cb
+	b.mu.Unlock()
+	return cb.State().String()
+}
+
+// Open reports whether the breaker is currently rejecting calls.
+func (b *Breaker) Open() bool {
+	return b.State() == StateOpen
+}
+
+// ForceOpen rejects every call until Reset.
+func (b *Breaker) ForceOpen() {
+	before := b.State()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.forced = true
+	b.transition(before, StateOpen)
+}
+
+// HoldOpen rejects every call until the given time, unless the breaker is
+// already held open for longer.
+func (b *Breaker) HoldOpen(until time.Time) {
+	before := b.State()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !until.After(b.now()) || !until.After(b.holdUntil) {
+		return
+	}
+	b.holdUntil = until
+	b.transition(before, StateOpen)
+}
+
+// Reset clears a forced or held state and closes the breaker with fresh
+// counts.
+func (b *Breaker) Reset() {
+	before := b.State()
+	cb := b.newCircuitBreaker()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cb = cb
+	b.forced = false
+	b.holdUntil = time.Time{}
+	b.openUntil = time.Time{}
+	b.transition(before, StateClosed)
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	state := b.State()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Snapshot{
+		State:       state,
+		Forced:      b.forced,
+		Transitions: make(map[string]int64, len(b.transitions)),
+		ChangedAt:   b.changedAt,
+	}
+	for to, n := range b.transitions {
+		s.Transitions[to] = n
+	}
+	if state == StateOpen && !b.forced {
+		s.OpenUntil = b.openUntil
+		if b.holdUntil.After(s.OpenUntil) {
+			s.OpenUntil = b.holdUntil
+		}
+	}
+	return s
+}
+
+// held reports whether the breaker is forced or held open, counting the
+// transition back when a hold has just run out.
+func (b *Breaker) held() bool {
+	b.mu.Lock()
+	if b.forced || b.now().Before(b.holdUntil) {
+		b.mu.Unlock()
+		return true
+	}
+	expired := !b.holdUntil.IsZero()
+	b.holdUntil = time.Time{}
+	cb := b.cb
+	b.mu.Unlock()
+
+	if expired {
+		if state := cb.State().String(); state != StateOpen {
+			b.mu.Lock()
+			b.record(state, b.now())
+			b.mu.Unlock()
+		}
+	}
+	return false
+}
+
+func (b *Breaker) transition(from, to string) {
+	if from != to {
+		b.record(to, b.now())
+	}
+}
+
+func (b *Breaker) record(to string, now time.Time) {
+	b.transitions[to]++
+	b.changedAt = now
+}
+
+// IsOpen reports whether err is the breaker rejecting a call rather than an
+// error from the call itself.
+func IsOpen(err error) bool {
+	return errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests)
 }
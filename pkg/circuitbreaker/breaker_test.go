@@ -0,0 +1,89 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = errors.New("down")
+
+func fail() (any, error) { return nil, errDown }
+
+func succeed() (any, error) { return "ok", nil }
+
+func TestBreaker_TripsAfterConfiguredFailures(t *testing.T) {
+	b := New("sms/twilio", Settings{Failures: 2, OpenTimeout: time.Minute})
+
+	_, _ = b.Execute(fail)
+	assert.Equal(t, StateClosed, b.State())
+	_, _ = b.Execute(fail)
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err := b.Execute(succeed)
+	assert.True(t, IsOpen(err))
+
+	snap := b.Snapshot()
+	assert.Equal(t, int64(1), snap.Transitions[StateOpen])
+	assert.WithinDuration(t, time.Now().Add(time.Minute), snap.OpenUntil, time.Second)
+}
+
+func TestBreaker_DefaultsZeroSettings(t *testing.T) {
+	b := New("email/smtp", Settings{OpenTimeout: time.Minute})
+
+	assert.Equal(t, Settings{Failures: 5, OpenTimeout: time.Minute, HalfOpenRequests: 3}, b.Settings())
+}
+
+func TestBreaker_ForceOpenUntilReset(t *testing.T) {
+	b := New("sms/twilio", Settings{})
+
+	b.ForceOpen()
+	_, err := b.Execute(succeed)
+	assert.True(t, IsOpen(err))
+	snap := b.Snapshot()
+	assert.True(t, snap.Forced)
+	assert.True(t, snap.OpenUntil.IsZero())
+
+	b.Reset()
+	result, err := b.Execute(succeed)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+
+	snap = b.Snapshot()
+	assert.Equal(t, StateClosed, snap.State)
+	assert.False(t, snap.Forced)
+	assert.Equal(t, map[string]int64{StateOpen: 1, StateClosed: 1}, snap.Transitions)
+}
+
+func TestBreaker_ResetClosesTrippedBreaker(t *testing.T) {
+	b := New("sms/twilio", Settings{Failures: 1, OpenTimeout: time.Hour})
+	_, _ = b.Execute(fail)
+	require.True(t, b.Open())
+
+	b.Reset()
+
+	assert.Equal(t, StateClosed, b.State())
+	_, err := b.Execute(succeed)
+	assert.NoError(t, err)
+}
+
+func TestBreaker_HoldOpenExpires(t *testing.T) {
+	b := New("push/fcm", Settings{})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.HoldOpen(now.Add(10 * time.Second))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, now.Add(10*time.Second), b.Snapshot().OpenUntil)
+
+	// A shorter hold doesn't cut a longer one short.
+	b.HoldOpen(now.Add(5 * time.Second))
+	assert.Equal(t, now.Add(10*time.Second), b.Snapshot().OpenUntil)
+
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, map[string]int64{StateOpen: 1, StateClosed: 1}, b.Snapshot().Transitions)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// BreakerConfig tunes the provider circuit breakers. The defaults come from
+// BREAKER_FAILURES, BREAKER_OPEN_TIMEOUT and BREAKER_HALF_OPEN_REQUESTS, and
+// BREAKER_SETTINGS overrides them per channel, provider or route; see
+// cmd/worker. Shared, from BREAKER_SHARED, opens a breaker on every worker
+// when it trips on one.
+type BreakerConfig struct {
+	Failures         int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+	Settings         string
+	Shared           bool
+}
+
+func loadBreakers() (BreakerConfig, error) {
+	openTimeout, err := parseDuration(getEnv("BREAKER_OPEN_TIMEOUT", ""), 30*time.Second)
+	if err != nil {
+		return BreakerConfig{}, fmt.Errorf("BREAKER_OPEN_TIMEOUT: %w", err)
+	}
+
+	var env envParser
+	breakers := BreakerConfig{
+		Failures:         env.getInt("BREAKER_FAILURES", 5),
+		OpenTimeout:      openTimeout,
+		HalfOpenRequests: env.getInt("BREAKER_HALF_OPEN_REQUESTS", 3),
+		Settings:         getEnv("BREAKER_SETTINGS", ""),
+		Shared:           env.getBool("BREAKER_SHARED", false),
+	}
+	if err := env.err(); err != nil {
+		return BreakerConfig{}, err
+	}
+	return breakers, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_RejectsUnparseableSettings(t *testing.T) {
+	t.Setenv("WORKER_CONCURRENCY", "twenty")
+	t.Setenv("ORDERED_DELIVERY", "yes please")
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "WORKER_CONCURRENCY")
+	assert.ErrorContains(t, err, "ORDERED_DELIVERY", "every bad setting is reported")
+}
+
+func TestLoad_RejectsUnparseableBreakerSetting(t *testing.T) {
+	t.Setenv("BREAKER_FAILURES", "5x")
+
+	_, err := Load()
+	assert.ErrorContains(t, err, "BREAKER_FAILURES")
+}
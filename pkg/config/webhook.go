@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookAuthNone   = ""
+	WebhookAuthBearer = "bearer"
+	WebhookAuthBasic  = "basic"
+	WebhookAuthOAuth2 = "oauth2"
+)
+
+// WebhookChannels are the channels that get their own webhook settings.
+var WebhookChannels = []string{"sms", "email", "push"}
+
+// WebhookConfig is one channel's webhook endpoint. Every setting is read
+// from WEBHOOK_<SETTING>_<CHANNEL>, falling back to WEBHOOK_<SETTING>, so a
+// channel only lists what differs from the shared endpoint.
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	// Secrets sign requests, current first; see pkg/webhooksig.
+	Secrets []string
+
+	Auth               string
+	BearerToken        string
+	BasicUsername      string
+	BasicPassword      string
+	OAuth2TokenURL     string
+	OAuth2ClientID     string
+	OAuth2ClientSecret string
+	OAuth2Scopes       []string
+
+	Timeout           time.Duration
+	TransientStatuses []int
+}
+
+func loadWebhooks() (map[string]WebhookConfig, error) {
+	webhooks := make(map[string]WebhookConfig, len(WebhookChannels))
+	for _, ch := range WebhookChannels {
+		wh, err := loadWebhook(func(setting, fallback string) string {
+			return getEnv("WEBHOOK_"+setting+"_"+strings.ToUpper(ch), getEnv("WEBHOOK_"+setting, fallback))
+		}, "https://webhook.site/test")
+		if err != nil {
+			return nil, fmt.Errorf("webhook settings for %s: %w", ch, err)
+		}
+		webhooks[ch] = wh
+	}
+	return webhooks, nil
+}
+
+// loadFallbackWebhook reads the secondary endpoint from
+// FALLBACK_WEBHOOK_<SETTING>. Nothing is inherited from the primary webhook:
+// it is usually another vendor, so the primary's headers, credentials and
+// signing secrets must not reach it.
+func loadFallbackWebhook() (WebhookConfig, error) {
+	wh, err := loadWebhook(func(setting, fallback string) string {
+		return getEnv("FALLBACK_WEBHOOK_"+setting, fallback)
+	}, "")
+	if err != nil {
+		return WebhookConfig{}, fmt.Errorf("fallback webhook settings: %w", err)
+	}
+	return wh, nil
+}
+
+func loadWebhook(get func(setting, fallback string) string, defaultURL string) (WebhookConfig, error) {
+	timeout, err := parseDuration(get("TIMEOUT", ""), 5*time.Second)
+	if err != nil {
+		return WebhookConfig{}, fmt.Errorf("TIMEOUT: %w", err)
+	}
+	statuses, err := parseStatuses(get("TRANSIENT_STATUSES", "429,500,502,503,504"))
+	if err != nil {
+		return WebhookConfig{}, fmt.Errorf("TRANSIENT_STATUSES: %w", err)
+	}
+
+	return WebhookConfig{
+		URL:                get("URL", defaultURL),
+		Headers:            parseHeaders(get("HEADERS", "")),
+		Secrets:            splitList(get("SECRETS", "")),
+		Auth:               strings.ToLower(get("AUTH", WebhookAuthNone)),
+		BearerToken:        get("TOKEN", ""),
+		BasicUsername:      get("USERNAME", ""),
+		BasicPassword:      get("PASSWORD", ""),
+		OAuth2TokenURL:     get("OAUTH2_TOKEN_URL", ""),
+		OAuth2ClientID:     get("OAUTH2_CLIENT_ID", ""),
+		OAuth2ClientSecret: get("OAUTH2_CLIENT_SECRET", ""),
+		OAuth2Scopes:       splitList(get("OAUTH2_SCOPES", "")),
+		Timeout:            timeout,
+		TransientStatuses:  statuses,
+	}, nil
+}
+
+// parseHeaders reads "Name: value; Name2: value2".
+func parseHeaders(spec string) map[string]string {
+	headers := map[string]string{}
+	for _, entry := range strings.Split(spec, ";") {
+		name, value, ok := strings.Cut(entry, ":")
+		if name = strings.TrimSpace(name); ok && name != "" {
+			headers[name] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+func splitList(spec string) []string {
+	var list []string
+	for _, item := range strings.Split(spec, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseDuration reads a positive duration, or fallback when val is empty.
+func parseDuration(val string, fallback time.Duration) (time.Duration, error) {
+	if val == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", val)
+	}
+	return d, nil
+}
+
+// parseStatuses reads a comma-separated list of HTTP status codes.
+func parseStatuses(spec string) ([]int, error) {
+	var statuses []int
+	for _, item := range splitList(spec) {
+		status, err := strconv.Atoi(item)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("invalid HTTP status %q", item)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,237 @@
+// Package ratelimit provides an adaptive token bucket that backs off when a
+// downstream service throttles and recovers gradually once it stops: additive
+// increase, multiplicative decrease (AIMD).
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// decreaseFactor scales the rate on each throttle.
+	decreaseFactor = 0.5
+	// recoverySteps is how many additive steps take the rate from zero back
+	// to the ceiling; one step is allowed per recoverEvery.
+	recoverySteps = 20
+	recoverEvery  = time.Second
+	// floorDivisor keeps the rate from collapsing below ceiling/floorDivisor.
+	floorDivisor = 20
+	// A shared limiter leases a tenth of a second's tokens per store round
+	// trip and gives up what it has not used after leaseFor, so an idle
+	// process cannot hoard a burst.
+	leaseDivisor = 10
+	leaseFor     = time.Second
+)
+
+// Limiter paces events at up to Ceiling per second. Throttled halves the
+// rate, at most once per second so a burst of rejections for requests that
+// were already in flight counts once, and a Retry-After pauses the limiter
+// until it has passed. Each Success after a quiet second adds a twentieth of
+// the ceiling back.
+//
+// A shared limiter draws its tokens from a Store instead, so every process
+// using the same key stays under one ceiling. It takes the rate from the
+// store on every lease and writes its own adjustments back, so a throttle
+// seen by one process slows them all. If the store fails it paces locally
+// until the store is back.
+type Limiter struct {
+	ceiling float64
+	floor   float64
+	step    float64
+	store   Store
+	key     string
+
+	mu           sync.Mutex
+	limiter      *rate.Limiter
+	current      float64
+	pausedUntil  time.Time
+	lastChange   time.Time
+	lastDecrease time.Time
+	leased       int
+	leaseExpires time.Time
+	now          func() time.Time
+}
+
+// New returns a limiter running at ceiling events per second. A ceiling of
+// zero or less never limits.
+func New(ceiling float64) *Limiter {
+	l := &Limiter{
+		ceiling: ceiling,
+		floor:   ceiling / floorDivisor,
+		step:    ceiling / recoverySteps,
+		current: ceiling,
+		now:     time.Now,
+	}
+	if ceiling > 0 {
+		l.limiter = rate.NewLimiter(rate.Limit(ceiling), burst(ceiling))
+	}
+	return l
+}
+
+// NewShared returns a limiter whose tokens come from the bucket under key in
+// store.
+func NewShared(ceiling float64, store Store, key string) *Limiter {
+	l := New(ceiling)
+	l.store = store
+	l.key = key
+	return l
+}
+
+// Wait blocks until an event may happen. It does not wait out a pause; see
+// PausedFor.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.limiter == nil {
+		return nil
+	}
+	for l.store != nil {
+		wait, err := l.take(ctx)
+		if err != nil {
+			break
+		}
+		if wait == 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// Allow takes a token if one is free right now.
+func (l *Limiter) Allow(ctx context.Context) bool {
+	if l.limiter == nil {
+		return true
+	}
+	if l.store != nil {
+		if wait, err := l.take(ctx); err == nil {
+			return wait == 0
+		}
+	}
+	return l.limiter.Allow()
+}
+
+// Shared reports whether the limit is shared through a Store.
+func (l *Limiter) Shared() bool {
+	return l.store != nil
+}
+
+// take spends a leased token, or leases more from the store.
+func (l *Limiter) take(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	if l.leased > 0 && l.now().Before(l.leaseExpires) {
+		l.leased--
+		l.mu.Unlock()
+		return 0, nil
+	}
+	n := max(int(l.current/leaseDivisor), 1)
+	l.mu.Unlock()
+
+	lease, err := l.store.Take(ctx, l.key, l.ceiling, n)
+	if err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease.Rate != l.current {
+		l.apply(lease.Rate)
+	}
+	if lease.Tokens == 0 {
+		return lease.Wait, nil
+	}
+	l.leased = lease.Tokens - 1
+	l.leaseExpires = l.now().Add(leaseFor)
+	return 0, nil
+}
+
+// PausedFor is how much longer a Retry-After holds the limiter, or zero.
+func (l *Limiter) PausedFor() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return max(l.pausedUntil.Sub(l.now()), 0)
+}
+
+// Success records an accepted event, stepping the rate back up toward the
+// ceiling.
+func (l *Limiter) Success(ctx context.Context) {
+	if l.limiter == nil {
+		return
+	}
+	l.mu.Lock()
+	now := l.now()
+	if l.current >= l.ceiling || now.Sub(l.lastChange) < recoverEvery {
+		l.mu.Unlock()
+		return
+	}
+	r := l.set(now, min(l.current+l.step, l.ceiling))
+	l.mu.Unlock()
+
+	l.share(ctx, r)
+}
+
+// Throttled records a rejection for sending too fast. retryAfter, when
+// positive, pauses the limiter for that long.
+func (l *Limiter) Throttled(ctx context.Context, retryAfter time.Duration) {
+	l.mu.Lock()
+	now := l.now()
+	if retryAfter > 0 && now.Add(retryAfter).After(l.pausedUntil) {
+		l.pausedUntil = now.Add(retryAfter)
+	}
+	if l.limiter == nil || now.Sub(l.lastDecrease) < recoverEvery {
+		l.mu.Unlock()
+		return
+	}
+	l.lastDecrease = now
+	r := l.set(now, max(l.current*decreaseFactor, l.floor))
+	l.mu.Unlock()
+
+	l.share(ctx, r)
+}
+
+// share writes an adjusted rate to the store. A failed write only means the
+// other processes adapt on their own for a while.
+func (l *Limiter) share(ctx context.Context, r float64) {
+	if l.store != nil {
+		_ = l.store.SetRate(ctx, l.key, r)
+	}
+}
+
+// Rate is the current rate in events per second; Ceiling is the configured
+// one. Both are zero for a limiter that never limits.
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+func (l *Limiter) Ceiling() float64 {
+	return l.ceiling
+}
+
+// set changes the rate as an adjustment of this limiter's own and returns it.
+func (l *Limiter) set(now time.Time, r float64) float64 {
+	l.lastChange = now
+	l.leased = 0
+	l.apply(r)
+	return r
+}
+
+// apply changes the rate, also for local pacing.
+func (l *Limiter) apply(r float64) {
+	l.current = r
+	l.limiter.SetLimit(rate.Limit(r))
+	l.limiter.SetBurst(burst(r))
+}
+
+func burst(r float64) int {
+	return max(int(r), 1)
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newTestLimiter(ceiling float64) (*Limiter, *clock) {
+	c := &clock{t: time.Unix(1_700_000_000, 0)}
+	l := New(ceiling)
+	l.now = c.now
+	return l, c
+}
+
+func TestLimiter_ThrottleHalvesRateOncePerSecond(t *testing.T) {
+	l, c := newTestLimiter(100)
+
+	l.Throttled(context.Background(), 0)
+	l.Throttled(context.Background(), 0)
+	assert.Equal(t, 50.0, l.Rate())
+
+	c.advance(time.Second)
+	l.Throttled(context.Background(), 0)
+	assert.Equal(t, 25.0, l.Rate())
+}
+
+func TestLimiter_NeverDropsBelowFloor(t *testing.T) {
+	l, c := newTestLimiter(100)
+
+	for range 10 {
+		l.Throttled(context.Background(), 0)
+		c.advance(time.Second)
+	}
+	assert.Equal(t, 5.0, l.Rate())
+}
+
+func TestLimiter_RecoversAdditively(t *testing.T) {
+	l, c := newTestLimiter(100)
+	l.Throttled(context.Background(), 0)
+
+	l.Success(context.Background())
+	assert.Equal(t, 50.0, l.Rate(), "no recovery within a second of the decrease")
+
+	c.advance(time.Second)
+	l.Success(context.Background())
+	l.Success(context.Background())
+	assert.Equal(t, 55.0, l.Rate())
+
+	for range 20 {
+		c.advance(time.Second)
+		l.Success(context.Background())
+	}
+	assert.Equal(t, 100.0, l.Rate())
+}
+
+func TestLimiter_RetryAfterPauses(t *testing.T) {
+	l, c := newTestLimiter(100)
+
+	l.Throttled(context.Background(), 30*time.Second)
+	assert.Equal(t, 30*time.Second, l.PausedFor())
+
+	l.Throttled(context.Background(), 10*time.Second)
+	assert.Equal(t, 30*time.Second, l.PausedFor(), "a shorter Retry-After doesn't cut the pause")
+
+	c.advance(31 * time.Second)
+	assert.Zero(t, l.PausedFor())
+}
+
+func TestLimiter_ZeroCeilingNeverLimits(t *testing.T) {
+	l, _ := newTestLimiter(0)
+
+	l.Throttled(context.Background(), 0)
+	l.Success(context.Background())
+
+	assert.Zero(t, l.Rate())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, l.Wait(ctx))
+}
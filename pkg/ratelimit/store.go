@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store holds token buckets that several processes draw from, so a limit
+// configured once holds across all of them. Each bucket also holds the rate
+// it refills at, so when one process adapts the rate the others follow.
+type Store interface {
+	// Take leases up to n tokens from the bucket under key. The bucket
+	// refills at its shared rate, which starts at ceiling and never exceeds
+	// it, and holds at most one second of tokens.
+	Take(ctx context.Context, key string, ceiling float64, n int) (Lease, error)
+	// SetRate replaces the shared rate of the bucket under key.
+	SetRate(ctx context.Context, key string, rate float64) error
+}
+
+// Lease is the result of a Take. Tokens may be fewer than asked for; when
+// it is zero, Wait is how long until one will be available. Rate is the
+// bucket's shared rate.
+type Lease struct {
+	Tokens int
+	Wait   time.Duration
+	Rate   float64
+}
+
+// TakeTokens is the bucket arithmetic for Store implementations: it refills
+// tokens at rate for the time since last, up to one second's worth, then
+// takes up to n of them. It returns the tokens left, the number taken and,
+// when none could be taken, the wait until one can.
+func TakeTokens(tokens float64, last, now time.Time, rate float64, n int) (float64, int, time.Duration) {
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens += elapsed.Seconds() * rate
+	}
+	tokens = min(tokens, float64(burst(rate)))
+
+	if taken := min(int(tokens), n); taken >= 1 {
+		return tokens - float64(taken), taken, 0
+	}
+	if rate <= 0 {
+		return tokens, 0, time.Second
+	}
+	return tokens, 0, time.Duration((1 - tokens) / rate * float64(time.Second))
+}
+
+// SharedRate is the rate a bucket refills at: its stored rate, if one has
+// been set and is positive, capped at ceiling.
+func SharedRate(stored *float64, ceiling float64) float64 {
+	if stored == nil || *stored <= 0 {
+		return ceiling
+	}
+	return min(*stored, ceiling)
+}
+
+// MemoryStore is a Store for one process: tests, and the memory queue
+// backend where there is only one worker.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	rate   *float64
+	last   time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, ceiling float64, n int) (Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b := s.bucket(key, ceiling, now)
+
+	r := SharedRate(b.rate, ceiling)
+	var lease Lease
+	b.tokens, lease.Tokens, lease.Wait = TakeTokens(b.tokens, b.last, now, r, n)
+	b.last = now
+	lease.Rate = r
+	return lease, nil
+}
+
+func (s *MemoryStore) SetRate(_ context.Context, key string, rate float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bucket(key, rate, s.now()).rate = &rate
+	return nil
+}
+
+func (s *MemoryStore) bucket(key string, rate float64, now time.Time) *memoryBucket {
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(burst(rate)), last: now}
+		s.buckets[key] = b
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeTokens(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+
+	left, taken, wait := TakeTokens(3, start, start, 10, 1)
+	assert.Equal(t, 2.0, left)
+	assert.Equal(t, 1, taken)
+	assert.Zero(t, wait)
+
+	left, taken, wait = TakeTokens(3.5, start, start, 10, 5)
+	assert.Equal(t, 0.5, left)
+	assert.Equal(t, 3, taken, "a lease takes what is there")
+	assert.Zero(t, wait)
+
+	left, taken, wait = TakeTokens(0.5, start, start, 10, 1)
+	assert.Equal(t, 0.5, left)
+	assert.Zero(t, taken)
+	assert.Equal(t, 50*time.Millisecond, wait)
+
+	left, _, _ = TakeTokens(0, start, start.Add(time.Hour), 10, 1)
+	assert.Equal(t, 9.0, left, "refill is capped at one second of tokens")
+}
+
+func TestSharedLimiters_DrawFromOneBucket(t *testing.T) {
+	store := NewMemoryStore()
+	c := &clock{t: time.Unix(1_700_000_000, 0)}
+	store.now = c.now
+
+	a := NewShared(10, store, "sms")
+	b := NewShared(10, store, "sms")
+	other := NewShared(10, store, "email")
+
+	granted := 0
+	for range 10 {
+		for _, l := range []*Limiter{a, b} {
+			if l.Allow(context.Background()) {
+				granted++
+			}
+		}
+	}
+	assert.Equal(t, 10, granted, "two workers share one ceiling")
+	assert.True(t, other.Allow(context.Background()), "other keys have their own bucket")
+
+	c.advance(500 * time.Millisecond)
+	assert.True(t, a.Allow(context.Background()))
+	assert.True(t, b.Allow(context.Background()))
+}
+
+func TestSharedLimiter_LeasesTokensInChunks(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	l := NewShared(100, store, "sms")
+
+	for range 10 {
+		require.True(t, l.Allow(context.Background()))
+	}
+	assert.Equal(t, 1, store.takes, "one lease covers a tenth of a second")
+
+	require.True(t, l.Allow(context.Background()))
+	assert.Equal(t, 2, store.takes)
+}
+
+func TestSharedLimiters_FollowTheSharedRate(t *testing.T) {
+	store := NewMemoryStore()
+	a := NewShared(100, store, "sms")
+	b := NewShared(100, store, "sms")
+
+	a.Throttled(context.Background(), 0)
+	assert.Equal(t, 50.0, a.Rate())
+
+	require.True(t, b.Allow(context.Background()))
+	assert.Equal(t, 50.0, b.Rate(), "b picks up the rate a adapted to")
+}
+
+type countingStore struct {
+	*MemoryStore
+	takes int
+}
+
+func (s *countingStore) Take(ctx context.Context, key string, ceiling float64, n int) (Lease, error) {
+	s.takes++
+	return s.MemoryStore.Take(ctx, key, ceiling, n)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, float64, int) (Lease, error) {
+	return Lease{}, errors.New("store down")
+}
+
+func (failingStore) SetRate(context.Context, string, float64) error {
+	return errors.New("store down")
+}
+
+func TestSharedLimiter_FallsBackToLocalPacing(t *testing.T) {
+	l := NewShared(2, failingStore{}, "sms")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.NoError(t, l.Wait(ctx))
+	require.NoError(t, l.Wait(ctx))
+	assert.Error(t, l.Wait(ctx), "local bucket is drained")
+}
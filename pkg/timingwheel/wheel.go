@@ -0,0 +1,124 @@
+package timingwheel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Wheel is a hashed timing wheel. Each slot covers one tick; entries further
+// out than one revolution carry a round counter that is decremented every
+// time the wheel passes their slot.
+type Wheel struct {
+	mu    sync.Mutex
+	tick  time.Duration
+	slots []map[string]*entry
+	index map[string]*entry
+	pos   int
+}
+
+type entry struct {
+	key    string
+	slot   int
+	rounds int
+}
+
+func New(tick time.Duration, size int) *Wheel {
+	slots := make([]map[string]*entry, size)
+	for i := range slots {
+		slots[i] = make(map[string]*entry)
+	}
+	return &Wheel{
+		tick:  tick,
+		slots: slots,
+		index: make(map[string]*entry),
+	}
+}
+
+// Add registers key to fire at the given time. Times in the past fire on the
+// next tick. It returns false when the key is already held by the wheel.
+func (w *Wheel) Add(key string, at time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.index[key]; ok {
+		return false
+	}
+
+	ticks := int((time.Until(at) + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	e := &entry{
+		key:    key,
+		slot:   (w.pos + ticks) % len(w.slots),
+		rounds: (ticks - 1) / len(w.slots),
+	}
+	w.slots[e.slot][key] = e
+	w.index[key] = e
+	return true
+}
+
+func (w *Wheel) Remove(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.index[key]
+	if !ok {
+		return false
+	}
+	delete(w.slots[e.slot], key)
+	delete(w.index, key)
+	return true
+}
+
+func (w *Wheel) Contains(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.index[key]
+	return ok
+}
+
+func (w *Wheel) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.index)
+}
+
+// Run advances the wheel every tick and calls fire for each expired key until
+// ctx is cancelled. fire is invoked from the Run goroutine.
+func (w *Wheel) Run(ctx context.Context, fire func(key string)) {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, key := range w.advance() {
+				fire(key)
+			}
+		}
+	}
+}
+
+func (w *Wheel) advance() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pos = (w.pos + 1) % len(w.slots)
+
+	var expired []string
+	for key, e := range w.slots[w.pos] {
+		if e.rounds > 0 {
+			e.rounds--
+			continue
+		}
+		expired = append(expired, key)
+		delete(w.slots[w.pos], key)
+		delete(w.index, key)
+	}
+	return expired
+}
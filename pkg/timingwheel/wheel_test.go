@@ -0,0 +1,59 @@
+package timingwheel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWheel_FiresInOrder(t *testing.T) {
+	w := New(5*time.Millisecond, 8)
+
+	start := time.Now()
+	w.Add("late", start.Add(80*time.Millisecond))
+	w.Add("early", start.Add(10*time.Millisecond))
+	w.Add("overdue", start.Add(-time.Second))
+
+	var mu sync.Mutex
+	var fired []string
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	w.Run(ctx, func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired = append(fired, key)
+		if key == "late" {
+			assert.GreaterOrEqual(t, time.Since(start), 75*time.Millisecond)
+		}
+	})
+
+	assert.Equal(t, []string{"overdue", "early", "late"}, fired)
+	assert.Equal(t, 0, w.Len())
+}
+
+func TestWheel_AddDuplicate(t *testing.T) {
+	w := New(time.Millisecond, 4)
+
+	assert.True(t, w.Add("a", time.Now().Add(time.Second)))
+	assert.False(t, w.Add("a", time.Now().Add(time.Second)))
+	assert.True(t, w.Contains("a"))
+}
+
+func TestWheel_Remove(t *testing.T) {
+	w := New(5*time.Millisecond, 4)
+	w.Add("a", time.Now().Add(10*time.Millisecond))
+
+	assert.True(t, w.Remove("a"))
+	assert.False(t, w.Remove("a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	w.Run(ctx, func(key string) {
+		t.Fatalf("removed key %s fired", key)
+	})
+}
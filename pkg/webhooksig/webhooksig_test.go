@@ -0,0 +1,75 @@
+package webhooksig
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"to":"+905551234567"}`)
+	now := time.Now()
+	h := http.Header{}
+
+	require.NoError(t, SignRequest(h, []string{"current"}, body, now))
+
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), h.Get(TimestampHeader))
+	assert.NoError(t, Verify(h, body, []string{"current"}, 0, now))
+	assert.ErrorIs(t, Verify(h, []byte(`{"to":"+15550000000"}`), []string{"current"}, 0, now), ErrNoMatch)
+	assert.ErrorIs(t, Verify(h, body, []string{"other"}, 0, now), ErrNoMatch)
+}
+
+func TestVerify_Rotation(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Now()
+	h := http.Header{}
+	require.NoError(t, SignRequest(h, []string{"new", "old"}, body, now))
+
+	// Receivers on either side of the rotation accept the request.
+	assert.NoError(t, Verify(h, body, []string{"old"}, 0, now))
+	assert.NoError(t, Verify(h, body, []string{"new"}, 0, now))
+	assert.NoError(t, Verify(h, body, []string{"new", "old"}, 0, now))
+
+	assert.ErrorIs(t, SignRequest(h, []string{"a", "b", "c"}, body, now), ErrTooManySecrets)
+}
+
+func TestVerify_ReplayWindow(t *testing.T) {
+	body := []byte(`{}`)
+	signedAt := time.Now()
+	h := http.Header{}
+	require.NoError(t, SignRequest(h, []string{"secret"}, body, signedAt))
+
+	assert.NoError(t, Verify(h, body, []string{"secret"}, time.Minute, signedAt.Add(59*time.Second)))
+	assert.ErrorIs(t, Verify(h, body, []string{"secret"}, time.Minute, signedAt.Add(2*time.Minute)), ErrTimestampExpired)
+	assert.ErrorIs(t, Verify(h, body, []string{"secret"}, time.Minute, signedAt.Add(-2*time.Minute)), ErrTimestampExpired)
+}
+
+func TestVerify_MissingHeaders(t *testing.T) {
+	assert.ErrorIs(t, Verify(http.Header{}, nil, []string{"secret"}, 0, time.Now()), ErrMissingSignature)
+
+	h := http.Header{}
+	h.Set(TimestampHeader, "yesterday")
+	h.Set(SignatureHeader, "v1=abc")
+	assert.ErrorIs(t, Verify(h, nil, []string{"secret"}, 0, time.Now()), ErrInvalidTimestamp)
+}
+
+func TestVerifyRequest_RestoresBody(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	require.NoError(t, SignRequest(req.Header, []string{"secret"}, body, time.Now()))
+
+	got, err := VerifyRequest(req, []string{"secret"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	again, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, again)
+}